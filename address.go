@@ -1,17 +1,264 @@
 package belajargorm
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+const (
+	AddressHome = "home"
+	AddressWork = "work"
+
+	// DefaultCountry dipakai alamat yang dibuat tanpa country.
+	DefaultCountry = "ID"
+)
+
+var ErrDefaultAddressRequired = errors.New("belajargorm: user must keep a default address")
+
+/**
+*	Address adalah alamat terstruktur milik user. Setiap user yang punya
+*	alamat selalu punya tepat satu alamat default: alamat pertama otomatis
+*	menjadi default, dan default dipindah lewat AddressRepository.SetDefault
+*	(atau create/update dengan IsDefault true). Unique index
+*	idx_addresses_default menjaga aturan ini juga di database.
+ */
 type Address struct {
-	ID        int64          `gorm:"primary_key;column:id;autoIncrement"`
-	UserID    string         `gorm:"column:user_id"`
-	Address   string         `gorm:"column:address"`
-	CreatedAt time.Time      `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time      `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
-	User      User           `gorm:"foreignKey:user_id;references:id"`
+	ID         int64          `gorm:"primary_key;column:id;autoIncrement"`
+	UserID     string         `gorm:"column:user_id" validate:"required,max=64"`
+	Label      string         `gorm:"column:label" validate:"oneof=home work"`
+	Line1      string         `gorm:"column:line1" validate:"required,max=200"`
+	Line2      string         `gorm:"column:line2" validate:"max=200"`
+	City       string         `gorm:"column:city" validate:"max=100"`
+	Region     string         `gorm:"column:region" validate:"max=100"`
+	PostalCode string         `gorm:"column:postal_code" validate:"max=16"`
+	Country    string         `gorm:"column:country" validate:"regex=^[A-Z]{2}$"`
+	IsDefault  bool           `gorm:"column:is_default"`
+	CreatedAt  time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time      `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `gorm:"column:deleted_at"`
+	User       User           `gorm:"foreignKey:user_id;references:id"`
+}
+
+// String menggabungkan alamat menjadi satu baris, misalnya untuk Order.ShippingAddress.
+func (a *Address) String() string {
+	parts := make([]string, 0, 5)
+	for _, part := range []string{a.Line1, a.Line2, a.City, a.Region} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	last := strings.TrimSpace(strings.TrimSpace(a.PostalCode) + " " + a.Country)
+	if last != "" {
+		parts = append(parts, last)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (a *Address) BeforeSave(db *gorm.DB) error {
+	err := validateModel(db, a)
+	ve := &ValidationError{}
+	if err != nil && !errors.As(err, &ve) {
+		return err
+	}
+
+	country := a.Country
+	if country == "" {
+		country = DefaultCountry
+	}
+	if a.PostalCode != "" && !ValidPostalCode(country, a.PostalCode) {
+		ve.Add("postal_code", "is not a valid postal code for "+country)
+	}
+	return ve.Err()
+}
+
+func (a *Address) BeforeCreate(db *gorm.DB) error {
+	if a.Label == "" {
+		a.Label = AddressHome
+	}
+	if a.Country == "" {
+		a.Country = DefaultCountry
+	}
+	if !a.IsDefault {
+		return nil
+	}
+	return clearDefaultAddress(db.Session(&gorm.Session{NewDB: true}), a.UserID, a.ID)
+}
+
+// AfterCreate menjadikan alamat pertama user sebagai default.
+func (a *Address) AfterCreate(db *gorm.DB) error {
+	if a.IsDefault {
+		return nil
+	}
+	tx := db.Session(&gorm.Session{NewDB: true})
+	var count int64
+	err := tx.Model(&Address{}).Where("user_id = ? AND is_default", a.UserID).Count(&count).Error
+	if err != nil || count > 0 {
+		return err
+	}
+	if err := tx.Model(&Address{}).Where("id = ?", a.ID).Update("is_default", true).Error; err != nil {
+		return err
+	}
+	a.IsDefault = true
+	return nil
+}
+
+func (a *Address) FilterFields() FilterFields {
+	return FilterFields{
+		Filter: []string{"id", "user_id", "label", "line1", "city", "region", "postal_code", "country", "is_default", "created_at"},
+		Sort:   []string{"id", "city", "created_at"},
+	}
+}
+
+// clearDefaultAddress mencabut status default alamat user selain exceptID.
+func clearDefaultAddress(db *gorm.DB, userID string, exceptID int64) error {
+	return db.Model(&Address{}).
+		Where("user_id = ? AND id <> ? AND is_default", userID, exceptID).
+		Update("is_default", false).Error
+}
+
+// postalCodePatterns berisi format kode pos per country (ISO 3166-1 alpha-2).
+// Country yang tidak terdaftar hanya dicek panjangnya lewat tag validate.
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"ID": regexp.MustCompile(`^[1-9]\d{4}$`),
+	"MY": regexp.MustCompile(`^\d{5}$`),
+	"TH": regexp.MustCompile(`^\d{5}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"SG": regexp.MustCompile(`^\d{6}$`),
+	"IN": regexp.MustCompile(`^[1-9]\d{5}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"PH": regexp.MustCompile(`^\d{4}$`),
+	"JP": regexp.MustCompile(`^\d{3}-\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"CA": regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+}
+
+// ValidPostalCode memeriksa format kode pos untuk country. Country yang tidak
+// dikenal selalu dianggap valid.
+func ValidPostalCode(country, code string) bool {
+	pattern, ok := postalCodePatterns[country]
+	return !ok || pattern.MatchString(code)
+}
+
+func (r *AddressRepository) ListAddressesForUser(ctx context.Context, userID string) ([]Address, error) {
+	return r.List(ctx, Where("user_id = ?", userID), OrderBy("is_default DESC, id"))
+}
+
+// DefaultForUser mengembalikan alamat default user.
+func (r *AddressRepository) DefaultForUser(ctx context.Context, userID string) (*Address, error) {
+	address, err := r.First(ctx, Where("user_id = ? AND is_default", userID))
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		notFound.Key = "default for user " + userID
+	}
+	return address, err
+}
+
+// SetDefault menjadikan alamat id default untuk pemiliknya, default lama dicabut.
+func (r *AddressRepository) SetDefault(ctx context.Context, id int64) (*Address, error) {
+	var address *Address
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		address, err = NewRepository[Address](tx).Get(ctx, id, lockForUpdate)
+		if err != nil || address.IsDefault {
+			return err
+		}
+		if err := clearDefaultAddress(tx, address.UserID, address.ID); err != nil {
+			return err
+		}
+		address.IsDefault = true
+		return tx.Select("is_default", "updated_at").Updates(address).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+/**
+*	Update menyimpan alamat sambil menjaga satu default per user. IsDefault
+*	true memindahkan default ke alamat ini, sedangkan mencabut default (atau
+*	memindahkan alamat default ke user lain) ditolak dengan
+*	ErrDefaultAddressRequired: pilih default lain dulu lewat SetDefault.
+ */
+func (r *AddressRepository) Update(ctx context.Context, address *Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := NewRepository[Address](tx).Get(ctx, address.ID, lockForUpdate)
+		if err != nil {
+			return err
+		}
+		if current.IsDefault && (!address.IsDefault || address.UserID != current.UserID) {
+			return fmt.Errorf("%w: address %d is the default for user %s", ErrDefaultAddressRequired, current.ID, current.UserID)
+		}
+		if address.IsDefault && !current.IsDefault {
+			if err := clearDefaultAddress(tx, address.UserID, address.ID); err != nil {
+				return err
+			}
+		}
+		return NewRepository[Address](tx).Update(ctx, address)
+	})
+}
+
+// Delete menghapus alamat. Kalau yang dihapus alamat default, alamat user
+// yang paling lama menjadi default baru.
+func (r *AddressRepository) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		addresses := NewRepository[Address](tx)
+		address, err := addresses.Get(ctx, id, lockForUpdate)
+		if err != nil {
+			return err
+		}
+		if err := addresses.Delete(ctx, id); err != nil || !address.IsDefault {
+			return err
+		}
+
+		next, err := addresses.First(ctx, Where("user_id = ?", address.UserID), OrderBy("id"), lockForUpdate)
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		next.IsDefault = true
+		return tx.Select("is_default", "updated_at").Updates(next).Error
+	})
+}
+
+/**
+*	Restore mengembalikan alamat dari trash sambil menjaga satu default per
+*	user: alamat yang dulu default hanya menjadi default lagi kalau user belum
+*	punya default baru, sebaliknya alamat menjadi default kalau user tidak
+*	punya alamat lain. Pakai method ini, bukan Trash().Restore, untuk Address.
+ */
+func (r *AddressRepository) Restore(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		address, err := NewTrash[Address](tx).Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Model(&Address{}).Where("user_id = ? AND is_default", address.UserID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if isDefault := count == 0; isDefault != address.IsDefault {
+			// baris masih di trash, jadi diubah tanpa scope soft delete dan tanpa hook
+			err := tx.Unscoped().Session(&gorm.Session{SkipHooks: true}).Model(&Address{}).
+				Where("id = ?", id).Update("is_default", isDefault).Error
+			if err != nil {
+				return err
+			}
+		}
+		return NewTrash[Address](tx).Restore(ctx, id)
+	})
 }
@@ -0,0 +1,123 @@
+package belajargorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func defaultAddressIDs(t *testing.T, store *Store, userID string) []int64 {
+	t.Helper()
+	var ids []int64
+	err := store.db.Model(&Address{}).Where("user_id = ? AND is_default", userID).Order("id").Pluck("id", &ids).Error
+	assert.Nil(t, err)
+	return ids
+}
+
+func TestAddressDefault(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	// alamat pertama otomatis menjadi default
+	home := Address{UserID: "4", Line1: "Jalan Melati 4", City: "Bandung", PostalCode: "40115"}
+	assert.Nil(t, store.Addresses.Create(ctx, &home))
+	assert.True(t, home.IsDefault)
+	assert.Equal(t, AddressHome, home.Label)
+	assert.Equal(t, DefaultCountry, home.Country)
+
+	office := Address{UserID: "4", Label: AddressWork, Line1: "Jalan Asia Afrika 8", City: "Bandung"}
+	assert.Nil(t, store.Addresses.Create(ctx, &office))
+	assert.False(t, office.IsDefault)
+	assert.Equal(t, []int64{home.ID}, defaultAddressIDs(t, store, "4"))
+
+	// create dengan IsDefault memindahkan default
+	other := Address{UserID: "4", Line1: "Jalan Kenanga 1", City: "Bogor", IsDefault: true}
+	assert.Nil(t, store.Addresses.Create(ctx, &other))
+	assert.Equal(t, []int64{other.ID}, defaultAddressIDs(t, store, "4"))
+
+	moved, err := store.Addresses.SetDefault(ctx, office.ID)
+	assert.Nil(t, err)
+	assert.True(t, moved.IsDefault)
+	assert.Equal(t, []int64{office.ID}, defaultAddressIDs(t, store, "4"))
+
+	addresses, err := store.Addresses.ListAddressesForUser(ctx, "4")
+	assert.Nil(t, err)
+	assert.Equal(t, office.ID, addresses[0].ID)
+
+	// default tidak boleh dicabut tanpa memilih default lain
+	moved.IsDefault = false
+	assert.ErrorIs(t, store.Addresses.Update(ctx, moved), ErrDefaultAddressRequired)
+	home.IsDefault = true
+	home.City = "Cimahi"
+	assert.Nil(t, store.Addresses.Update(ctx, &home))
+	assert.Equal(t, []int64{home.ID}, defaultAddressIDs(t, store, "4"))
+
+	// default yang dihapus digantikan alamat paling lama
+	assert.Nil(t, store.Addresses.Delete(ctx, home.ID))
+	found, err := store.Addresses.DefaultForUser(ctx, "4")
+	assert.Nil(t, err)
+	assert.Equal(t, office.ID, found.ID)
+
+	_, err = store.Addresses.DefaultForUser(ctx, "5")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// unique index menolak dua default walaupun hook dilewati
+	err = store.Addresses.UpdateFields(ctx, other.ID, map[string]any{"is_default": true})
+	assert.NotNil(t, err)
+}
+
+func TestAddressRestore(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	home := Address{UserID: "4", Line1: "Jalan Melati 4", City: "Bandung"}
+	assert.Nil(t, store.Addresses.Create(ctx, &home))
+	office := Address{UserID: "4", Label: AddressWork, Line1: "Jalan Asia Afrika 8", City: "Bandung"}
+	assert.Nil(t, store.Addresses.Create(ctx, &office))
+
+	// default lama yang dikembalikan tidak merebut default yang sekarang
+	assert.Nil(t, store.Addresses.Delete(ctx, home.ID))
+	assert.Equal(t, []int64{office.ID}, defaultAddressIDs(t, store, "4"))
+	assert.Nil(t, store.Addresses.Restore(ctx, home.ID))
+	assert.Equal(t, []int64{office.ID}, defaultAddressIDs(t, store, "4"))
+
+	restored, err := store.Addresses.Get(ctx, home.ID)
+	assert.Nil(t, err)
+	assert.False(t, restored.IsDefault)
+
+	// alamat yang dikembalikan ke user tanpa default menjadi default
+	assert.Nil(t, store.Addresses.Delete(ctx, home.ID))
+	assert.Nil(t, store.Addresses.Delete(ctx, office.ID))
+	assert.Nil(t, store.Addresses.Restore(ctx, home.ID))
+	assert.Equal(t, []int64{home.ID}, defaultAddressIDs(t, store, "4"))
+
+	assert.ErrorIs(t, store.Addresses.Restore(ctx, home.ID), ErrNotFound)
+}
+
+func TestAddressPostalCode(t *testing.T) {
+	db := beginTest(t)
+
+	err := db.Create(&Address{UserID: "4", Line1: "Jalan Braga 1", PostalCode: "4011"}).Error
+	assert.Equal(t, "is not a valid postal code for ID", validationFields(t, err)["postal_code"])
+
+	err = db.Create(&Address{UserID: "4", Line1: "Orchard Road 1", Country: "SG", PostalCode: "238823"}).Error
+	assert.Nil(t, err)
+	err = db.Create(&Address{UserID: "4", Line1: "1 Main St", Country: "us", Label: "villa"}).Error
+	fields := validationFields(t, err)
+	assert.Equal(t, "has an invalid format", fields["country"])
+	assert.Equal(t, "must be one of home, work", fields["label"])
+
+	assert.True(t, ValidPostalCode("US", "94105-1234"))
+	assert.True(t, ValidPostalCode("GB", "SW1A 1AA"))
+	assert.False(t, ValidPostalCode("JP", "1000001"))
+	// country yang tidak dikenal tidak dicek formatnya
+	assert.True(t, ValidPostalCode("ZZ", "apa saja"))
+}
+
+func TestAddressString(t *testing.T) {
+	address := Address{Line1: "Jalan Merdeka 1", Line2: "Lantai 2", City: "Jakarta", Region: "DKI Jakarta", PostalCode: "10110", Country: "ID"}
+	assert.Equal(t, "Jalan Merdeka 1, Lantai 2, Jakarta, DKI Jakarta, 10110 ID", address.String())
+}
@@ -0,0 +1,328 @@
+package belajargorm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// SystemActor dipakai ketika context tidak membawa actor, misalnya dari job.
+const SystemActor = "system"
+
+// auditedTables berisi table yang dicatat ke user_logs beserta kolom yang
+// menunjukkan user pemilik datanya.
+var auditedTables = map[string]string{
+	"users":     "id",
+	"wallets":   "user_id",
+	"addresses": "user_id",
+	"todos":     "user_id",
+}
+
+var (
+	auditIgnoredColumns  = map[string]bool{"updated_at": true, "version": true, "search_name": true, "search_vector": true}
+	auditRedactedColumns = map[string]bool{"password": true}
+)
+
+const (
+	auditRedacted    = "[redacted]"
+	auditSnapshotKey = "audit:snapshot"
+)
+
+type actorKey struct{}
+
+// WithActor menandai siapa yang melakukan perubahan, misalnya id user yang login.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+			return actor
+		}
+	}
+	return SystemActor
+}
+
+// AuditChange adalah nilai satu kolom sebelum dan sesudah perubahan, isi
+// UserLog.Changes berupa JSON object dari nama kolom ke AuditChange.
+type AuditChange struct {
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+}
+
+/**
+*	AuditPlugin mencatat create, update dan delete pada User, Wallet, Address
+*	dan Todo ke user_logs. Baris audit ditulis memakai koneksi statement yang
+*	sama, jadi ikut transaksi perubahan: kalau perubahannya di-rollback, audit
+*	juga hilang.
+ */
+type AuditPlugin struct{}
+
+func (AuditPlugin) Name() string {
+	return "belajargorm:audit"
+}
+
+func (AuditPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().After("gorm:create").Register("audit:after_create", auditAfterCreate); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("audit:before_update", auditSnapshot); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("audit:after_update", auditAfterUpdate); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("audit:before_delete", auditSnapshot); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Register("audit:after_delete", auditAfterDelete)
+}
+
+func auditedStatement(db *gorm.DB) bool {
+	if db.Error != nil {
+		return false
+	}
+	_, ok := auditedTables[db.Statement.Table]
+	return ok
+}
+
+// auditQuery membuat query baru di koneksi (transaksi) yang sama dengan statement.
+func auditQuery(db *gorm.DB) *gorm.DB {
+	query := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	if db.Statement.Schema != nil {
+		return query.Model(reflect.New(db.Statement.Schema.ModelType).Interface())
+	}
+	return query.Table(db.Statement.Table)
+}
+
+func auditPrimaryKey(db *gorm.DB) string {
+	if db.Statement.Schema != nil && db.Statement.Schema.PrioritizedPrimaryField != nil {
+		return db.Statement.Schema.PrioritizedPrimaryField.DBName
+	}
+	return "id"
+}
+
+// auditSnapshot menyimpan isi baris yang akan diubah/dihapus sebelum query dijalankan.
+func auditSnapshot(db *gorm.DB) {
+	if !auditedStatement(db) {
+		return
+	}
+
+	stmt := db.Statement
+	query := auditQuery(db)
+	if stmt.Unscoped {
+		query = query.Unscoped()
+	}
+
+	conditions := false
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			query = query.Clauses(where)
+			conditions = true
+		}
+	}
+	if stmt.Schema != nil && stmt.ReflectValue.IsValid() {
+		_, values := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, queryValues := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, values)
+		if len(queryValues) > 0 {
+			query = query.Where(clause.IN{Column: column, Values: queryValues})
+			conditions = true
+		}
+	}
+	if !conditions && !stmt.AllowGlobalUpdate {
+		// query tanpa where akan ditolak gorm, tidak perlu snapshot
+		return
+	}
+
+	var rows []map[string]any
+	if err := query.Find(&rows).Error; err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(auditSnapshotKey, rows)
+}
+
+func auditSnapshotRows(db *gorm.DB) []map[string]any {
+	rows, _ := db.InstanceGet(auditSnapshotKey)
+	snapshot, _ := rows.([]map[string]any)
+	return snapshot
+}
+
+// auditReload membaca ulang baris berdasarkan primary key, termasuk yang soft deleted.
+func auditReload(db *gorm.DB, ids []any) (map[string]map[string]any, error) {
+	result := make(map[string]map[string]any, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	pk := auditPrimaryKey(db)
+	var rows []map[string]any
+	err := auditQuery(db).Unscoped().Where(clause.IN{Column: clause.Column{Name: pk}, Values: ids}).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[fmt.Sprint(row[pk])] = row
+	}
+	return result, nil
+}
+
+func auditAfterCreate(db *gorm.DB) {
+	stmt := db.Statement
+	if !auditedStatement(db) || stmt.Schema == nil || !stmt.ReflectValue.IsValid() {
+		return
+	}
+
+	_, values := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+	ids := make([]any, 0, len(values))
+	for _, value := range values {
+		ids = append(ids, value[0])
+	}
+
+	rows, err := auditReload(db, ids)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+
+	logs := make([]UserLog, 0, len(rows))
+	for _, id := range sortedKeys(rows) {
+		logs = append(logs, newAuditLog(db, AuditCreate, id, rows[id], auditDiff(nil, rows[id])))
+	}
+	writeAuditLogs(db, logs)
+}
+
+func auditAfterUpdate(db *gorm.DB) {
+	before := auditSnapshotRows(db)
+	if !auditedStatement(db) || len(before) == 0 {
+		return
+	}
+
+	pk := auditPrimaryKey(db)
+	ids := make([]any, 0, len(before))
+	for _, row := range before {
+		ids = append(ids, row[pk])
+	}
+
+	after, err := auditReload(db, ids)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+
+	logs := make([]UserLog, 0, len(before))
+	for _, old := range before {
+		id := fmt.Sprint(old[pk])
+		changes := auditDiff(old, after[id])
+		if len(changes) == 0 {
+			continue
+		}
+		logs = append(logs, newAuditLog(db, AuditUpdate, id, old, changes))
+	}
+	writeAuditLogs(db, logs)
+}
+
+func auditAfterDelete(db *gorm.DB) {
+	before := auditSnapshotRows(db)
+	if !auditedStatement(db) || len(before) == 0 || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	pk := auditPrimaryKey(db)
+	logs := make([]UserLog, 0, len(before))
+	for _, old := range before {
+		logs = append(logs, newAuditLog(db, AuditDelete, fmt.Sprint(old[pk]), old, auditDiff(old, nil)))
+	}
+	writeAuditLogs(db, logs)
+}
+
+// auditDiff membandingkan dua baris, old nil berarti create dan updated nil berarti delete.
+func auditDiff(old, updated map[string]any) map[string]AuditChange {
+	columns := map[string]bool{}
+	for column := range old {
+		columns[column] = true
+	}
+	for column := range updated {
+		columns[column] = true
+	}
+
+	changes := map[string]AuditChange{}
+	for column := range columns {
+		if auditIgnoredColumns[column] {
+			continue
+		}
+
+		oldValue, newValue := auditValue(old[column]), auditValue(updated[column])
+		if old != nil && updated != nil && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if auditRedactedColumns[column] {
+			oldValue, newValue = redact(oldValue), redact(newValue)
+		}
+		changes[column] = AuditChange{Old: oldValue, New: newValue}
+	}
+	return changes
+}
+
+func auditValue(v any) any {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+func redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	return auditRedacted
+}
+
+func newAuditLog(db *gorm.DB, action, recordID string, row map[string]any, changes map[string]AuditChange) UserLog {
+	b, err := json.Marshal(changes)
+	if err != nil {
+		db.AddError(err)
+	}
+
+	return UserLog{
+		UserID:   fmt.Sprint(auditValue(row[auditedTables[db.Statement.Table]])),
+		Action:   action,
+		Actor:    ActorFromContext(db.Statement.Context),
+		Entity:   db.Statement.Table,
+		RecordID: recordID,
+		Changes:  string(b),
+	}
+}
+
+func writeAuditLogs(db *gorm.DB, logs []UserLog) {
+	if len(logs) == 0 || db.Error != nil {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&logs).Error; err != nil {
+		db.AddError(err)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,144 @@
+package belajargorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// lastAuditID dipakai supaya audit dari fixtures tidak ikut dihitung.
+func lastAuditID(t *testing.T, db *gorm.DB) int {
+	t.Helper()
+
+	var id int
+	assert.Nil(t, db.Model(&UserLog{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error)
+	return id
+}
+
+func auditLogs(t *testing.T, db *gorm.DB, since int, entity string, recordID string) []UserLog {
+	t.Helper()
+
+	var logs []UserLog
+	err := db.Where("id > ? AND entity = ? AND record_id = ?", since, entity, recordID).Order("id").Find(&logs).Error
+	assert.Nil(t, err)
+	return logs
+}
+
+func auditChanges(t *testing.T, log UserLog) map[string]AuditChange {
+	t.Helper()
+
+	changes := map[string]AuditChange{}
+	assert.Nil(t, json.Unmarshal([]byte(log.Changes), &changes))
+	return changes
+}
+
+func TestAuditCreateUpdateDelete(t *testing.T) {
+	db := beginTest(t)
+	since := lastAuditID(t, db)
+	ctx := WithActor(context.Background(), "admin")
+
+	user := User{ID: "400", Password: "rahasia", Name: Name{FirstName: "Audit"}}
+	assert.Nil(t, db.WithContext(ctx).Create(&user).Error)
+
+	logs := auditLogs(t, db, since, "users", "400")
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, AuditCreate, logs[0].Action)
+	assert.Equal(t, "admin", logs[0].Actor)
+	assert.Equal(t, "400", logs[0].UserID)
+
+	changes := auditChanges(t, logs[0])
+	assert.Equal(t, "Audit", changes["first_name"].New)
+	assert.Equal(t, auditRedacted, changes["password"].New)
+
+	err := db.WithContext(ctx).Model(&user).Updates(map[string]interface{}{
+		"middle_name": "Jejak",
+		"password":    "baru",
+	}).Error
+	assert.Nil(t, err)
+
+	logs = auditLogs(t, db, since, "users", "400")
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, AuditUpdate, logs[1].Action)
+
+	changes = auditChanges(t, logs[1])
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, "", changes["middle_name"].Old)
+	assert.Equal(t, "Jejak", changes["middle_name"].New)
+	assert.Equal(t, auditRedacted, changes["password"].Old)
+
+	assert.Nil(t, db.Delete(&user).Error)
+
+	logs = auditLogs(t, db, since, "users", "400")
+	assert.Equal(t, 3, len(logs))
+	assert.Equal(t, AuditDelete, logs[2].Action)
+	assert.Equal(t, SystemActor, logs[2].Actor)
+	assert.Equal(t, "Jejak", auditChanges(t, logs[2])["middle_name"].Old)
+}
+
+func TestAuditNoChange(t *testing.T) {
+	db := beginTest(t)
+	since := lastAuditID(t, db)
+
+	err := db.Model(&User{}).Where("id = ?", "2").Update("first_name", "User 2").Error
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(auditLogs(t, db, since, "users", "2")))
+}
+
+func TestAuditAddressAndTodo(t *testing.T) {
+	db := beginTest(t)
+	since := lastAuditID(t, db)
+
+	assert.Nil(t, db.Where("id = ?", 2).Delete(&Address{}).Error)
+	logs := auditLogs(t, db, since, "addresses", "2")
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, AuditDelete, logs[0].Action)
+	assert.Equal(t, "1", logs[0].UserID)
+
+	todo := Todo{UserID: "3", Task: "Catat audit"}
+	assert.Nil(t, db.Create(&todo).Error)
+	assert.Nil(t, db.Delete(&todo).Error)
+
+	logs = auditLogs(t, db, since, "todos", strconv.Itoa(todo.ID))
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, []string{AuditCreate, AuditDelete}, []string{logs[0].Action, logs[1].Action})
+	assert.Equal(t, "3", logs[1].UserID)
+}
+
+func TestAuditWalletLedger(t *testing.T) {
+	db := beginTest(t)
+	since := lastAuditID(t, db)
+	ctx := WithActor(context.Background(), "1")
+
+	_, err := NewLedger(db).Transfer(ctx, "1", "3", 5000, "")
+	assert.Nil(t, err)
+
+	logs := auditLogs(t, db, since, "wallets", "3")
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, "1", logs[0].Actor)
+	assert.Equal(t, "3", logs[0].UserID)
+
+	changes := auditChanges(t, logs[0])
+	assert.EqualValues(t, 0, changes["balance"].Old)
+	assert.EqualValues(t, 5000, changes["balance"].New)
+}
+
+func TestAuditRollback(t *testing.T) {
+	db := beginTest(t)
+	since := lastAuditID(t, db)
+
+	errBatal := errors.New("batal")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", "5").Update("middle_name", "Batal").Error; err != nil {
+			return err
+		}
+		assert.Equal(t, 1, len(auditLogs(t, tx, since, "users", "5")))
+		return errBatal
+	})
+	assert.ErrorIs(t, err, errBatal)
+	assert.Equal(t, 0, len(auditLogs(t, db, since, "users", "5")))
+}
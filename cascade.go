@@ -0,0 +1,299 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CascadeAction string
+
+const (
+	CascadeSoftDelete CascadeAction = "soft_delete"
+	CascadeHardDelete CascadeAction = "hard_delete"
+	CascadeNullify    CascadeAction = "nullify"
+	CascadeRestrict   CascadeAction = "restrict"
+)
+
+var (
+	ErrRestricted     = errors.New("belajargorm: delete restricted by existing associations")
+	ErrInvalidCascade = errors.New("belajargorm: invalid cascade policy")
+)
+
+/**
+*	CascadePolicy menentukan apa yang terjadi pada data milik user ketika
+*	user dihapus. Key Associations adalah nama table association (lihat
+*	userAssociations), association yang tidak disebut dibiarkan apa adanya,
+*	kecuali saat hard delete: association dengan foreign key ke users yang
+*	tidak disebut diperlakukan sebagai CascadeRestrict. User sendiri dihapus
+*	sesuai field User: CascadeSoftDelete atau CascadeHardDelete.
+ */
+type CascadePolicy struct {
+	User         CascadeAction
+	Associations map[string]CascadeAction
+}
+
+// DefaultUserCascade dipakai UserRepository.Delete. user_logs sengaja tidak
+// disebut supaya riwayat audit tetap utuh setelah user dihapus.
+var DefaultUserCascade = CascadePolicy{
+	User: CascadeSoftDelete,
+	Associations: map[string]CascadeAction{
+		"wallets":           CascadeSoftDelete,
+		"addresses":         CascadeSoftDelete,
+		"todos":             CascadeSoftDelete,
+		"user_like_product": CascadeHardDelete,
+	},
+}
+
+// CascadeSummary berisi jumlah baris yang terpengaruh per table, termasuk "users".
+type CascadeSummary map[string]int64
+
+/**
+*	userAssociation adalah table yang menyimpan user id di columns. model
+*	kosong untuk join table yang tidak punya struct sendiri.
+*	- foreignKey: columns mereferensikan users (id), jadi baris harus habis
+*	  sebelum user dihapus permanen
+*	- nullable: columns boleh NULL dan bukan foreign key, hanya association
+*	  seperti ini yang boleh CascadeNullify
+*	- restrictOnly: data transaksi (order, transfer) yang tidak boleh ikut dihapus
+*	- dependents: table lain yang mereferensikan baris association ini, hard
+*	  delete ditolak selama masih ada barisnya
+ */
+type userAssociation struct {
+	model        any
+	columns      []string
+	foreignKey   bool
+	nullable     bool
+	restrictOnly bool
+	dependents   []associationDependent
+}
+
+type associationDependent struct {
+	table  string
+	column string
+}
+
+var userAssociations = map[string]userAssociation{
+	"wallets": {
+		model: &Wallet{}, columns: []string{"user_id"}, foreignKey: true,
+		// ledger append-only, wallet yang sudah punya transaksi tidak bisa dihapus permanen
+		dependents: []associationDependent{{table: "wallet_transactions", column: "wallet_id"}},
+	},
+	"addresses": {
+		model: &Address{}, columns: []string{"user_id"}, foreignKey: true,
+		dependents: []associationDependent{{table: "orders", column: "address_id"}},
+	},
+	"todos":             {model: &Todo{}, columns: []string{"user_id"}},
+	"user_logs":         {model: &UserLog{}, columns: []string{"user_id"}, nullable: true},
+	"user_like_product": {columns: []string{"user_id"}, foreignKey: true},
+	"orders":            {model: &Order{}, columns: []string{"user_id"}, foreignKey: true, restrictOnly: true},
+	"wallet_transfers": {
+		model: &WalletTransfer{}, columns: []string{"from_user_id", "to_user_id"}, foreignKey: true, restrictOnly: true,
+	},
+}
+
+func (p CascadePolicy) validate() error {
+	if p.User != CascadeSoftDelete && p.User != CascadeHardDelete {
+		return fmt.Errorf("%w: user must be %s or %s", ErrInvalidCascade, CascadeSoftDelete, CascadeHardDelete)
+	}
+	for name, action := range p.Associations {
+		assoc, ok := userAssociations[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown association %q", ErrInvalidCascade, name)
+		}
+		if assoc.restrictOnly && action != CascadeRestrict {
+			return fmt.Errorf("%w: %s can only be %s", ErrInvalidCascade, name, CascadeRestrict)
+		}
+		switch action {
+		case CascadeHardDelete, CascadeRestrict:
+		case CascadeSoftDelete:
+			if assoc.model == nil {
+				return fmt.Errorf("%w: %s cannot be soft deleted", ErrInvalidCascade, name)
+			}
+		case CascadeNullify:
+			if !assoc.nullable {
+				return fmt.Errorf("%w: %s cannot be nullified", ErrInvalidCascade, name)
+			}
+		default:
+			return fmt.Errorf("%w: unknown action %q for %s", ErrInvalidCascade, action, name)
+		}
+	}
+	return nil
+}
+
+// actions melengkapi policy dengan CascadeRestrict untuk association yang punya
+// foreign key ke users saat hard delete, supaya hasilnya ErrRestricted dan
+// bukan pelanggaran foreign key atau baris yatim.
+func (p CascadePolicy) actions() map[string]CascadeAction {
+	actions := make(map[string]CascadeAction, len(userAssociations))
+	for name, action := range p.Associations {
+		actions[name] = action
+	}
+	if p.User != CascadeHardDelete {
+		return actions
+	}
+	for name, assoc := range userAssociations {
+		if _, ok := actions[name]; !ok && assoc.foreignKey {
+			actions[name] = CascadeRestrict
+		}
+	}
+	return actions
+}
+
+// newModel membuat instance baru supaya gorm tidak mengubah model di userAssociations.
+func (a userAssociation) newModel() any {
+	return reflect.New(reflect.TypeOf(a.model).Elem()).Interface()
+}
+
+func (a userAssociation) query(tx *gorm.DB, name, userID string) *gorm.DB {
+	conditions := make([]clause.Expression, len(a.columns))
+	for i, column := range a.columns {
+		conditions[i] = clause.Eq{Column: clause.Column{Name: column}, Value: userID}
+	}
+	condition := clause.Or(conditions...)
+	if a.model == nil {
+		return tx.Table(name).Where(condition)
+	}
+	return tx.Model(a.newModel()).Where(condition)
+}
+
+func (a userAssociation) apply(tx *gorm.DB, name, userID string, action CascadeAction) (int64, error) {
+	switch action {
+	case CascadeRestrict:
+		var count int64
+		if err := a.query(tx, name, userID).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		if count > 0 {
+			return 0, fmt.Errorf("%w: %s has %d rows", ErrRestricted, name, count)
+		}
+		return 0, nil
+
+	case CascadeSoftDelete:
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(a.model); err != nil {
+			return 0, err
+		}
+		if _, ok := softDeleteColumnOf(stmt.Schema); !ok {
+			return 0, fmt.Errorf("%w: %s", ErrNotSoftDeletable, name)
+		}
+		res := a.query(tx, name, userID).Delete(a.newModel())
+		return res.RowsAffected, res.Error
+
+	case CascadeHardDelete:
+		if a.model == nil {
+			res := a.query(tx, name, userID).Delete(nil)
+			return res.RowsAffected, res.Error
+		}
+		for _, dep := range a.dependents {
+			var count int64
+			ids := a.query(tx.Unscoped(), name, userID).Select("id")
+			err := tx.Table(dep.table).Where("? IN (?)", clause.Column{Name: dep.column}, ids).Count(&count).Error
+			if err != nil {
+				return 0, err
+			}
+			if count > 0 {
+				return 0, fmt.Errorf("%w: %s is referenced by %d %s rows", ErrRestricted, name, count, dep.table)
+			}
+		}
+
+		// baris dibaca dulu supaya join table many2many-nya ikut dihapus
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(a.model); err != nil {
+			return 0, err
+		}
+		rows := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+		if err := a.query(tx.Unscoped(), name, userID).Find(rows.Interface()).Error; err != nil {
+			return 0, err
+		}
+		if rows.Elem().Len() == 0 {
+			return 0, nil
+		}
+
+		query := tx.Unscoped()
+		if len(stmt.Schema.Relationships.Many2Many) > 0 {
+			var names []string
+			for _, rel := range stmt.Schema.Relationships.Many2Many {
+				names = append(names, rel.Name)
+			}
+			query = query.Select(names)
+		}
+		res := query.Delete(rows.Interface())
+		return res.RowsAffected, res.Error
+
+	case CascadeNullify:
+		if !a.nullable {
+			return 0, fmt.Errorf("%w: %s cannot be nullified", ErrInvalidCascade, name)
+		}
+		var affected int64
+		for _, column := range a.columns {
+			res := tx.Unscoped().Session(&gorm.Session{SkipHooks: true}).Model(a.newModel()).
+				Where(clause.Eq{Column: clause.Column{Name: column}, Value: userID}).
+				UpdateColumn(column, gorm.Expr("NULL"))
+			if res.Error != nil {
+				return 0, res.Error
+			}
+			affected += res.RowsAffected
+		}
+		return affected, nil
+	}
+	return 0, fmt.Errorf("%w: unknown action %q", ErrInvalidCascade, action)
+}
+
+/**
+*	DeleteCascade menghapus user beserta association-nya sesuai policy dalam
+*	satu transaksi. Aturan restrict dicek lebih dulu, kalau ada yang gagal
+*	tidak ada data yang berubah.
+ */
+func (r *UserRepository) DeleteCascade(ctx context.Context, id string, policy CascadePolicy) (CascadeSummary, error) {
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+
+	actions := policy.actions()
+	names := sortedKeys(actions)
+	summary := CascadeSummary{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := NewRepository[User](tx).Get(ctx, id, Select("id"), lockForUpdate); err != nil {
+			return err
+		}
+
+		for _, restrict := range []bool{true, false} {
+			for _, name := range names {
+				action := actions[name]
+				if (action == CascadeRestrict) != restrict {
+					continue
+				}
+				affected, err := userAssociations[name].apply(tx, name, id, action)
+				if err != nil {
+					return err
+				}
+				summary[name] = affected
+			}
+		}
+
+		query := tx
+		if policy.User == CascadeHardDelete {
+			query = tx.Unscoped()
+		}
+		res := query.Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).Delete(&User{})
+		if res.Error != nil {
+			return res.Error
+		}
+		summary["users"] = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Delete menghapus user dengan DefaultUserCascade.
+func (r *UserRepository) Delete(ctx context.Context, id string) (CascadeSummary, error) {
+	return r.DeleteCascade(ctx, id, DefaultUserCascade)
+}
@@ -0,0 +1,189 @@
+package belajargorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserDeleteCascadeDefault(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	assert.Nil(t, store.Todos.Create(ctx, &Todo{UserID: "1", Task: "Ikut terhapus"}))
+
+	summary, err := store.Users.Delete(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, CascadeSummary{
+		"users":             1,
+		"wallets":           1,
+		"addresses":         2,
+		"todos":             1,
+		"user_like_product": 1,
+	}, summary)
+
+	_, err = store.Wallets.GetByUserID(ctx, "1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	addresses, err := store.Addresses.Trash().List(ctx, Where("user_id = ?", "1"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(addresses))
+
+	var likes int64
+	db.Table("user_like_product").Where("product_id = ?", productID).Count(&likes)
+	assert.Equal(t, int64(1), likes)
+
+	_, err = store.Users.Delete(ctx, "1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUserDeleteCascadeHard(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	todo := Todo{UserID: "4", Task: "Dihapus permanen"}
+	assert.Nil(t, store.Todos.Create(ctx, &todo))
+	assert.Nil(t, store.Todos.SetTags(ctx, todo.ID, []string{"kerja"}))
+	assert.Nil(t, store.Addresses.Create(ctx, &Address{UserID: "4", Line1: "Jalan Hapus"}))
+	assert.Nil(t, store.Users.LikeProduct(ctx, "4", productID))
+
+	var logs int64
+	db.Model(&UserLog{}).Where("user_id = ?", "4").Count(&logs)
+	assert.NotZero(t, logs)
+
+	summary, err := store.Users.DeleteCascade(ctx, "4", CascadePolicy{
+		User: CascadeHardDelete,
+		Associations: map[string]CascadeAction{
+			"wallets":           CascadeRestrict,
+			"addresses":         CascadeHardDelete,
+			"todos":             CascadeHardDelete,
+			"user_logs":         CascadeNullify,
+			"user_like_product": CascadeHardDelete,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), summary["users"])
+	assert.Equal(t, int64(0), summary["wallets"])
+	assert.Equal(t, int64(1), summary["addresses"])
+	assert.Equal(t, int64(1), summary["todos"])
+	// termasuk log audit dari address dan todo yang dihapus sebelumnya
+	assert.Equal(t, logs+2, summary["user_logs"])
+	assert.Equal(t, int64(1), summary["user_like_product"])
+
+	var count int64
+	db.Unscoped().Model(&User{}).Where("id = ?", "4").Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.Unscoped().Model(&Todo{}).Where("user_id = ?", "4").Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.Model(&todoTag{}).Where("todo_id = ?", todo.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// hanya log delete user yang ditulis setelah nullify, log lama berisi NULL
+	db.Model(&UserLog{}).Where("user_id = ? AND entity <> ?", "4", "users").Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.Model(&UserLog{}).Where("user_id IS NULL").Count(&count)
+	assert.Equal(t, summary["user_logs"], count)
+}
+
+func TestUserDeleteCascadeForeignKeys(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	// test sqlite dijalankan dengan foreign key aktif, jadi baris yatim langsung gagal
+	if db.Dialector.Name() == DriverSQLite {
+		var enabled int
+		assert.Nil(t, db.Raw("PRAGMA foreign_keys").Scan(&enabled).Error)
+		assert.Equal(t, 1, enabled)
+	}
+
+	hardDelete := CascadePolicy{
+		User: CascadeHardDelete,
+		Associations: map[string]CascadeAction{
+			"wallets":           CascadeHardDelete,
+			"addresses":         CascadeHardDelete,
+			"todos":             CascadeHardDelete,
+			"user_logs":         CascadeNullify,
+			"user_like_product": CascadeHardDelete,
+		},
+	}
+
+	// wallet 2 punya ledger saldo awal yang append-only
+	_, err := store.Users.DeleteCascade(ctx, "2", hardDelete)
+	assert.ErrorIs(t, err, ErrRestricted)
+	assert.Contains(t, err.Error(), "wallet_transactions")
+
+	// transfer dan order tidak disebut di policy, jadi otomatis restrict
+	_, err = NewLedger(db).Transfer(ctx, "1", "3", 1000, "")
+	assert.Nil(t, err)
+	_, err = store.Users.DeleteCascade(ctx, "3", hardDelete)
+	assert.ErrorIs(t, err, ErrRestricted)
+	assert.Contains(t, err.Error(), "wallet_transfers")
+
+	order := Order{UserID: "5", Status: OrderPending, Total: IDR(0), Currency: DefaultCurrency}
+	assert.Nil(t, db.Create(&order).Error)
+	_, err = store.Users.DeleteCascade(ctx, "5", hardDelete)
+	assert.ErrorIs(t, err, ErrRestricted)
+	assert.Contains(t, err.Error(), "orders")
+
+	for _, id := range []string{"2", "3", "5"} {
+		_, err := store.Users.Get(ctx, id)
+		assert.Nil(t, err, id)
+	}
+
+	// wallet tanpa ledger ikut terhapus permanen
+	assert.Nil(t, db.Create(&Wallet{UserID: "7", Balance: IDR(0)}).Error)
+	summary, err := store.Users.DeleteCascade(ctx, "7", hardDelete)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), summary["wallets"])
+	assert.Equal(t, int64(1), summary["users"])
+}
+
+func TestUserDeleteCascadeRestrict(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	_, err := store.Users.DeleteCascade(ctx, "1", CascadePolicy{
+		User: CascadeSoftDelete,
+		Associations: map[string]CascadeAction{
+			"todos":     CascadeSoftDelete,
+			"addresses": CascadeRestrict,
+		},
+	})
+	assert.ErrorIs(t, err, ErrRestricted)
+
+	_, err = store.Users.Get(ctx, "1")
+	assert.Nil(t, err)
+	addresses, err := store.Addresses.ListAddressesForUser(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(addresses))
+}
+
+func TestUserDeleteCascadeInvalid(t *testing.T) {
+	store := NewStore(beginTest(t))
+	ctx := context.Background()
+
+	policies := []CascadePolicy{
+		{User: CascadeNullify},
+		{User: CascadeSoftDelete, Associations: map[string]CascadeAction{"orders": CascadeHardDelete}},
+		{User: CascadeSoftDelete, Associations: map[string]CascadeAction{"user_like_product": CascadeSoftDelete}},
+		{User: CascadeSoftDelete, Associations: map[string]CascadeAction{"todos": "archive"}},
+		// user_id wallets dan addresses NOT NULL dengan foreign key ke users
+		{User: CascadeHardDelete, Associations: map[string]CascadeAction{"wallets": CascadeNullify}},
+		{User: CascadeHardDelete, Associations: map[string]CascadeAction{"addresses": CascadeNullify}},
+		{User: CascadeSoftDelete, Associations: map[string]CascadeAction{"wallet_transfers": CascadeSoftDelete}},
+	}
+	for _, policy := range policies {
+		_, err := store.Users.DeleteCascade(ctx, "1", policy)
+		assert.ErrorIs(t, err, ErrInvalidCascade)
+	}
+
+	_, err := store.Users.DeleteCascade(ctx, "1", CascadePolicy{
+		User:         CascadeSoftDelete,
+		Associations: map[string]CascadeAction{"user_logs": CascadeSoftDelete},
+	})
+	assert.ErrorIs(t, err, ErrNotSoftDeletable)
+}
@@ -0,0 +1,287 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCategoryCycle     = errors.New("belajargorm: category cannot be moved under itself")
+	ErrInvalidQuantity   = errors.New("belajargorm: quantity must be greater than zero")
+	ErrInsufficientStock = errors.New("belajargorm: insufficient product stock")
+	ErrReleaseExceeded   = errors.New("belajargorm: release exceeds reserved stock")
+)
+
+// Category boleh punya parent, category tanpa parent adalah root. Children
+// hanya diisi oleh CategoryRepository.Tree dan Subtree.
+type Category struct {
+	ID        int64      `gorm:"primary_key;column:id;autoIncrement"`
+	ParentID  *int64     `gorm:"column:parent_id"`
+	Name      string     `gorm:"column:name" validate:"required,max=100"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+	Children  []Category `gorm:"foreignKey:parent_id;references:id"`
+}
+
+func (c *Category) TableName() string {
+	return "categories"
+}
+
+func (c *Category) BeforeSave(db *gorm.DB) error {
+	return validateModel(db, c)
+}
+
+// ProductPrice adalah satu baris riwayat harga product. Table-nya diisi trigger
+// database setiap kali price atau currency product berubah.
+type ProductPrice struct {
+	ID          int64     `gorm:"primary_key;column:id;autoIncrement"`
+	ProductID   int64     `gorm:"column:product_id"`
+	Price       Money     `gorm:"column:price"`
+	Currency    string    `gorm:"column:currency"`
+	EffectiveAt time.Time `gorm:"column:effective_at"`
+}
+
+func (p *ProductPrice) TableName() string {
+	return "product_price_history"
+}
+
+func (p *ProductPrice) AfterFind(db *gorm.DB) error {
+	p.Price.Currency = p.Currency
+	return nil
+}
+
+// categorySubtree berisi id category dan semua turunannya.
+const categorySubtree = `WITH RECURSIVE subtree (id) AS (
+	SELECT id FROM categories WHERE id = ?
+	UNION ALL
+	SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+) SELECT id FROM subtree`
+
+// inCategorySubtree memfilter column yang berisi id category atau turunannya.
+func inCategorySubtree(column string, categoryID int64) clause.Expression {
+	return clause.Expr{
+		SQL:  "? IN (" + categorySubtree + ")",
+		Vars: []any{clause.Column{Name: column}, categoryID},
+	}
+}
+
+type CategoryRepository struct {
+	*Repository[Category]
+}
+
+// Tree mengembalikan semua root category beserta turunannya, diurutkan per nama.
+func (r *CategoryRepository) Tree(ctx context.Context) ([]Category, error) {
+	categories, err := r.List(ctx, OrderBy("name, id"))
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories, nil), nil
+}
+
+// Subtree mengembalikan category id dengan Children berisi semua turunannya.
+func (r *CategoryRepository) Subtree(ctx context.Context, id int64) (*Category, error) {
+	categories, err := r.List(ctx, Where(inCategorySubtree("id", id)), OrderBy("name, id"))
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range categories {
+		if c.ID == id {
+			c.Children = buildCategoryTree(categories, &id)
+			return &c, nil
+		}
+	}
+	return nil, &NotFoundError{Model: r.model, Key: id}
+}
+
+// Descendants mengembalikan semua turunan category id (tanpa category itu sendiri) dalam bentuk datar.
+func (r *CategoryRepository) Descendants(ctx context.Context, id int64) ([]Category, error) {
+	if _, err := r.Get(ctx, id, Select("id")); err != nil {
+		return nil, err
+	}
+	return r.List(ctx, Where(inCategorySubtree("id", id)), Where("id <> ?", id), OrderBy("name, id"))
+}
+
+// Ancestors mengembalikan jalur dari root sampai parent category id.
+func (r *CategoryRepository) Ancestors(ctx context.Context, id int64) ([]Category, error) {
+	category, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var path []Category
+	for parentID := category.ParentID; parentID != nil; {
+		parent, err := r.Get(ctx, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		path = append([]Category{*parent}, path...)
+		parentID = parent.ParentID
+	}
+	return path, nil
+}
+
+// Move memindahkan category ke parent lain (nil untuk menjadikannya root).
+// Parent tidak boleh category itu sendiri atau turunannya.
+func (r *CategoryRepository) Move(ctx context.Context, id int64, parentID *int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		categories := NewRepository[Category](tx)
+		if _, err := categories.Get(ctx, id, Select("id"), lockForUpdate); err != nil {
+			return err
+		}
+		if parentID != nil {
+			if _, err := categories.Get(ctx, *parentID, Select("id")); err != nil {
+				return err
+			}
+			cycle, err := categories.Exists(ctx, Where(inCategorySubtree("id", id)), Where("id = ?", *parentID))
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return ErrCategoryCycle
+			}
+		}
+		return tx.Model(&Category{ID: id}).Update("parent_id", parentID).Error
+	})
+}
+
+// buildCategoryTree menyusun Children dari daftar datar, parentID nil berarti mulai dari root.
+func buildCategoryTree(categories []Category, parentID *int64) []Category {
+	// id category mulai dari 1, jadi 0 dipakai sebagai parent untuk root
+	children := map[int64][]Category{}
+	for _, c := range categories {
+		var parent int64
+		if c.ParentID != nil {
+			parent = *c.ParentID
+		}
+		children[parent] = append(children[parent], c)
+	}
+
+	var attach func(parent int64) []Category
+	attach = func(parent int64) []Category {
+		nodes := children[parent]
+		for i := range nodes {
+			nodes[i].Children = attach(nodes[i].ID)
+		}
+		return nodes
+	}
+
+	var root int64
+	if parentID != nil {
+		root = *parentID
+	}
+	return attach(root)
+}
+
+// ListInCategory mengembalikan product di category, termasuk sub-category kalau recursive.
+func (r *ProductRepository) ListInCategory(ctx context.Context, categoryID int64, recursive bool) ([]Product, error) {
+	if _, err := NewRepository[Category](r.db).Get(ctx, categoryID, Select("id")); err != nil {
+		return nil, err
+	}
+	if !recursive {
+		return r.List(ctx, Where("category_id = ?", categoryID), OrderBy("name, id"))
+	}
+	return r.List(ctx, Where(inCategorySubtree("category_id", categoryID)), OrderBy("name, id"))
+}
+
+/**
+*	adjustStock menjalankan satu UPDATE bersyarat, jadi aman dipanggil
+*	bersamaan tanpa lock: kalau syarat tidak terpenuhi tidak ada baris yang
+*	berubah dan failure dikembalikan (atau NotFoundError kalau product tidak
+*	ada). condition kosong berarti tanpa syarat.
+ */
+func (r *ProductRepository) adjustStock(ctx context.Context, id, quantity int64, changes map[string]any, condition string, failure error) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	changes["updated_at"] = time.Now()
+
+	// lewat Table, bukan Model, karena stock dan reserved tidak bisa diubah lewat field Product
+	query := r.db.WithContext(ctx).Table("products").Where("id = ? AND deleted_at IS NULL", id)
+	if condition != "" {
+		query = query.Where(condition, quantity)
+	}
+	res := query.UpdateColumns(changes)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+	if _, err := r.Get(ctx, id, Select("id")); err != nil {
+		return err
+	}
+	return failure
+}
+
+// Restock menambah stock product.
+func (r *ProductRepository) Restock(ctx context.Context, id, quantity int64) error {
+	return r.adjustStock(ctx, id, quantity, map[string]any{
+		"stock": gorm.Expr("stock + ?", quantity),
+	}, "", nil)
+}
+
+// Reserve menahan stock untuk pesanan, gagal dengan ErrInsufficientStock kalau
+// stock yang tersedia (stock - reserved) kurang.
+func (r *ProductRepository) Reserve(ctx context.Context, id, quantity int64) error {
+	return r.adjustStock(ctx, id, quantity, map[string]any{
+		"reserved": gorm.Expr("reserved + ?", quantity),
+	}, "stock - reserved >= ?", ErrInsufficientStock)
+}
+
+// Sell mengurangi stock yang tersedia (tidak termasuk yang sedang di-Reserve)
+// secara langsung, dipakai Checkout.
+func (r *ProductRepository) Sell(ctx context.Context, id, quantity int64) error {
+	return r.adjustStock(ctx, id, quantity, map[string]any{
+		"stock": gorm.Expr("stock - ?", quantity),
+	}, "stock - reserved >= ?", ErrInsufficientStock)
+}
+
+// Release melepas stock yang sebelumnya di-Reserve.
+func (r *ProductRepository) Release(ctx context.Context, id, quantity int64) error {
+	return r.adjustStock(ctx, id, quantity, map[string]any{
+		"reserved": gorm.Expr("reserved - ?", quantity),
+	}, "reserved >= ?", ErrReleaseExceeded)
+}
+
+// Fulfill mengurangi stock yang sudah di-Reserve, dipakai ketika barang dikirim.
+func (r *ProductRepository) Fulfill(ctx context.Context, id, quantity int64) error {
+	return r.adjustStock(ctx, id, quantity, map[string]any{
+		"stock":    gorm.Expr("stock - ?", quantity),
+		"reserved": gorm.Expr("reserved - ?", quantity),
+	}, "reserved >= ?", ErrReleaseExceeded)
+}
+
+// PriceHistory mengembalikan riwayat harga product, yang terbaru lebih dulu.
+func (r *ProductRepository) PriceHistory(ctx context.Context, id int64) ([]ProductPrice, error) {
+	if _, err := r.Get(ctx, id, Select("id")); err != nil {
+		return nil, err
+	}
+	var prices []ProductPrice
+	err := r.db.WithContext(ctx).
+		Where("product_id = ?", id).
+		Order("effective_at DESC, id DESC").
+		Find(&prices).Error
+	return prices, err
+}
+
+// PriceAt mengembalikan harga yang berlaku pada waktu at. NotFoundError kalau
+// product belum ada pada waktu itu.
+func (r *ProductRepository) PriceAt(ctx context.Context, id int64, at time.Time) (*ProductPrice, error) {
+	var price ProductPrice
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND effective_at <= ?", id, at.UTC()).
+		Order("effective_at DESC, id DESC").
+		Take(&price).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &NotFoundError{Model: "ProductPrice", Key: fmt.Sprintf("%d at %s", id, at.Format(time.RFC3339))}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
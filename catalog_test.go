@@ -0,0 +1,222 @@
+package belajargorm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryTree(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	minuman := Category{Name: "Minuman"}
+	makanan := Category{Name: "Makanan"}
+	assert.Nil(t, store.Categories.Create(ctx, &minuman))
+	assert.Nil(t, store.Categories.Create(ctx, &makanan))
+	kopi := Category{Name: "Kopi", ParentID: &minuman.ID}
+	teh := Category{Name: "Teh", ParentID: &minuman.ID}
+	assert.Nil(t, store.Categories.Create(ctx, &kopi))
+	assert.Nil(t, store.Categories.Create(ctx, &teh))
+	espresso := Category{Name: "Espresso", ParentID: &kopi.ID}
+	assert.Nil(t, store.Categories.Create(ctx, &espresso))
+
+	tree, err := store.Categories.Tree(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tree))
+	assert.Equal(t, "Makanan", tree[0].Name)
+	assert.Equal(t, "Minuman", tree[1].Name)
+	assert.Equal(t, 2, len(tree[1].Children))
+	assert.Equal(t, "Kopi", tree[1].Children[0].Name)
+	assert.Equal(t, "Espresso", tree[1].Children[0].Children[0].Name)
+
+	descendants, err := store.Categories.Descendants(ctx, minuman.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(descendants))
+
+	subtree, err := store.Categories.Subtree(ctx, kopi.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "Kopi", subtree.Name)
+	assert.Equal(t, 1, len(subtree.Children))
+
+	path, err := store.Categories.Ancestors(ctx, espresso.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(path))
+	assert.Equal(t, "Minuman", path[0].Name)
+	assert.Equal(t, "Kopi", path[1].Name)
+
+	// sesama sibling tidak boleh punya nama sama
+	err = store.Categories.Create(ctx, &Category{Name: "Kopi", ParentID: &minuman.ID})
+	assert.NotNil(t, err)
+
+	assert.ErrorIs(t, store.Categories.Move(ctx, minuman.ID, &espresso.ID), ErrCategoryCycle)
+	assert.ErrorIs(t, store.Categories.Move(ctx, kopi.ID, &kopi.ID), ErrCategoryCycle)
+	assert.Nil(t, store.Categories.Move(ctx, kopi.ID, &makanan.ID))
+	assert.Nil(t, store.Categories.Move(ctx, teh.ID, nil))
+
+	descendants, err = store.Categories.Descendants(ctx, makanan.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(descendants))
+
+	_, err = store.Categories.Subtree(ctx, 999)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestListInCategory(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	minuman := Category{Name: "Minuman"}
+	assert.Nil(t, store.Categories.Create(ctx, &minuman))
+	kopi := Category{Name: "Kopi", ParentID: &minuman.ID}
+	assert.Nil(t, store.Categories.Create(ctx, &kopi))
+
+	products := []Product{
+		{Name: "Air Mineral", Price: IDR(5000), CategoryID: &minuman.ID},
+		{Name: "Kopi Hitam", Price: IDR(15000), CategoryID: &kopi.ID},
+		{Name: "Roti", Price: IDR(10000)},
+	}
+	for i := range products {
+		assert.Nil(t, store.Products.Create(ctx, &products[i]))
+	}
+
+	direct, err := store.Products.ListInCategory(ctx, minuman.ID, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(direct))
+	assert.Equal(t, "Air Mineral", direct[0].Name)
+
+	all, err := store.Products.ListInCategory(ctx, minuman.ID, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(all))
+	assert.Equal(t, "Kopi Hitam", all[1].Name)
+
+	_, err = store.Products.ListInCategory(ctx, 999, true)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestProductStock(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	product := Product{Name: "Teh Botol", Price: IDR(6000), Stock: 5}
+	assert.Nil(t, store.Products.Create(ctx, &product))
+
+	assert.Nil(t, store.Products.Reserve(ctx, product.ID, 3))
+	assert.ErrorIs(t, store.Products.Reserve(ctx, product.ID, 3), ErrInsufficientStock)
+	assert.ErrorIs(t, store.Products.Reserve(ctx, product.ID, 0), ErrInvalidQuantity)
+
+	assert.Nil(t, store.Products.Release(ctx, product.ID, 1))
+	assert.ErrorIs(t, store.Products.Release(ctx, product.ID, 5), ErrReleaseExceeded)
+	assert.Nil(t, store.Products.Fulfill(ctx, product.ID, 2))
+	assert.Nil(t, store.Products.Restock(ctx, product.ID, 10))
+
+	loaded, err := store.Products.Get(ctx, product.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(13), loaded.Stock)
+	assert.Equal(t, int64(0), loaded.Reserved)
+	assert.Equal(t, int64(13), loaded.Available())
+
+	// stock tidak ikut tersimpan lewat Update biasa
+	loaded.Stock = 100
+	assert.Nil(t, store.Products.Update(ctx, loaded))
+	loaded, err = store.Products.Get(ctx, product.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(13), loaded.Stock)
+
+	assert.ErrorIs(t, store.Products.Reserve(ctx, 999, 1), ErrNotFound)
+}
+
+func TestProductReserveConcurrent(t *testing.T) {
+	db := openMigratedDB(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	product := Product{Name: "Edisi Terbatas", Price: IDR(100000), Stock: 10}
+	assert.Nil(t, store.Products.Create(ctx, &product))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved int
+		rejected int
+	)
+	for i := 0; i < 25; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.Products.Reserve(ctx, product.ID, 1)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				reserved++
+			case assert.ErrorIs(t, err, ErrInsufficientStock):
+				rejected++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, reserved)
+	assert.Equal(t, 15, rejected)
+
+	loaded, err := store.Products.Get(ctx, product.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), loaded.Reserved)
+	assert.Equal(t, int64(0), loaded.Available())
+}
+
+func TestProductPriceHistory(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	product := Product{Name: "Es Teh", Price: IDR(3000)}
+	assert.Nil(t, store.Products.Create(ctx, &product))
+
+	// Save tanpa perubahan harga tidak menambah riwayat
+	product.Name = "Es Teh Manis"
+	assert.Nil(t, store.Products.Update(ctx, &product))
+	product.Price = IDR(4000)
+	assert.Nil(t, store.Products.Update(ctx, &product))
+	assert.Nil(t, store.Products.UpdateFields(ctx, product.ID, map[string]any{"price": IDR(5000)}))
+
+	history, err := store.Products.PriceHistory(ctx, product.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(history))
+	assert.Equal(t, IDR(5000), history[0].Price)
+	assert.Equal(t, IDR(3000), history[2].Price)
+
+	// geser waktu berlaku supaya tiap harga punya rentang yang jelas
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, price := range history {
+		effectiveAt := base.AddDate(0, len(history)-1-i, 0)
+		assert.Nil(t, db.Model(&ProductPrice{}).Where("id = ?", price.ID).Update("effective_at", effectiveAt).Error)
+	}
+
+	price, err := store.Products.PriceAt(ctx, product.ID, base.AddDate(0, 0, 15))
+	assert.Nil(t, err)
+	assert.Equal(t, IDR(3000), price.Price)
+
+	price, err = store.Products.PriceAt(ctx, product.ID, base.AddDate(0, 1, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, IDR(4000), price.Price)
+
+	price, err = store.Products.PriceAt(ctx, product.ID, time.Now().In(time.FixedZone("WIB", 7*3600)))
+	assert.Nil(t, err)
+	assert.Equal(t, IDR(5000), price.Price)
+
+	_, err = store.Products.PriceAt(ctx, product.ID, base.Add(-time.Hour))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// fixture product juga tercatat saat dibuat
+	price, err = store.Products.PriceAt(ctx, productID, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, IDR(25000), price.Price)
+}
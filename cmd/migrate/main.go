@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	belajargorm "belajar-gorm"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "print SQL without touching the database")
+	down := flag.Int("down", -1, "rollback N migrations (0 = all)")
+	status := flag.Bool("status", false, "show migration status")
+	forceUnlock := flag.Bool("force-unlock", false, "remove a stale migration lock")
+	flag.Parse()
+
+	db, err := belajargorm.OpenConnection()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := belajargorm.NewSchemaMigrator(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrator.DryRun = *dryRun
+
+	ctx := context.Background()
+
+	switch {
+	case *forceUnlock:
+		err = migrator.ForceUnlock(ctx)
+	case *status:
+		var statuses []belajargorm.MigrationStatus
+		statuses, err = migrator.Status(ctx)
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d %-30s %s\n", s.Version, s.Name, state)
+		}
+	case *down >= 0:
+		err = migrator.Down(ctx, *down)
+	default:
+		err = migrator.Up(ctx)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	belajargorm "belajar-gorm"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	retention := flag.Duration("purge-retention", belajargorm.DefaultRetention, "how long soft deleted rows are kept before purge")
+	purgeInterval := flag.Duration("purge-interval", time.Hour, "how often soft deleted rows are purged, 0 disables the purger")
+	flag.Parse()
+
+	db, err := belajargorm.OpenConnection()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer belajargorm.Close(db)
+
+	store := belajargorm.NewStore(db)
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           belajargorm.NewServer(store),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       time.Minute,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *purgeInterval > 0 {
+		purger := belajargorm.NewPurger(store, *retention)
+		purger.Interval = *purgeInterval
+		go purger.Run(ctx)
+	}
+
+	go func() {
+		log.Printf("listening on %s", *addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,449 @@
+package belajargorm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+var (
+	ErrUnknownDriver  = errors.New("belajargorm: unknown database driver")
+	ErrInvalidSSLMode = errors.New("belajargorm: invalid sslmode")
+	ErrInvalidConfig  = errors.New("belajargorm: invalid database config")
+)
+
+// Duration supaya field durasi di file config bisa ditulis sebagai "30m", "5s", dst.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(b, &n); err != nil {
+			return fmt.Errorf("duration: %w", err)
+		}
+		*d = Duration(n)
+		return nil
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+type Config struct {
+	Driver       string `json:"driver"`
+	Host         string `json:"host"`
+	Port         int    `json:"port"`
+	User         string `json:"user"`
+	Password     string `json:"password"`
+	PasswordFile string `json:"password_file"`
+	DBName       string `json:"dbname"`
+	TimeZone     string `json:"timezone"`
+
+	SSLMode     string `json:"sslmode"`
+	SSLRootCert string `json:"sslrootcert"`
+	SSLCert     string `json:"sslcert"`
+	SSLKey      string `json:"sslkey"`
+
+	SQLitePath string `json:"sqlite_path"`
+
+	// Replicas berisi host (atau host:port) read replica Postgres, atau path
+	// file untuk SQLite. Setting lain sama dengan primary.
+	Replicas []string `json:"replicas"`
+
+	LogLevel        string   `json:"log_level"`
+	MaxOpenConns    int      `json:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Driver:          DriverPostgres,
+		Host:            "localhost",
+		Port:            5432,
+		User:            "postgres",
+		DBName:          "belajar_gorm",
+		TimeZone:        "UTC",
+		SSLMode:         "disable",
+		SQLitePath:      "belajar_gorm.db",
+		LogLevel:        "warn",
+		MaxOpenConns:    100,
+		MaxIdleConns:    10,
+		ConnMaxLifetime: Duration(30 * time.Minute),
+		ConnMaxIdleTime: Duration(5 * time.Minute),
+	}
+}
+
+// LoadConfig membaca config dengan urutan: default, file dari DB_CONFIG_FILE
+// (kalau ada), lalu environment variable DB_*. Secret yang diisi lewat *_FILE
+// dibaca dari file tersebut.
+func LoadConfig() (Config, error) {
+	cfg := DefaultConfig()
+
+	if path := os.Getenv("DB_CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := cfg.loadEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+
+	if err := cfg.resolveSecrets(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	if err := json.Unmarshal(b, c); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) loadEnv(lookup func(string) (string, bool)) error {
+	strs := map[string]*string{
+		"DB_DRIVER":        &c.Driver,
+		"DB_HOST":          &c.Host,
+		"DB_USER":          &c.User,
+		"DB_PASSWORD":      &c.Password,
+		"DB_PASSWORD_FILE": &c.PasswordFile,
+		"DB_NAME":          &c.DBName,
+		"DB_TIMEZONE":      &c.TimeZone,
+		"DB_SSLMODE":       &c.SSLMode,
+		"DB_SSLROOTCERT":   &c.SSLRootCert,
+		"DB_SSLCERT":       &c.SSLCert,
+		"DB_SSLKEY":        &c.SSLKey,
+		"DB_SQLITE_PATH":   &c.SQLitePath,
+		"DB_LOG_LEVEL":     &c.LogLevel,
+	}
+	for key, dst := range strs {
+		if v, ok := lookup(key); ok {
+			*dst = v
+		}
+	}
+
+	ints := map[string]*int{
+		"DB_PORT":           &c.Port,
+		"DB_MAX_OPEN_CONNS": &c.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS": &c.MaxIdleConns,
+	}
+	for key, dst := range ints {
+		v, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		*dst = n
+	}
+
+	if v, ok := lookup("DB_REPLICAS"); ok {
+		c.Replicas = nil
+		for _, replica := range strings.Split(v, ",") {
+			if replica = strings.TrimSpace(replica); replica != "" {
+				c.Replicas = append(c.Replicas, replica)
+			}
+		}
+	}
+
+	durations := map[string]*Duration{
+		"DB_CONN_MAX_LIFETIME":  &c.ConnMaxLifetime,
+		"DB_CONN_MAX_IDLE_TIME": &c.ConnMaxIdleTime,
+	}
+	for key, dst := range durations {
+		v, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		*dst = Duration(d)
+	}
+
+	return nil
+}
+
+func (c *Config) resolveSecrets() error {
+	if c.PasswordFile == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(c.PasswordFile)
+	if err != nil {
+		return fmt.Errorf("read password file: %w", err)
+	}
+	c.Password = strings.TrimRight(string(b), "\r\n")
+	return nil
+}
+
+func (c Config) Validate() error {
+	for _, replica := range c.Replicas {
+		if replica == "" {
+			return fmt.Errorf("%w: replica is empty", ErrInvalidConfig)
+		}
+	}
+
+	switch c.Driver {
+	case DriverSQLite:
+		if c.SQLitePath == "" {
+			return fmt.Errorf("%w: sqlite_path is empty", ErrInvalidConfig)
+		}
+		if len(c.Replicas) > 0 && strings.Contains(c.SQLitePath, ":memory:") {
+			return fmt.Errorf("%w: in-memory sqlite cannot have replicas", ErrInvalidConfig)
+		}
+		return nil
+	case DriverPostgres:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownDriver, c.Driver)
+	}
+
+	if c.Host == "" || c.DBName == "" {
+		return fmt.Errorf("%w: host and dbname are required", ErrInvalidConfig)
+	}
+
+	switch c.SSLMode {
+	case "disable", "allow", "prefer", "require":
+	case "verify-ca", "verify-full":
+		if c.SSLRootCert == "" {
+			return fmt.Errorf("%w: sslmode %s needs sslrootcert", ErrInvalidConfig, c.SSLMode)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidSSLMode, c.SSLMode)
+	}
+
+	if (c.SSLCert == "") != (c.SSLKey == "") {
+		return fmt.Errorf("%w: sslcert and sslkey must be set together", ErrInvalidConfig)
+	}
+
+	for _, path := range []string{c.SSLRootCert, c.SSLCert, c.SSLKey} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+	}
+
+	return nil
+}
+
+// replicaConfig mengembalikan config primary dengan host/port atau path
+// SQLite diganti alamat replica.
+func (c Config) replicaConfig(addr string) (Config, error) {
+	replica := c
+	replica.Replicas = nil
+	if c.Driver == DriverSQLite {
+		replica.SQLitePath = addr
+		return replica, nil
+	}
+
+	replica.Host = addr
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return replica, fmt.Errorf("%w: replica %s: invalid port", ErrInvalidConfig, addr)
+		}
+		replica.Host, replica.Port = host, n
+	}
+	return replica, nil
+}
+
+func (c Config) DSN() string {
+	if c.Driver == DriverSQLite {
+		return c.SQLitePath
+	}
+
+	parts := []string{
+		"host=" + quoteDSN(c.Host),
+		"port=" + strconv.Itoa(c.Port),
+		"user=" + quoteDSN(c.User),
+		"password=" + quoteDSN(c.Password),
+		"dbname=" + quoteDSN(c.DBName),
+		"sslmode=" + quoteDSN(c.SSLMode),
+	}
+	if c.SSLRootCert != "" {
+		parts = append(parts, "sslrootcert="+quoteDSN(c.SSLRootCert))
+	}
+	if c.SSLCert != "" {
+		parts = append(parts, "sslcert="+quoteDSN(c.SSLCert), "sslkey="+quoteDSN(c.SSLKey))
+	}
+	if c.TimeZone != "" {
+		parts = append(parts, "TimeZone="+quoteDSN(c.TimeZone))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func quoteDSN(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + r.Replace(v) + "'"
+}
+
+func (c Config) Dialector() (gorm.Dialector, error) {
+	switch c.Driver {
+	case DriverPostgres:
+		return postgres.Open(c.DSN()), nil
+	case DriverSQLite:
+		return sqlite.Open(c.DSN()), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDriver, c.Driver)
+	}
+}
+
+func (c Config) logLevel() logger.LogLevel {
+	switch strings.ToLower(c.LogLevel) {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "info":
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+func Open(cfg Config) (*gorm.DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	db, err := cfg.open()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Use(AuditPlugin{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(OptimisticLockPlugin{}); err != nil {
+		return nil, err
+	}
+	if err := setupLikeJoinTable(db); err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Replicas) == 0 {
+		return db, nil
+	}
+
+	plugin := NewReplicaPlugin()
+	for _, addr := range cfg.Replicas {
+		replicaCfg, err := cfg.replicaConfig(addr)
+		if err != nil {
+			plugin.Close()
+			return nil, err
+		}
+		replica, err := replicaCfg.open()
+		if err != nil {
+			plugin.Close()
+			return nil, fmt.Errorf("replica %s: %w", addr, err)
+		}
+		plugin.Replicas = append(plugin.Replicas, replica.ConnPool)
+	}
+	if err := db.Use(plugin); err != nil {
+		plugin.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// open membuka satu koneksi pool tanpa plugin, dipakai untuk primary dan replica.
+func (c Config) open() (*gorm.DB, error) {
+	dialector, err := c.Dialector()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         logger.Default.LogMode(c.logLevel()),
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", c.Driver, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Driver == DriverSQLite && strings.Contains(c.SQLitePath, ":memory:") {
+		// tiap koneksi in-memory punya database sendiri, jadi koneksinya
+		// harus satu dan tidak boleh ditutup
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		return db, nil
+	}
+
+	sqlDB.SetMaxOpenConns(c.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(c.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(c.ConnMaxLifetime))
+	sqlDB.SetConnMaxIdleTime(time.Duration(c.ConnMaxIdleTime))
+
+	return db, nil
+}
+
+// Close menutup koneksi primary dan semua replica.
+func Close(db *gorm.DB) error {
+	var errs []error
+	if plugin, ok := db.Config.Plugins[replicaPluginName].(*ReplicaPlugin); ok {
+		errs = append(errs, plugin.Close())
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return errors.Join(append(errs, sqlDB.Close())...)
+}
+
+func OpenConnection() (*gorm.DB, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return Open(cfg)
+}
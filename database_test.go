@@ -0,0 +1,97 @@
+package belajargorm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigFromFileAndEnv(t *testing.T) {
+	dir := t.TempDir()
+
+	secret := filepath.Join(dir, "password")
+	err := os.WriteFile(secret, []byte("s3cr3t 'pw'\n"), 0o600)
+	assert.Nil(t, err)
+
+	file := filepath.Join(dir, "db.json")
+	err = os.WriteFile(file, []byte(`{"host":"db.internal","port":6432,"conn_max_lifetime":"1m"}`), 0o600)
+	assert.Nil(t, err)
+
+	t.Setenv("DB_DRIVER", DriverPostgres)
+	t.Setenv("DB_CONFIG_FILE", file)
+	t.Setenv("DB_NAME", "toko")
+	t.Setenv("DB_PASSWORD_FILE", secret)
+
+	cfg, err := LoadConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, "db.internal", cfg.Host)
+	assert.Equal(t, 6432, cfg.Port)
+	assert.Equal(t, "toko", cfg.DBName)
+	assert.Equal(t, "s3cr3t 'pw'", cfg.Password)
+	assert.Equal(t, Duration(time.Minute), cfg.ConnMaxLifetime)
+	assert.True(t, strings.Contains(cfg.DSN(), `password='s3cr3t \'pw\''`))
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SSLMode = "verify-full"
+	assert.ErrorIs(t, cfg.Validate(), ErrInvalidConfig)
+
+	cfg.SSLRootCert = filepath.Join(t.TempDir(), "ca.pem")
+	assert.ErrorIs(t, cfg.Validate(), ErrInvalidConfig)
+
+	err := os.WriteFile(cfg.SSLRootCert, []byte("ca"), 0o600)
+	assert.Nil(t, err)
+	assert.Nil(t, cfg.Validate())
+	assert.True(t, strings.Contains(cfg.DSN(), "sslmode=verify-full sslrootcert="))
+
+	cfg.SSLMode = "yolo"
+	assert.ErrorIs(t, cfg.Validate(), ErrInvalidSSLMode)
+
+	cfg.Driver = "oracle"
+	assert.ErrorIs(t, cfg.Validate(), ErrUnknownDriver)
+
+	cfg = DefaultConfig()
+	cfg.Driver = DriverSQLite
+	cfg.SQLitePath = ":memory:"
+	cfg.Replicas = []string{"replica.db"}
+	assert.ErrorIs(t, cfg.Validate(), ErrInvalidConfig)
+}
+
+func TestConfigReplicas(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Nil(t, cfg.loadEnv(func(key string) (string, bool) {
+		if key == "DB_REPLICAS" {
+			return " replica-1, replica-2:6432 ,", true
+		}
+		return "", false
+	}))
+	assert.Equal(t, []string{"replica-1", "replica-2:6432"}, cfg.Replicas)
+
+	replica, err := cfg.replicaConfig(cfg.Replicas[1])
+	assert.Nil(t, err)
+	assert.Equal(t, "replica-2", replica.Host)
+	assert.Equal(t, 6432, replica.Port)
+	assert.Nil(t, replica.Replicas)
+
+	replica, err = cfg.replicaConfig(cfg.Replicas[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "replica-1", replica.Host)
+	assert.Equal(t, 5432, replica.Port)
+}
+
+func TestOpenSQLite(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Driver = DriverSQLite
+	cfg.SQLitePath = filepath.Join(t.TempDir(), "test.db")
+
+	conn, err := Open(cfg)
+	assert.Nil(t, err)
+
+	err = conn.AutoMigrate(&GuestBook{})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,346 @@
+package belajargorm
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrInvalidFilter = errors.New("belajargorm: invalid filter")
+
+type FilterOp string
+
+const (
+	OpEq   FilterOp = "eq"
+	OpNe   FilterOp = "ne"
+	OpLt   FilterOp = "lt"
+	OpLte  FilterOp = "lte"
+	OpGt   FilterOp = "gt"
+	OpGte  FilterOp = "gte"
+	OpIn   FilterOp = "in"
+	OpLike FilterOp = "like"
+	OpNull FilterOp = "null"
+)
+
+// filterSymbols diurutkan dari yang terpanjang supaya ">=" tidak terbaca sebagai ">".
+var filterSymbols = []struct {
+	symbol string
+	op     FilterOp
+}{
+	{"!=", OpNe},
+	{">=", OpGte},
+	{"<=", OpLte},
+	{"=", OpEq},
+	{">", OpGt},
+	{"<", OpLt},
+	{"~", OpLike},
+}
+
+// filterReservedKeys adalah parameter pagination yang boleh ada di query string
+// yang sama tapi bukan filter.
+var filterReservedKeys = map[string]bool{
+	"limit":  true,
+	"offset": true,
+	"cursor": true,
+	"total":  true,
+}
+
+// FilterFields berisi field yang boleh difilter dan diurutkan lewat ParseFilter.
+// Field association ditulis "Association.kolom", misalnya "Wallet.balance".
+type FilterFields struct {
+	Filter []string
+	Sort   []string
+}
+
+type Filterable interface {
+	FilterFields() FilterFields
+}
+
+type Condition struct {
+	Field string
+	Op    FilterOp
+	Value string
+}
+
+// Filter adalah hasil ParseFilter yang sudah divalidasi terhadap allowlist model.
+type Filter struct {
+	Conditions []Condition
+	Sort       []SortField
+
+	exprs []clause.Expression
+	order []sortColumn
+	joins []string
+}
+
+var filterSchemas sync.Map
+
+/**
+*	ParseFilter membaca query string seperti
+*	first_name~User&balance>=1000000&sort=-created_at,id
+*	Operator: = != < <= > >= ~ (like), atau bentuk panjang field:op=value
+*	dengan op eq, ne, lt, lte, gt, gte, in (dipisah koma), like dan null
+*	(true/false). Field yang tidak ada di FilterFields model ditolak.
+ */
+func ParseFilter[T any](query string) (*Filter, error) {
+	model, ok := any(new(T)).(Filterable)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not declare filter fields", ErrInvalidFilter, model)
+	}
+	s, err := schema.Parse(model, &filterSchemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := model.FilterFields()
+	filter := &Filter{}
+	for _, term := range strings.Split(query, "&") {
+		if term == "" {
+			continue
+		}
+
+		key, op, value, err := splitFilterTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		if filterReservedKeys[key] {
+			continue
+		}
+		if key == "sort" {
+			if err := filter.addSort(s, fields.Sort, value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := filter.addCondition(s, fields.Filter, Condition{Field: key, Op: op, Value: value}); err != nil {
+			return nil, err
+		}
+	}
+	if len(filter.Sort) > 0 {
+		// urutan yang sama dengan Page: NULL di akhir dan primary key sebagai pemisah
+		if filter.order, err = sortColumns(s, filter.Sort); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+func splitFilterTerm(term string) (string, FilterOp, string, error) {
+	idx := strings.IndexAny(term, "!<>=~")
+	if idx <= 0 {
+		return "", "", "", fmt.Errorf("%w: missing operator in %q", ErrInvalidFilter, term)
+	}
+
+	var op FilterOp
+	rest := term[idx:]
+	for _, s := range filterSymbols {
+		if strings.HasPrefix(rest, s.symbol) {
+			op, rest = s.op, rest[len(s.symbol):]
+			break
+		}
+	}
+	if op == "" {
+		return "", "", "", fmt.Errorf("%w: unknown operator in %q", ErrInvalidFilter, term)
+	}
+
+	key, err := url.QueryUnescape(term[:idx])
+	if err != nil {
+		return "", "", "", fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	value, err := url.QueryUnescape(rest)
+	if err != nil {
+		return "", "", "", fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+
+	if field, named, ok := strings.Cut(key, ":"); ok {
+		if op != OpEq {
+			return "", "", "", fmt.Errorf("%w: %q must use = with a named operator", ErrInvalidFilter, key)
+		}
+		switch FilterOp(named) {
+		case OpEq, OpNe, OpLt, OpLte, OpGt, OpGte, OpIn, OpLike, OpNull:
+			return field, FilterOp(named), value, nil
+		default:
+			return "", "", "", fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, named)
+		}
+	}
+	return key, op, value, nil
+}
+
+func allowed(list []string, name string) bool {
+	for _, item := range list {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveFilterField mencari kolom untuk nama field yang sudah lolos allowlist.
+// Field association hanya boleh dari relasi has one / belongs to supaya join
+// tidak menggandakan baris.
+func (f *Filter) resolveFilterField(s *schema.Schema, name string) (*schema.Field, clause.Column, error) {
+	table, fieldName := clause.CurrentTable, name
+	fieldSchema := s
+
+	if rel, column, ok := strings.Cut(name, "."); ok {
+		relation, found := s.Relationships.Relations[rel]
+		if !found || (relation.Type != schema.HasOne && relation.Type != schema.BelongsTo) {
+			return nil, clause.Column{}, fmt.Errorf("%w: %s is not a single association", ErrInvalidFilter, rel)
+		}
+		table, fieldName, fieldSchema = rel, column, relation.FieldSchema
+		if !allowed(f.joins, rel) {
+			f.joins = append(f.joins, rel)
+		}
+	}
+
+	field := fieldSchema.LookUpField(fieldName)
+	if field == nil || field.DBName == "" {
+		return nil, clause.Column{}, fmt.Errorf("%w: unknown field %s", ErrInvalidFilter, name)
+	}
+	return field, clause.Column{Table: table, Name: field.DBName}, nil
+}
+
+func (f *Filter) addSort(s *schema.Schema, allowlist []string, value string) error {
+	for _, name := range strings.Split(value, ",") {
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		if !allowed(allowlist, name) {
+			return fmt.Errorf("%w: cannot sort by %q", ErrInvalidFilter, name)
+		}
+
+		if _, _, err := f.resolveFilterField(s, name); err != nil {
+			return err
+		}
+		f.Sort = append(f.Sort, SortField{Column: name, Desc: desc})
+	}
+	return nil
+}
+
+func (f *Filter) addCondition(s *schema.Schema, allowlist []string, cond Condition) error {
+	if !allowed(allowlist, cond.Field) {
+		return fmt.Errorf("%w: cannot filter by %q", ErrInvalidFilter, cond.Field)
+	}
+
+	field, column, err := f.resolveFilterField(s, cond.Field)
+	if err != nil {
+		return err
+	}
+
+	var expr clause.Expression
+	switch cond.Op {
+	case OpLike:
+		pattern := "%" + escapeLike(strings.ToLower(cond.Value)) + "%"
+		expr = clause.Expr{SQL: `LOWER(?) LIKE ? ESCAPE '\'`, Vars: []any{column, pattern}}
+	case OpNull:
+		isNull, err := strconv.ParseBool(cond.Value)
+		if err != nil {
+			return fmt.Errorf("%w: %s:null must be true or false", ErrInvalidFilter, cond.Field)
+		}
+		if isNull {
+			expr = clause.Expr{SQL: "? IS NULL", Vars: []any{column}}
+		} else {
+			expr = clause.Expr{SQL: "? IS NOT NULL", Vars: []any{column}}
+		}
+	case OpIn:
+		var values []any
+		for _, raw := range strings.Split(cond.Value, ",") {
+			v, err := parseFilterValue(field, raw)
+			if err != nil {
+				return err
+			}
+			values = append(values, v)
+		}
+		expr = clause.IN{Column: column, Values: values}
+	default:
+		v, err := parseFilterValue(field, cond.Value)
+		if err != nil {
+			return err
+		}
+		switch cond.Op {
+		case OpEq:
+			expr = clause.Eq{Column: column, Value: v}
+		case OpNe:
+			expr = clause.Neq{Column: column, Value: v}
+		case OpLt:
+			expr = clause.Lt{Column: column, Value: v}
+		case OpLte:
+			expr = clause.Lte{Column: column, Value: v}
+		case OpGt:
+			expr = clause.Gt{Column: column, Value: v}
+		case OpGte:
+			expr = clause.Gte{Column: column, Value: v}
+		}
+	}
+
+	f.Conditions = append(f.Conditions, cond)
+	f.exprs = append(f.exprs, expr)
+	return nil
+}
+
+// parseFilterValue mengubah string dari query ke tipe kolom, supaya perbandingan
+// angka dan waktu tidak dilakukan sebagai text.
+func parseFilterValue(field *schema.Field, raw string) (any, error) {
+	var (
+		v   any
+		err error
+	)
+	switch field.DataType {
+	case schema.Bool:
+		v, err = strconv.ParseBool(raw)
+	case schema.Int, "bigint":
+		v, err = strconv.ParseInt(raw, 10, 64)
+	case schema.Uint:
+		v, err = strconv.ParseUint(raw, 10, 64)
+	case schema.Float:
+		v, err = strconv.ParseFloat(raw, 64)
+	case schema.Time:
+		v, err = time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			v, err = time.Parse(time.DateOnly, raw)
+		}
+	default:
+		v = raw
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid value %q for %s", ErrInvalidFilter, raw, field.Name)
+	}
+	return v, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (f *Filter) applyWhere(db *gorm.DB) *gorm.DB {
+	for _, join := range f.joins {
+		db = db.Joins(join)
+	}
+	if len(f.exprs) > 0 {
+		db = db.Where(clause.And(f.exprs...))
+	}
+	return db
+}
+
+// Where hanya memasang kondisi filter, dipakai bersama Page yang punya urutan sendiri.
+func (f *Filter) Where() QueryOption {
+	return f.applyWhere
+}
+
+// Scope memasang kondisi filter sekaligus urutan dari parameter sort, dengan
+// aturan urutan yang sama seperti Page (NULL selalu di akhir).
+func (f *Filter) Scope() QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		db = f.applyWhere(db)
+		if len(f.order) > 0 {
+			db = db.Clauses(orderBy(f.order, false))
+		}
+		return db
+	}
+}
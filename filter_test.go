@@ -0,0 +1,99 @@
+package belajargorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	db := beginTest(t)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"first_name~User&Wallet.balance>=1000000&sort=-created_at,id", []string{"2"}},
+		{"id:in=1,2,3&sort=id", []string{"1", "2", "3"}},
+		{"first_name~yonathan", []string{"1"}},
+		{"middle_name=Rahasia", []string{"6"}},
+		{"Wallet.balance<1000000", []string{"3"}},
+		{"Wallet.balance:null=true&id:in=1,2,3,4&sort=id", []string{"4"}},
+		// user tanpa wallet selalu di akhir, baik asc maupun desc
+		{"id:in=1,2,3,4&sort=Wallet.balance", []string{"3", "1", "2", "4"}},
+		{"id:in=1,2,3,4&sort=-Wallet.balance", []string{"2", "1", "3", "4"}},
+		{"first_name~%25", nil},
+		{"first_name=User+1%26", nil},
+		{"created_at>=2000-01-01&first_name!=Yonathan&last_name:like=&sort=-first_name&limit=5", []string{"9", "8", "7", "6", "5", "4", "3", "2", "14", "13", "12", "11", "10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filter, err := ParseFilter[User](tt.query)
+			assert.Nil(t, err)
+
+			var users []User
+			err = filter.Scope()(db.Model(&User{})).Find(&users).Error
+			assert.Nil(t, err)
+			if tt.expected == nil {
+				assert.Empty(t, users)
+			} else {
+				assert.Equal(t, tt.expected, userIDs(users))
+			}
+		})
+	}
+}
+
+func TestParseFilterWallet(t *testing.T) {
+	store := NewStore(beginTest(t))
+	ctx := context.Background()
+
+	filter, err := ParseFilter[Wallet]("balance>=1000000&currency=IDR&sort=-balance")
+	assert.Nil(t, err)
+	wallets, err := store.Wallets.List(ctx, filter.Scope())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(wallets))
+	assert.Equal(t, IDR(2500000), wallets[0].Balance)
+
+	filter, err = ParseFilter[Wallet]("User.first_name=Yonathan")
+	assert.Nil(t, err)
+	count, err := store.Wallets.Count(ctx, filter.Where())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestParseFilterWithPage(t *testing.T) {
+	store := NewStore(beginTest(t))
+
+	filter, err := ParseFilter[User]("first_name~User 1&sort=-first_name")
+	assert.Nil(t, err)
+	assert.Equal(t, []Condition{{Field: "first_name", Op: OpLike, Value: "User 1"}}, filter.Conditions)
+
+	page, err := store.Users.Page(context.Background(), PageRequest{Limit: 2, Sort: filter.Sort, WithTotal: true}, filter.Where())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"14", "13"}, userIDs(page.Items))
+	assert.Equal(t, int64(5), *page.Total)
+}
+
+func TestParseFilterRejected(t *testing.T) {
+	for _, query := range []string{
+		"password=rahasia",
+		"first_name;DROP TABLE users=1",
+		"sort=password",
+		"sort=first_name;DROP TABLE users",
+		"Addresses.address~Jalan",
+		"Wallet.user_id=1",
+		"Wallet.balance>=banyak",
+		"first_name:regex=.*",
+		"first_name:like>x",
+		"middle_name:null=mungkin",
+		"first_name",
+	} {
+		_, err := ParseFilter[User](query)
+		assert.ErrorIs(t, err, ErrInvalidFilter, query)
+	}
+
+	_, err := ParseFilter[UserLog]("action=create")
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+}
@@ -5,49 +5,23 @@ import (
 	"log"
 	"strconv"
 	"testing"
-	"time"
 
 	faker "github.com/brianvoe/gofakeit/v7"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
-	"gorm.io/gorm/logger"
 )
 
-func OpenConnection() *gorm.DB {
-	dsn := "host=localhost user=postgres password=postgres dbname=belajar_gorm port=5432 sslmode=disable TimeZone=UTC"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	})
-	if err != nil {
-		panic(err)
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		panic(err)
-	}
-
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetConnMaxLifetime(30 * time.Minute)
-	sqlDB.SetConnMaxIdleTime(5 * time.Minute)
-
-	return db
-}
-
-var db = OpenConnection()
-
 func TestOpenConnection(t *testing.T) {
-	assert.NotNil(t, db)
+	assert.NotNil(t, testDB)
 }
 
 func TestRawSQL(t *testing.T) {
-	err := db.Exec("INSERT INTO public.sample(id, name) values (?, ?)", "1", "Nathan").Error
+	db := beginTest(t)
+	err := db.Exec("INSERT INTO sample(id, name) values (?, ?)", "3", "Budi").Error
 	assert.Nil(t, err)
 
-	err = db.Exec("INSERT INTO public.sample(id, name) values (?, ?)", "2", "Jono").Error
+	err = db.Exec("INSERT INTO sample(id, name) values (?, ?)", "4", "Siti").Error
 	assert.Nil(t, err)
 }
 
@@ -57,6 +31,7 @@ type Sample struct {
 }
 
 func TestQuerySQL(t *testing.T) {
+	db := beginTest(t)
 	var sample Sample
 	err := db.Raw("SELECT id, name FROM sample WHERE id = ?", "1").Scan(&sample).Error
 
@@ -69,6 +44,7 @@ func TestQuerySQL(t *testing.T) {
 }
 
 func TestSQLRow(t *testing.T) {
+	db := beginTest(t)
 	rows, err := db.Raw("SELECT id, name FROM sample").Rows()
 	assert.Nil(t, err)
 	defer rows.Close()
@@ -88,6 +64,7 @@ func TestSQLRow(t *testing.T) {
 }
 
 func TestScanRows(t *testing.T) {
+	db := beginTest(t)
 	rows, err := db.Raw("SELECT id, name FROM sample").Rows()
 	assert.Nil(t, err)
 	defer rows.Close()
@@ -100,6 +77,7 @@ func TestScanRows(t *testing.T) {
 }
 
 func TestInsertData(t *testing.T) {
+	db := beginTest(t)
 	user := User{
 		ID:       faker.UUID(),
 		Password: "password",
@@ -117,8 +95,9 @@ func TestInsertData(t *testing.T) {
 }
 
 func TestBatchInsertData(t *testing.T) {
+	db := beginTest(t)
 	var users []User
-	for i := 2; i < 11; i++ {
+	for i := 15; i < 24; i++ {
 		user := User{
 			ID:       strconv.Itoa(i),
 			Password: "password",
@@ -135,12 +114,13 @@ func TestBatchInsertData(t *testing.T) {
 }
 
 func TestTransactionSuccess(t *testing.T) {
+	db := beginTest(t)
 	err := db.Transaction(func(tx *gorm.DB) error {
 		err := tx.Create(&User{
-			ID:       "11",
+			ID:       "15",
 			Password: "password",
 			Name: Name{
-				FirstName: "User 11",
+				FirstName: "User 15",
 			},
 		}).Error
 		if err != nil {
@@ -148,10 +128,10 @@ func TestTransactionSuccess(t *testing.T) {
 		}
 
 		err = tx.Create(&User{
-			ID:       "12",
+			ID:       "16",
 			Password: "password",
 			Name: Name{
-				FirstName: "User 12",
+				FirstName: "User 16",
 			},
 		}).Error
 		if err != nil {
@@ -164,12 +144,13 @@ func TestTransactionSuccess(t *testing.T) {
 }
 
 func TestTransactionError(t *testing.T) {
+	db := beginTest(t)
 	err := db.Transaction(func(tx *gorm.DB) error {
 		err := tx.Create(&User{
-			ID:       "13",
+			ID:       "15",
 			Password: "password",
 			Name: Name{
-				FirstName: "User 13",
+				FirstName: "User 15",
 			},
 		}).Error
 		if err != nil {
@@ -193,35 +174,46 @@ func TestTransactionError(t *testing.T) {
 }
 
 func TestManualTransactionSuccess(t *testing.T) {
-	tx := db.Begin()
-	defer tx.Rollback()
+	db := beginTest(t)
+
+	/**
+	*	Tiap test sudah berjalan di dalam transaksi (lihat beginTest), jadi transaksi
+	*	manual di sini memakai savepoint: SavePoint = begin, RollbackTo = rollback
+	 */
+	tx := db.SavePoint("manual")
+	assert.Nil(t, tx.Error)
 
 	err := tx.Create(&User{
-		ID:       "13",
+		ID:       "15",
 		Password: "password",
 		Name: Name{
-			FirstName: "User 13",
+			FirstName: "User 15",
 		},
 	}).Error
 	assert.Nil(t, err)
 
 	err = tx.Create(&User{
-		ID:       "14",
+		ID:       "16",
 		Password: "password",
 		Name: Name{
-			FirstName: "User 14",
+			FirstName: "User 16",
 		},
 	}).Error
 	assert.Nil(t, err)
 
-	if err == nil {
-		tx.Commit()
+	if err != nil {
+		tx.RollbackTo("manual")
 	}
+
+	var count int64
+	db.Model(&User{}).Where("id IN ?", []string{"15", "16"}).Count(&count)
+	assert.Equal(t, int64(2), count)
 }
 
 func TestManualTransactionFailed(t *testing.T) {
-	tx := db.Begin()
-	defer tx.Rollback()
+	db := beginTest(t)
+	tx := db.SavePoint("manual")
+	assert.Nil(t, tx.Error)
 
 	err := tx.Create(&User{
 		ID:       "15",
@@ -241,14 +233,19 @@ func TestManualTransactionFailed(t *testing.T) {
 	}).Error
 	assert.NotNil(t, err)
 
-	if err == nil {
-		tx.Commit()
+	if err != nil {
+		tx.RollbackTo("manual")
 	}
+
+	var count int64
+	db.Model(&User{}).Where("id = ?", "15").Count(&count)
+	assert.Equal(t, int64(0), count)
 }
 
 func TestSingleData(t *testing.T) {
+	db := beginTest(t)
 	user := User{}
-	err := db.First(&user, "password = ?", "rahasia").Error
+	err := db.First(&user, "middle_name = ?", "Rahasia").Error
 	assert.Nil(t, err)
 	assert.Equal(t, "6", user.ID)
 
@@ -258,12 +255,13 @@ func TestSingleData(t *testing.T) {
 	assert.Equal(t, "9", user.ID)
 
 	user = User{}
-	err = db.Take(&user, "password = ?", "rahasia").Error
+	err = db.Take(&user, "middle_name = ?", "Rahasia").Error
 	assert.Nil(t, err)
 	assert.Equal(t, "6", user.ID)
 }
 
 func TestQueryAll(t *testing.T) {
+	db := beginTest(t)
 	var users []User
 	err := db.Find(&users, "id in ?", []string{"1", "3", "5"}).Error
 	assert.Nil(t, err)
@@ -271,33 +269,37 @@ func TestQueryAll(t *testing.T) {
 }
 
 func TestQueryCondition(t *testing.T) {
+	db := beginTest(t)
 	var users []User
 	res := db.Where("first_name LIKE ?", "%User%").
-		Where("password = ?", "password").
+		Where("middle_name = ?", "").
 		Find(&users)
 	assert.Nil(t, res.Error)
-	assert.Equal(t, 12, res.RowsAffected)
+	assert.Equal(t, int64(12), res.RowsAffected)
 }
 
 func TestOrOperator(t *testing.T) {
+	db := beginTest(t)
 	var users []User
 	res := db.Where("first_name LIKE ?", "%User%").
-		Or("password = ?", "password").
+		Or("middle_name = ?", "").
 		Find(&users)
 	assert.Nil(t, res.Error)
 	assert.Equal(t, 14, len(users))
 }
 
 func TestNotOperator(t *testing.T) {
+	db := beginTest(t)
 	var users []User
 	res := db.Not("first_name LIKE ?", "%User%").
-		Where("password = ?", "password").
+		Where("middle_name = ?", "").
 		Find(&users)
 	assert.Nil(t, res.Error)
 	assert.Equal(t, 1, len(users))
 }
 
 func TestSelectOperator(t *testing.T) {
+	db := beginTest(t)
 	var users []User
 	err := db.Select("id", "first_name").Find(&users).Error
 	assert.Nil(t, err)
@@ -311,6 +313,7 @@ func TestSelectOperator(t *testing.T) {
 }
 
 func TestStructCondition(t *testing.T) {
+	db := beginTest(t)
 	userCondition := User{
 		Name: Name{
 			FirstName:  "User 5",
@@ -324,6 +327,7 @@ func TestStructCondition(t *testing.T) {
 }
 
 func TestMapCondition(t *testing.T) {
+	db := beginTest(t)
 	mapCondition := map[string]interface{}{
 		"last_name": "",
 	}
@@ -334,6 +338,7 @@ func TestMapCondition(t *testing.T) {
 }
 
 func TestOrderLimitOffset(t *testing.T) {
+	db := beginTest(t)
 	var users []User
 
 	err := db.Order("id asc, first_name asc").Limit(5).Offset(5).Find(&users).Error
@@ -347,6 +352,7 @@ type UserResponse struct {
 }
 
 func TestQueryNonModel(t *testing.T) {
+	db := beginTest(t)
 	var users []UserResponse
 	err := db.Model(&User{}).Select("id", "first_name", "last_name").Find(&users).Error
 	assert.Nil(t, err)
@@ -354,6 +360,7 @@ func TestQueryNonModel(t *testing.T) {
 }
 
 func TestUpdate(t *testing.T) {
+	db := beginTest(t)
 	var user User
 	err := db.Take(&user, "id = ?", "2").Error
 	assert.Nil(t, err)
@@ -365,9 +372,11 @@ func TestUpdate(t *testing.T) {
 
 	err = db.Save(&user).Error
 	assert.Nil(t, err)
+	assert.True(t, user.VerifyPassword("password1234"))
 }
 
 func TestSelectedColumn(t *testing.T) {
+	db := beginTest(t)
 	err := db.Model(&User{}).Where("id = ?", "3").Updates(map[string]interface{}{
 		"first_name": "Merah",
 		"last_name":  "Putih",
@@ -377,7 +386,7 @@ func TestSelectedColumn(t *testing.T) {
 	err = db.Model(&User{}).Where("id = ?", "4").Update("middle_name", "Ujang").Error
 	assert.Nil(t, err)
 
-	err = db.Where("id = ?", "5").Updates(User{
+	err = db.Where("id = ?", "5").Updates(&User{
 		Name: Name{
 			FirstName: "Maria",
 			LastName:  "Bellen",
@@ -388,6 +397,7 @@ func TestSelectedColumn(t *testing.T) {
 }
 
 func TestAutoIncrement(t *testing.T) {
+	db := beginTest(t)
 	for i := 0; i < 10; i++ {
 		userLog := UserLog{
 			UserID: "1",
@@ -403,6 +413,7 @@ func TestAutoIncrement(t *testing.T) {
 }
 
 func TestSaveorUpdate(t *testing.T) {
+	db := beginTest(t)
 	userLog := UserLog{
 		UserID: "1",
 		Action: "Test Action",
@@ -423,6 +434,7 @@ func TestSaveorUpdate(t *testing.T) {
 }
 
 func TestSaveOrUpdateNonAutoIncrement(t *testing.T) {
+	db := beginTest(t)
 	user := User{
 		ID:       "100",
 		Password: "ahoep;83",
@@ -437,28 +449,34 @@ func TestSaveOrUpdateNonAutoIncrement(t *testing.T) {
 	*	- kalau ada row yang keupdate, maka berhenti di query update
 	*	- kalau tidak ada baru melakukan insert
 	*
-	*	[rows:0] UPDATE "users" SET "password"='ahoep;83',"first_name"='Bina',
+	*	[rows:0] UPDATE "users" SET "password"='$2a$10$3euPcmQFCiblsZeEu5s7p.9OVHgeHWFDk9nhMqZ0m/3pd/lhwZgES',"first_name"='Bina',
 	*	"middle_name"='',"last_name"='',"updated_at"='2024-06-05 13:09:03.771' WHERE "id" = '100'
 	*
 	*	[rows:1] INSERT INTO "users" ("id","password","first_name","middle_name","last_name","created_at","updated_at")
-	*	VALUES ('100','ahoep;83','Bina','','','2024-06-05 13:09:03.772','2024-06-05 13:09:03.771')
+	*	VALUES ('100','$2a$10$3euPcmQFCiblsZeEu5s7p.9OVHgeHWFDk9nhMqZ0m/3pd/lhwZgES','Bina','','','2024-06-05 13:09:03.772','2024-06-05 13:09:03.771')
 	*	ON CONFLICT ("id") DO UPDATE SET "updated_at"='2024-06-05 13:09:03.772',"password"="excluded"."password",
 	*	"first_name"="excluded"."first_name","middle_name"="excluded"."middle_name","last_name"="excluded"."last_name"
 	*
-	*	[rows:1] UPDATE "users" SET "password"='ahoep;83',"first_name"='Bina',"middle_name"='Ujang',
+	*	[rows:1] UPDATE "users" SET "password"='$2a$10$3euPcmQFCiblsZeEu5s7p.9OVHgeHWFDk9nhMqZ0m/3pd/lhwZgES',"first_name"='Bina',"middle_name"='Ujang',
 	*	"last_name"='',"updated_at"='2024-06-05 13:09:03.779' WHERE "id" = '100'
+	*
+	*	Password di-hash di hook BeforeSave, jadi yang tercatat di log hanya hash-nya.
+	*	Save kedua tidak meng-hash ulang karena nilainya sama dengan hash yang terakhir disimpan.
 	 */
 
 	result := db.Save(&user)
 	assert.Nil(t, result.Error)
+	assert.NotEqual(t, "ahoep;83", user.Password)
 
 	user.Name.MiddleName = "Ujang"
 
 	result = db.Save(&user)
 	assert.Nil(t, result.Error)
+	assert.True(t, user.VerifyPassword("ahoep;83"))
 }
 
 func TestConflict(t *testing.T) {
+	db := beginTest(t)
 	user := User{
 		ID: "88",
 		Name: Name{
@@ -473,13 +491,15 @@ func TestConflict(t *testing.T) {
 }
 
 func TestDelete(t *testing.T) {
+	db := beginTest(t)
 	var user User
 
 	res := db.Take(&user, "id = ?", "11")
 	assert.Nil(t, res.Error)
 
-	res = db.Delete(&user)
-	assert.Nil(t, res.Error)
+	summary, err := NewStore(db).Users.Delete(context.Background(), user.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), summary["users"])
 
 	res = db.Delete(&User{}, "id = ?", "99")
 	assert.Nil(t, res.Error)
@@ -489,6 +509,10 @@ func TestDelete(t *testing.T) {
 }
 
 func TestSoftDelete(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	todos := NewRepository[Todo](db)
+
 	todo := Todo{
 		UserID: "1",
 		Task:   "Test Soft Delete",
@@ -499,26 +523,37 @@ func TestSoftDelete(t *testing.T) {
 
 	res = db.Delete(&todo)
 	assert.Nil(t, res.Error)
-	assert.NotNil(t, todo.DeletedAt)
+	assert.NotZero(t, todo.DeletedAt)
 
-	var todos []Todo
+	_, err := todos.Get(ctx, todo.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
 
-	res = db.Or("deleted_at = ?", nil).Find(&todos)
-	assert.Nil(t, res.Error)
-	assert.Equal(t, 0, len(todos))
+	trashed, err := todos.Trash().List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(trashed))
+	assert.Equal(t, todo.ID, trashed[0].ID)
+
+	assert.Nil(t, todos.Restore(ctx, todo.ID))
+	_, err = todos.Get(ctx, todo.ID)
+	assert.Nil(t, err)
+
+	count, err := todos.Trash().Count(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
 }
 
 func TestUnscope(t *testing.T) {
-	var todo TodoGorm
+	db := beginTest(t)
+	var todo Todo
 
-	create := TodoGorm{
+	create := Todo{
 		UserID: "1",
 		Task:   "Test Soft Delete",
 	}
 	res := db.Create(&create)
 	assert.Nil(t, res.Error)
 
-	err := db.Unscoped().First(&todo, "id = ?", "1").Error
+	err := db.Unscoped().First(&todo, "id = ?", create.ID).Error
 	assert.Nil(t, err)
 
 	err = db.Unscoped().Delete(&todo).Error
@@ -530,6 +565,7 @@ func TestUnscope(t *testing.T) {
 }
 
 func TestLock(t *testing.T) {
+	db := beginTest(t)
 	err := db.Transaction(func(tx *gorm.DB) error {
 		var user User
 		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Take(&user, "id = ?", "1").Error
@@ -539,16 +575,17 @@ func TestLock(t *testing.T) {
 
 		user.Name.FirstName = "Nathan"
 
-		return tx.Save(user).Error
+		return tx.Save(&user).Error
 	})
 
 	assert.Nil(t, err)
 }
 
 func TestCreateWallet(t *testing.T) {
+	db := beginTest(t)
 	wallet := Wallet{
 		UserID:  "1",
-		Balance: 1000000,
+		Balance: IDR(1000000),
 	}
 
 	err := db.Create(&wallet).Error
@@ -557,6 +594,7 @@ func TestCreateWallet(t *testing.T) {
 }
 
 func TestEagerLoad(t *testing.T) {
+	db := beginTest(t)
 	var user User
 	err := db.Model(&User{}).Preload("Wallet").Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
@@ -566,15 +604,17 @@ func TestEagerLoad(t *testing.T) {
 }
 
 func TestRetriveJoin(t *testing.T) {
+	db := beginTest(t)
 	var users []User
 	err := db.Model(&User{}).Joins("Wallet").Find(&users).Error
 	assert.Nil(t, err)
 
-	assert.Equal(t, 12, len(users))
+	assert.Equal(t, 14, len(users))
 
 }
 
 func TestAutoUpsert(t *testing.T) {
+	db := beginTest(t)
 	var user = User{
 		ID:       "39",
 		Password: "rahasia",
@@ -584,7 +624,7 @@ func TestAutoUpsert(t *testing.T) {
 		},
 		Wallet: Wallet{
 			UserID:  "39",
-			Balance: 9307390,
+			Balance: IDR(9307390),
 		},
 	}
 
@@ -593,6 +633,7 @@ func TestAutoUpsert(t *testing.T) {
 }
 
 func TestSkipUpsert(t *testing.T) {
+	db := beginTest(t)
 	var user = User{
 		ID:       "40",
 		Password: "rahasia",
@@ -602,7 +643,7 @@ func TestSkipUpsert(t *testing.T) {
 		},
 		Wallet: Wallet{
 			UserID:  "40",
-			Balance: 9307390,
+			Balance: IDR(9307390),
 		},
 	}
 
@@ -611,6 +652,7 @@ func TestSkipUpsert(t *testing.T) {
 }
 
 func TestUserAddress(t *testing.T) {
+	db := beginTest(t)
 	user := User{
 		ID:       "50",
 		Password: "rahasia",
@@ -619,16 +661,16 @@ func TestUserAddress(t *testing.T) {
 		},
 		Wallet: Wallet{
 			UserID:  "50",
-			Balance: 1000000,
+			Balance: IDR(1000000),
 		},
 		Addresses: []Address{
 			{
-				UserID:  "50",
-				Address: "Jalan jalan kemana pun",
+				UserID: "50",
+				Line1:  "Jalan jalan kemana pun",
 			},
 			{
-				UserID:  "50",
-				Address: "Coba tulis",
+				UserID: "50",
+				Line1:  "Coba tulis",
 			},
 		},
 	}
@@ -638,12 +680,14 @@ func TestUserAddress(t *testing.T) {
 }
 
 func TestUserPreload(t *testing.T) {
+	db := beginTest(t)
 	var usersPreload []User
 	err := db.Model(&User{}).Preload("Addresses").Joins("Wallet").Find(&usersPreload).Error
 	assert.Nil(t, err)
 }
 
 func TestBelongsTo(t *testing.T) {
+	db := beginTest(t)
 	var addresses []Address
 	err := db.Model(&Address{}).Preload("User").Find(&addresses).Error
 	assert.Nil(t, err)
@@ -652,28 +696,28 @@ func TestBelongsTo(t *testing.T) {
 var productID = int64(8174854164025333465)
 
 func TestCreateManyToMany(t *testing.T) {
+	db := beginTest(t)
 	product := Product{
-		ID:    productID,
 		Name:  faker.Name(),
-		Price: 100000,
+		Price: IDR(100000),
 	}
 	err := db.Create(&product).Error
 	assert.Nil(t, err)
+	assert.NotEqual(t, int64(0), product.ID)
 
-	err = db.Table("user_like_product").Create(map[string]any{
-		"user_id":    "1",
-		"product_id": productID,
-	}).Error
-	assert.Nil(t, err)
+	likes := NewLikes(db)
+	for _, userID := range []string{"1", "2", "2"} {
+		_, err = likes.Like(context.Background(), userID, product.ID)
+		assert.Nil(t, err)
+	}
 
-	err = db.Table("user_like_product").Create(map[string]any{
-		"user_id":    "2",
-		"product_id": productID,
-	}).Error
+	count, err := likes.Count(context.Background(), product.ID)
 	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
 }
 
 func TestPreloadManyToMany(t *testing.T) {
+	db := beginTest(t)
 	var product Product
 	err := db.Preload("LikedByUsers").First(&product, "id = ?", productID).Error
 	assert.Nil(t, err)
@@ -681,6 +725,7 @@ func TestPreloadManyToMany(t *testing.T) {
 }
 
 func TestPreloadManyToManyProduct(t *testing.T) {
+	db := beginTest(t)
 	var user User
 	err := db.Preload("LikeProducts").First(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
@@ -688,6 +733,7 @@ func TestPreloadManyToManyProduct(t *testing.T) {
 }
 
 func TestAssociationFind(t *testing.T) {
+	db := beginTest(t)
 	var product Product
 	err := db.Take(&product, "id = ?", productID).Error
 	assert.Nil(t, err)
@@ -699,6 +745,7 @@ func TestAssociationFind(t *testing.T) {
 }
 
 func TestAssociationAppend(t *testing.T) {
+	db := beginTest(t)
 	var user User
 	err := db.Take(&user, "id = ?", "3").Error
 	assert.Nil(t, err)
@@ -712,6 +759,7 @@ func TestAssociationAppend(t *testing.T) {
 }
 
 func TestAssociationDelete(t *testing.T) {
+	db := beginTest(t)
 	var user User
 	err := db.Take(&user, "id = ?", "3").Error
 	assert.Nil(t, err)
@@ -725,6 +773,7 @@ func TestAssociationDelete(t *testing.T) {
 }
 
 func TestAssociationClear(t *testing.T) {
+	db := beginTest(t)
 	var product Product
 	err := db.Take(&product, "id = ?", int64(8174854164025333465)).Error
 	assert.Nil(t, err)
@@ -734,12 +783,14 @@ func TestAssociationClear(t *testing.T) {
 }
 
 func TestPreloadCondition(t *testing.T) {
+	db := beginTest(t)
 	var user User
 	err := db.Preload("Wallet", "balance > ?", 1000000).Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
 }
 
 func TestNestedPreload(t *testing.T) {
+	db := beginTest(t)
 	var wallet Wallet
 	err := db.Preload("User.Addresses").Take(&wallet, "id = ?", 1).Error
 	assert.Nil(t, err)
@@ -750,12 +801,14 @@ func TestNestedPreload(t *testing.T) {
 }
 
 func TestPreloadAll(t *testing.T) {
+	db := beginTest(t)
 	var user User
 	err := db.Preload(clause.Associations).Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
 }
 
 func TestJoinQuery(t *testing.T) {
+	db := beginTest(t)
 	var users []User
 	err := db.Joins("JOIN wallets ON wallets.user_id = users.id").Find(&users).Error
 	assert.Nil(t, err)
@@ -770,6 +823,7 @@ func TestJoinQuery(t *testing.T) {
 }
 
 func TestJoinWithCondition(t *testing.T) {
+	db := beginTest(t)
 	// var users []User
 	// err := db.Joins("JOIN wallets ON wallets.user_id = users.id AND wallets.balance > ?", 1000000).Find(&users).Error
 	// assert.Nil(t, err)
@@ -786,6 +840,7 @@ func TestJoinWithCondition(t *testing.T) {
 }
 
 func TestCount(t *testing.T) {
+	db := beginTest(t)
 	var count int64
 	err := db.Model(&User{}).Joins("Wallet").Where("\"Wallet\".balance > ?", 500000).Count(&count).Error
 	assert.Nil(t, err)
@@ -800,15 +855,18 @@ type AggregationResult struct {
 }
 
 func TestAggregation(t *testing.T) {
+	db := beginTest(t)
 	var result AggregationResult
 	err := db.Model(&Wallet{}).Select("sum(balance) AS total_balance", "min(balance) AS min_balance", "max(balance) AS max_balance",
 		"avg(balance) AS avg_balance").Take(&result).Error
 
 	assert.Nil(t, err)
+	assert.Equal(t, int64(3500000), result.TotalBalance)
 	log.Println(result)
 }
 
 func TestGroupByHaving(t *testing.T) {
+	db := beginTest(t)
 	var result []AggregationResult
 	err := db.Model(&Wallet{}).Select("sum(balance) AS total_balance", "min(balance) AS min_balance", "max(balance) AS max_balance",
 		"avg(balance) AS avg_balance").
@@ -822,6 +880,7 @@ func TestGroupByHaving(t *testing.T) {
 }
 
 func TestContext(t *testing.T) {
+	db := beginTest(t)
 	ctx := context.Background()
 
 	var users []User
@@ -839,6 +898,7 @@ func SultanWallet(db *gorm.DB) *gorm.DB {
 }
 
 func TestScope(t *testing.T) {
+	db := beginTest(t)
 	var wallets []Wallet
 
 	err := db.Scopes(BrokeWallet).Find(&wallets).Error
@@ -846,11 +906,13 @@ func TestScope(t *testing.T) {
 }
 
 func TestMigrator(t *testing.T) {
+	db := beginTest(t)
 	err := db.Migrator().AutoMigrate(&GuestBook{})
 	assert.Nil(t, err)
 }
 
 func TestHook(t *testing.T) {
+	db := beginTest(t)
 	user := &User{
 		Password: "873hi73y",
 		Name: Name{
@@ -1,10 +1,295 @@
 package belajargorm
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
+	"gorm.io/gorm"
+)
+
+const (
+	GuestBookPending  = "pending"
+	GuestBookApproved = "approved"
+	GuestBookRejected = "rejected"
+	GuestBookSpam     = "spam"
+)
+
+var ErrInvalidModerationStatus = errors.New("belajargorm: invalid guest book moderation status")
+
+/**
+*	GuestBook baru selalu masuk sebagai pending (atau spam kalau terkena
+*	SpamFilter) dan baru tampil ke publik setelah approved. Balasan memakai
+*	ParentID ke entry lain, Replies hanya diisi oleh ListPublic dan Thread.
+ */
 type GuestBook struct {
-	Name    string `gorm:"column:name"`
-	Email   string `gorm:"column:email"`
-	Message string `gorm:"column:message"`
+	Name           string      `gorm:"column:name" validate:"required,max=100"`
+	Email          string      `gorm:"column:email" validate:"required,max=254,email"`
+	Message        string      `gorm:"column:message" validate:"required,max=2000"`
+	ParentID       *uint       `gorm:"column:parent_id"`
+	Status         string      `gorm:"column:status" validate:"oneof=pending approved rejected spam"`
+	ModerationNote string      `gorm:"column:moderation_note" validate:"max=500"`
+	ModeratedBy    *string     `gorm:"column:moderated_by"`
+	ModeratedAt    *time.Time  `gorm:"column:moderated_at"`
+	SpamScore      int         `gorm:"column:spam_score"`
+	SpamReasons    string      `gorm:"column:spam_reasons"`
+	Replies        []GuestBook `gorm:"foreignKey:parent_id;references:id"`
 	gorm.Model
 }
+
+func (g *GuestBook) FilterFields() FilterFields {
+	return FilterFields{
+		Filter: []string{"id", "name", "email", "message", "status", "parent_id", "spam_score", "created_at"},
+		Sort:   []string{"id", "name", "spam_score", "created_at"},
+	}
+}
+
+func (g *GuestBook) BeforeSave(db *gorm.DB) error {
+	return validateModel(db, g)
+}
+
+func (g *GuestBook) BeforeCreate(db *gorm.DB) error {
+	if g.Status == "" {
+		g.Status = GuestBookPending
+	}
+	return nil
+}
+
+func validModerationStatus(status string) bool {
+	switch status {
+	case GuestBookPending, GuestBookApproved, GuestBookRejected, GuestBookSpam:
+		return true
+	}
+	return false
+}
+
+// SpamSignal adalah hasil satu SpamRule. Score 0 berarti tidak ada yang mencurigakan.
+type SpamSignal struct {
+	Score  int
+	Reason string
+}
+
+// SpamRule memeriksa entry sebelum disimpan. db dipakai rule yang perlu
+// membandingkan dengan entry lain, misalnya RepeatedMessages.
+type SpamRule interface {
+	Check(ctx context.Context, db *gorm.DB, entry *GuestBook) (SpamSignal, error)
+}
+
+type SpamRuleFunc func(ctx context.Context, db *gorm.DB, entry *GuestBook) (SpamSignal, error)
+
+func (f SpamRuleFunc) Check(ctx context.Context, db *gorm.DB, entry *GuestBook) (SpamSignal, error) {
+	return f(ctx, db, entry)
+}
+
+// SpamFilter menjumlahkan score semua Rules, entry dengan total score
+// minimal Threshold ditandai spam.
+type SpamFilter struct {
+	Rules     []SpamRule
+	Threshold int
+}
+
+// DefaultSpamFilter dipakai GuestBookRepository yang SpamFilter-nya nil.
+var DefaultSpamFilter = &SpamFilter{
+	Threshold: 3,
+	Rules: []SpamRule{
+		LinkLimit(2),
+		BannedWords("casino", "viagra", "judi online", "slot gacor"),
+		RepeatedMessages(2, 24*time.Hour),
+	},
+}
+
+// Evaluate mengembalikan total score dan alasan dari rule yang memberi score.
+func (f *SpamFilter) Evaluate(ctx context.Context, db *gorm.DB, entry *GuestBook) (int, []string, error) {
+	var (
+		score   int
+		reasons []string
+	)
+	for _, rule := range f.Rules {
+		signal, err := rule.Check(ctx, db, entry)
+		if err != nil {
+			return 0, nil, err
+		}
+		if signal.Score > 0 {
+			score += signal.Score
+			reasons = append(reasons, signal.Reason)
+		}
+	}
+	return score, reasons, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(https?://|www\.)`)
+
+// LinkLimit memberi score 1 untuk setiap link di atas max.
+func LinkLimit(max int) SpamRule {
+	return SpamRuleFunc(func(ctx context.Context, db *gorm.DB, entry *GuestBook) (SpamSignal, error) {
+		links := len(linkPattern.FindAllStringIndex(entry.Name+" "+entry.Message, -1))
+		if links <= max {
+			return SpamSignal{}, nil
+		}
+		return SpamSignal{Score: links - max, Reason: fmt.Sprintf("%d links", links)}, nil
+	})
+}
+
+// BannedWords memberi score 3 untuk setiap kata terlarang di nama atau pesan.
+func BannedWords(words ...string) SpamRule {
+	return SpamRuleFunc(func(ctx context.Context, db *gorm.DB, entry *GuestBook) (SpamSignal, error) {
+		text := strings.ToLower(entry.Name + " " + entry.Message)
+		var found []string
+		for _, word := range words {
+			if strings.Contains(text, strings.ToLower(word)) {
+				found = append(found, word)
+			}
+		}
+		if len(found) == 0 {
+			return SpamSignal{}, nil
+		}
+		return SpamSignal{Score: 3 * len(found), Reason: "banned words: " + strings.Join(found, ", ")}, nil
+	})
+}
+
+// RepeatedMessages memberi score 3 kalau email yang sama sudah mengirim pesan
+// yang sama sebanyak max kali dalam window terakhir.
+func RepeatedMessages(max int, window time.Duration) SpamRule {
+	return SpamRuleFunc(func(ctx context.Context, db *gorm.DB, entry *GuestBook) (SpamSignal, error) {
+		var count int64
+		err := db.WithContext(ctx).Unscoped().Model(&GuestBook{}).
+			Where("email = ? AND message = ? AND created_at >= ?", entry.Email, entry.Message, time.Now().Add(-window)).
+			Where("id <> ?", entry.ID).
+			Count(&count).Error
+		if err != nil || count < int64(max) {
+			return SpamSignal{}, err
+		}
+		return SpamSignal{Score: 3, Reason: fmt.Sprintf("same message sent %d times", count+1)}, nil
+	})
+}
+
+/**
+*	Submit menyimpan entry baru atau hasil edit lewat moderasi: status
+*	kembali ke pending (atau spam menurut SpamFilter) dan catatan
+*	moderator sebelumnya dihapus. Balasan hanya boleh ke entry yang approved.
+ */
+func (r *GuestBookRepository) Submit(ctx context.Context, entry *GuestBook) error {
+	db := r.db.WithContext(ctx)
+	if entry.ParentID != nil {
+		if _, err := r.Get(ctx, *entry.ParentID, Select("id"), Where("status = ?", GuestBookApproved)); err != nil {
+			return err
+		}
+	}
+
+	filter := r.SpamFilter
+	if filter == nil {
+		filter = DefaultSpamFilter
+	}
+	score, reasons, err := filter.Evaluate(ctx, db, entry)
+	if err != nil {
+		return err
+	}
+	entry.Status = GuestBookPending
+	if score >= filter.Threshold {
+		entry.Status = GuestBookSpam
+	}
+	entry.SpamScore, entry.SpamReasons = score, strings.Join(reasons, "; ")
+	entry.ModerationNote, entry.ModeratedBy, entry.ModeratedAt = "", nil, nil
+
+	if entry.ID == 0 {
+		return db.Create(entry).Error
+	}
+	return db.Omit("Replies").Save(entry).Error
+}
+
+// Moderate mengubah status banyak entry sekaligus dan mencatat moderator
+// (actor dari context) serta catatannya. Mengembalikan jumlah entry yang berubah.
+func (r *GuestBookRepository) Moderate(ctx context.Context, ids []uint, status, note string) (int64, error) {
+	if !validModerationStatus(status) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidModerationStatus, status)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	res := r.db.WithContext(ctx).Model(&GuestBook{}).Where("id IN ?", ids).Updates(map[string]any{
+		"status":          status,
+		"moderation_note": note,
+		"moderated_by":    ActorFromContext(ctx),
+		"moderated_at":    now,
+	})
+	return res.RowsAffected, res.Error
+}
+
+func (r *GuestBookRepository) Approve(ctx context.Context, ids []uint, note string) (int64, error) {
+	return r.Moderate(ctx, ids, GuestBookApproved, note)
+}
+
+func (r *GuestBookRepository) Reject(ctx context.Context, ids []uint, note string) (int64, error) {
+	return r.Moderate(ctx, ids, GuestBookRejected, note)
+}
+
+// approvedReplies berisi id semua balasan approved di bawah entry parentIDs.
+// Balasan dari entry yang tidak approved ikut tersembunyi.
+const approvedReplies = `WITH RECURSIVE thread (id) AS (
+	SELECT id FROM guest_books WHERE parent_id IN ? AND status = 'approved' AND deleted_at IS NULL
+	UNION ALL
+	SELECT g.id FROM guest_books g JOIN thread t ON g.parent_id = t.id
+	WHERE g.status = 'approved' AND g.deleted_at IS NULL
+) SELECT id FROM thread`
+
+// withReplies mengisi Replies setiap entry dengan balasan approved bertingkat,
+// yang paling lama lebih dulu.
+func (r *GuestBookRepository) withReplies(ctx context.Context, entries []GuestBook) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+
+	replies, err := r.List(ctx, Where("id IN ("+approvedReplies+")", ids), OrderBy("created_at, id"))
+	if err != nil {
+		return err
+	}
+	children := map[uint][]GuestBook{}
+	for _, reply := range replies {
+		children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+	}
+
+	var attach func(nodes []GuestBook)
+	attach = func(nodes []GuestBook) {
+		for i := range nodes {
+			nodes[i].Replies = children[nodes[i].ID]
+			attach(nodes[i].Replies)
+		}
+	}
+	attach(entries)
+	return nil
+}
+
+// ListPublic mengembalikan entry approved paling atas (bukan balasan) beserta
+// balasan approved-nya, untuk ditampilkan ke publik.
+func (r *GuestBookRepository) ListPublic(ctx context.Context, req PageRequest) (*Page[GuestBook], error) {
+	page, err := r.Page(ctx, req, Where("status = ?", GuestBookApproved), Where("parent_id IS NULL"))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.withReplies(ctx, page.Items); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Thread mengembalikan satu entry approved beserta balasan approved-nya.
+func (r *GuestBookRepository) Thread(ctx context.Context, id uint) (*GuestBook, error) {
+	entry, err := r.Get(ctx, id, Where("status = ?", GuestBookApproved))
+	if err != nil {
+		return nil, err
+	}
+	entries := []GuestBook{*entry}
+	if err := r.withReplies(ctx, entries); err != nil {
+		return nil, err
+	}
+	return &entries[0], nil
+}
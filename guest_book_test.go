@@ -0,0 +1,136 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGuestBookModeration(t *testing.T) {
+	db := beginTest(t)
+	ctx := WithActor(context.Background(), "moderator")
+	store := NewStore(db)
+
+	first := GuestBook{Name: "Tamu", Email: "tamu@example.com", Message: "Halo"}
+	second := GuestBook{Name: "Tamu Lain", Email: "lain@example.com", Message: "Salam"}
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &first))
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &second))
+	assert.Equal(t, GuestBookPending, first.Status)
+
+	page, err := store.GuestBooks.ListPublic(ctx, PageRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(page.Items))
+
+	// balasan hanya boleh untuk entry yang sudah approved
+	err = store.GuestBooks.Submit(ctx, &GuestBook{Name: "A", Email: "a@example.com", Message: "B", ParentID: &first.ID})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	updated, err := store.GuestBooks.Approve(ctx, []uint{first.ID, second.ID}, "ok")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), updated)
+
+	loaded, err := store.GuestBooks.Get(ctx, first.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, GuestBookApproved, loaded.Status)
+	assert.Equal(t, "moderator", *loaded.ModeratedBy)
+	assert.Equal(t, "ok", loaded.ModerationNote)
+	assert.NotNil(t, loaded.ModeratedAt)
+
+	reply := GuestBook{Name: "Admin", Email: "admin@example.com", Message: "Terima kasih", ParentID: &first.ID}
+	hidden := GuestBook{Name: "Anon", Email: "anon@example.com", Message: "Belum dicek", ParentID: &first.ID}
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &reply))
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &hidden))
+	_, err = store.GuestBooks.Approve(ctx, []uint{reply.ID}, "")
+	assert.Nil(t, err)
+	nested := GuestBook{Name: "Tamu", Email: "tamu@example.com", Message: "Sama-sama", ParentID: &reply.ID}
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &nested))
+	_, err = store.GuestBooks.Approve(ctx, []uint{nested.ID}, "")
+	assert.Nil(t, err)
+
+	_, err = store.GuestBooks.Reject(ctx, []uint{second.ID}, "off topic")
+	assert.Nil(t, err)
+
+	page, err = store.GuestBooks.ListPublic(ctx, PageRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(page.Items))
+	assert.Equal(t, first.ID, page.Items[0].ID)
+	assert.Equal(t, 1, len(page.Items[0].Replies))
+	assert.Equal(t, "Terima kasih", page.Items[0].Replies[0].Message)
+	assert.Equal(t, "Sama-sama", page.Items[0].Replies[0].Replies[0].Message)
+
+	thread, err := store.GuestBooks.Thread(ctx, reply.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(thread.Replies))
+	_, err = store.GuestBooks.Thread(ctx, hidden.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// edit mengembalikan entry ke antrean moderasi
+	loaded.Message = "Halo semua"
+	assert.Nil(t, store.GuestBooks.Submit(ctx, loaded))
+	loaded, err = store.GuestBooks.Get(ctx, first.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, GuestBookPending, loaded.Status)
+	assert.Nil(t, loaded.ModeratedBy)
+
+	_, err = store.GuestBooks.Moderate(ctx, []uint{first.ID}, "hidden", "")
+	assert.ErrorIs(t, err, ErrInvalidModerationStatus)
+}
+
+func TestGuestBookSpamFilter(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	links := GuestBook{Name: "Promo", Email: "promo@example.com", Message: strings.Repeat("lihat https://example.com ", 5)}
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &links))
+	assert.Equal(t, GuestBookSpam, links.Status)
+	assert.Equal(t, 3, links.SpamScore)
+	assert.Equal(t, "5 links", links.SpamReasons)
+
+	banned := GuestBook{Name: "Bonus", Email: "bonus@example.com", Message: "Main SLOT GACOR di sini"}
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &banned))
+	assert.Equal(t, GuestBookSpam, banned.Status)
+
+	// satu link lebih dari batas belum cukup untuk dianggap spam
+	few := GuestBook{Name: "Blog", Email: "blog@example.com", Message: "www.a.com www.b.com www.c.com"}
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &few))
+	assert.Equal(t, GuestBookPending, few.Status)
+	assert.Equal(t, 1, few.SpamScore)
+
+	for i, status := range []string{GuestBookPending, GuestBookPending, GuestBookSpam} {
+		entry := GuestBook{Name: "Ulang", Email: "ulang@example.com", Message: "Halo lagi"}
+		assert.Nil(t, store.GuestBooks.Submit(ctx, &entry))
+		assert.Equal(t, status, entry.Status, "entry %d", i)
+	}
+
+	// rule sendiri lewat SpamFilter di repository
+	guestBooks := &GuestBookRepository{
+		Repository: store.GuestBooks.Repository,
+		SpamFilter: &SpamFilter{Threshold: 1, Rules: []SpamRule{
+			SpamRuleFunc(func(ctx context.Context, db *gorm.DB, entry *GuestBook) (SpamSignal, error) {
+				if !strings.HasSuffix(entry.Email, "@example.org") {
+					return SpamSignal{}, nil
+				}
+				return SpamSignal{Score: 1, Reason: "blocked domain"}, nil
+			}),
+		}},
+	}
+	blocked := GuestBook{Name: "Org", Email: "x@example.org", Message: "Halo"}
+	assert.Nil(t, guestBooks.Submit(ctx, &blocked))
+	assert.Equal(t, GuestBookSpam, blocked.Status)
+	assert.Equal(t, "blocked domain", blocked.SpamReasons)
+
+	failing := &GuestBookRepository{
+		Repository: store.GuestBooks.Repository,
+		SpamFilter: &SpamFilter{Threshold: 1, Rules: []SpamRule{
+			SpamRuleFunc(func(ctx context.Context, db *gorm.DB, entry *GuestBook) (SpamSignal, error) {
+				return SpamSignal{}, errors.New("spam service unavailable")
+			}),
+		}},
+	}
+	assert.NotNil(t, failing.Submit(ctx, &GuestBook{Name: "X", Email: "x@example.com", Message: "Y"}))
+}
@@ -0,0 +1,1633 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type nameJSON struct {
+	FirstName  string `json:"first_name"`
+	MiddleName string `json:"middle_name"`
+	LastName   string `json:"last_name"`
+}
+
+type userJSON struct {
+	ID        string    `json:"id"`
+	Name      nameJSON  `json:"name"`
+	FullName  string    `json:"full_name"`
+	Version   Version   `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newUserJSON(u *User) userJSON {
+	return userJSON{
+		ID: u.ID,
+		Name: nameJSON{
+			FirstName:  u.Name.FirstName,
+			MiddleName: u.Name.MiddleName,
+			LastName:   u.Name.LastName,
+		},
+		FullName:  u.Name.FullName(),
+		Version:   u.Version,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+type addressJSON struct {
+	ID         int64     `json:"id"`
+	UserID     string    `json:"user_id"`
+	Label      string    `json:"label"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2"`
+	City       string    `json:"city"`
+	Region     string    `json:"region"`
+	PostalCode string    `json:"postal_code"`
+	Country    string    `json:"country"`
+	IsDefault  bool      `json:"is_default"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newAddressJSON(a *Address) addressJSON {
+	return addressJSON{
+		ID:         a.ID,
+		UserID:     a.UserID,
+		Label:      a.Label,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		IsDefault:  a.IsDefault,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
+	}
+}
+
+type walletJSON struct {
+	ID        uint      `json:"id"`
+	UserID    string    `json:"user_id"`
+	Balance   Money     `json:"balance"`
+	Version   Version   `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newWalletJSON(w *Wallet) walletJSON {
+	return walletJSON{ID: w.ID, UserID: w.UserID, Balance: w.Balance, Version: w.Version, CreatedAt: w.CreatedAt, UpdatedAt: w.UpdatedAt}
+}
+
+// productJSON mengirim id sebagai string karena snowflake id melebihi presisi number di JavaScript.
+type productJSON struct {
+	ID         int64     `json:"id,string"`
+	Name       string    `json:"name"`
+	Price      Money     `json:"price"`
+	CategoryID *int64    `json:"category_id"`
+	Stock      int64     `json:"stock"`
+	Reserved   int64     `json:"reserved"`
+	Available  int64     `json:"available"`
+	Version    Version   `json:"version"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newProductJSON(p *Product) productJSON {
+	return productJSON{
+		ID:         p.ID,
+		Name:       p.Name,
+		Price:      p.Price,
+		CategoryID: p.CategoryID,
+		Stock:      p.Stock,
+		Reserved:   p.Reserved,
+		Available:  p.Available(),
+		Version:    p.Version,
+		CreatedAt:  p.CreatedAt,
+		UpdatedAt:  p.UpdatedAt,
+	}
+}
+
+type productPriceJSON struct {
+	Price       Money     `json:"price"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+func newProductPriceJSON(p *ProductPrice) productPriceJSON {
+	return productPriceJSON{Price: p.Price, EffectiveAt: p.EffectiveAt}
+}
+
+type categoryJSON struct {
+	ID        int64          `json:"id"`
+	ParentID  *int64         `json:"parent_id"`
+	Name      string         `json:"name"`
+	Children  []categoryJSON `json:"children"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func newCategoryJSON(c *Category) categoryJSON {
+	return categoryJSON{
+		ID:        c.ID,
+		ParentID:  c.ParentID,
+		Name:      c.Name,
+		Children:  mapSlice(c.Children, newCategoryJSON),
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+type orderItemJSON struct {
+	ProductID   int64  `json:"product_id,string"`
+	ProductName string `json:"product_name"`
+	UnitPrice   Money  `json:"unit_price"`
+	Quantity    int64  `json:"quantity"`
+	Subtotal    Money  `json:"subtotal"`
+}
+
+func newOrderItemJSON(i *OrderItem) orderItemJSON {
+	return orderItemJSON{ProductID: i.ProductID, ProductName: i.ProductName, UnitPrice: i.UnitPrice, Quantity: i.Quantity, Subtotal: i.Subtotal}
+}
+
+type orderJSON struct {
+	ID              int64           `json:"id"`
+	UserID          string          `json:"user_id"`
+	Status          string          `json:"status"`
+	Total           Money           `json:"total"`
+	AddressID       *int64          `json:"address_id"`
+	ShippingAddress string          `json:"shipping_address"`
+	Items           []orderItemJSON `json:"items"`
+	PaidAt          *time.Time      `json:"paid_at"`
+	CancelledAt     *time.Time      `json:"cancelled_at"`
+	RefundedAt      *time.Time      `json:"refunded_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+func newOrderJSON(o *Order) orderJSON {
+	return orderJSON{
+		ID:              o.ID,
+		UserID:          o.UserID,
+		Status:          o.Status,
+		Total:           o.Total,
+		AddressID:       o.AddressID,
+		ShippingAddress: o.ShippingAddress,
+		Items:           mapSlice(o.Items, newOrderItemJSON),
+		PaidAt:          o.PaidAt,
+		CancelledAt:     o.CancelledAt,
+		RefundedAt:      o.RefundedAt,
+		CreatedAt:       o.CreatedAt,
+		UpdatedAt:       o.UpdatedAt,
+	}
+}
+
+type todoJSON struct {
+	ID          int        `json:"id"`
+	UserID      string     `json:"user_id"`
+	Task        string     `json:"task"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	Overdue     bool       `json:"overdue"`
+	Tags        []string   `json:"tags"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func newTodoJSON(t *Todo) todoJSON {
+	tags := make([]string, len(t.Tags))
+	for i, tag := range t.Tags {
+		tags[i] = tag.Name
+	}
+	return todoJSON{
+		ID:          t.ID,
+		UserID:      t.UserID,
+		Task:        t.Task,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		DueAt:       t.DueAt,
+		CompletedAt: t.CompletedAt,
+		Overdue:     t.IsOverdue(time.Now()),
+		Tags:        tags,
+		CreatedAt:   time.Unix(0, t.CreatedAt).UTC(),
+		UpdatedAt:   time.Unix(0, t.UpdatedAt).UTC(),
+	}
+}
+
+type guestBookJSON struct {
+	ID             uint       `json:"id"`
+	ParentID       *uint      `json:"parent_id"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	Message        string     `json:"message"`
+	Status         string     `json:"status"`
+	ModerationNote string     `json:"moderation_note"`
+	ModeratedBy    *string    `json:"moderated_by"`
+	ModeratedAt    *time.Time `json:"moderated_at"`
+	SpamScore      int        `json:"spam_score"`
+	SpamReasons    string     `json:"spam_reasons"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func newGuestBookJSON(g *GuestBook) guestBookJSON {
+	return guestBookJSON{
+		ID:             g.ID,
+		ParentID:       g.ParentID,
+		Name:           g.Name,
+		Email:          g.Email,
+		Message:        g.Message,
+		Status:         g.Status,
+		ModerationNote: g.ModerationNote,
+		ModeratedBy:    g.ModeratedBy,
+		ModeratedAt:    g.ModeratedAt,
+		SpamScore:      g.SpamScore,
+		SpamReasons:    g.SpamReasons,
+		CreatedAt:      g.CreatedAt,
+		UpdatedAt:      g.UpdatedAt,
+	}
+}
+
+// publicGuestBookJSON tidak memuat email dan data moderasi.
+type publicGuestBookJSON struct {
+	ID        uint                  `json:"id"`
+	Name      string                `json:"name"`
+	Message   string                `json:"message"`
+	CreatedAt time.Time             `json:"created_at"`
+	Replies   []publicGuestBookJSON `json:"replies"`
+}
+
+func newPublicGuestBookJSON(g *GuestBook) publicGuestBookJSON {
+	return publicGuestBookJSON{
+		ID:        g.ID,
+		Name:      g.Name,
+		Message:   g.Message,
+		CreatedAt: g.CreatedAt,
+		Replies:   mapSlice(g.Replies, newPublicGuestBookJSON),
+	}
+}
+
+// jsonFields mengganti nama column di *ValidationError dari model dengan nama
+// field di body request kalau berbeda, misalnya first_name menjadi name.first_name.
+// Rule-nya tetap dari tag `validate` model, handler hanya memeriksa field yang
+// wajib dikirim dan bentuk JSON-nya.
+func jsonFields(err error, names map[string]string) error {
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+	renamed := &ValidationError{}
+	for field, message := range ve.Fields {
+		if name, ok := names[field]; ok {
+			field = name
+		}
+		renamed.Add(field, message)
+	}
+	return renamed
+}
+
+// requireCategory memastikan category_id yang dikirim memang ada.
+func (s *Server) requireCategory(ctx context.Context, ve *ValidationError, field string, categoryID *int64) error {
+	if categoryID == nil {
+		return nil
+	}
+	exists, err := s.store.Categories.Exists(ctx, Where("id = ?", *categoryID))
+	if err == nil && !exists {
+		ve.Add(field, "does not exist")
+	}
+	return err
+}
+
+// requireUser memastikan user_id yang dikirim memang ada.
+func (s *Server) requireUser(ctx context.Context, ve *ValidationError, field, userID string) error {
+	if userID == "" {
+		return nil
+	}
+	exists, err := s.store.Users.Exists(ctx, Where("id = ?", userID))
+	if err == nil && !exists {
+		ve.Add(field, "does not exist")
+	}
+	return err
+}
+
+// userRequest: version boleh dikirim saat update supaya perubahan ditolak
+// (409) kalau user sudah diubah orang lain sejak dibaca.
+type userRequest struct {
+	ID       string   `json:"id"`
+	Password *string  `json:"password"`
+	Name     nameJSON `json:"name"`
+	Version  *Version `json:"version"`
+}
+
+var userJSONFields = map[string]string{
+	"first_name":  "name.first_name",
+	"middle_name": "name.middle_name",
+	"last_name":   "name.last_name",
+}
+
+// validate memeriksa password (tidak ada di tag karena model hanya melihat
+// hash-nya) lalu rule model, supaya semua error dilaporkan sekaligus.
+func (req *userRequest) validate(create bool) error {
+	ve := &ValidationError{}
+	if create && req.Password == nil {
+		ve.Add("password", "is required")
+	} else if !create && req.ID != "" {
+		ve.Add("id", "cannot be changed")
+	}
+	if req.Password != nil && utf8.RuneCountInString(*req.Password) < 8 {
+		ve.Add("password", "must be at least 8 characters")
+	} else if req.Password != nil && isPasswordHash(*req.Password) {
+		ve.Add("password", "must be plain text, not a hash")
+	}
+
+	user := User{ID: req.ID}
+	req.apply(&user)
+	if err := validateStruct(ve, &user); err != nil {
+		return err
+	}
+	return jsonFields(ve.Err(), userJSONFields)
+}
+
+func (req *userRequest) apply(u *User) {
+	u.Name = Name{FirstName: req.Name.FirstName, MiddleName: req.Name.MiddleName, LastName: req.Name.LastName}
+	if req.Password != nil {
+		u.Password = *req.Password
+	}
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	listResource(s, w, r, s.store.Users.Repository, newUserJSON)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var req userRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := req.validate(true); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	user := User{ID: req.ID}
+	req.apply(&user)
+	if err := s.store.Users.Create(r.Context(), &user); err != nil {
+		s.fail(w, r, jsonFields(err, userJSONFields))
+		return
+	}
+
+	w.Header().Set("Location", "/users/"+user.ID)
+	writeJSON(w, http.StatusCreated, newUserJSON(&user))
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	user, err := s.store.Users.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newUserJSON(user))
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request) {
+	var req userRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := req.validate(false); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	user, err := s.store.Users.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	req.apply(user)
+	if req.Version != nil {
+		user.Version = *req.Version
+	}
+	if err := s.store.Users.Update(r.Context(), user); err != nil {
+		s.fail(w, r, jsonFields(err, userJSONFields))
+		return
+	}
+	writeJSON(w, http.StatusOK, newUserJSON(user))
+}
+
+// deleteUser membalas jumlah baris per table yang ikut terhapus, lihat DefaultUserCascade.
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	summary, err := s.store.Users.Delete(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]CascadeSummary{"deleted": summary})
+}
+
+func (s *Server) searchUsers(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		s.fail(w, r, badRequest("q is required"))
+		return
+	}
+	limit, err := queryInt(r, "limit", DefaultSearchLimit, MaxPageSize)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	users, err := s.store.Users.SearchUsersByName(r.Context(), query, limit)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapSlice(users, newUserJSON))
+}
+
+func (s *Server) listUserAddresses(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	if _, err := s.store.Users.Get(r.Context(), userID, Select("id")); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	addresses, err := s.store.Addresses.ListAddressesForUser(r.Context(), userID)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapSlice(addresses, newAddressJSON))
+}
+
+func (s *Server) listUserLikes(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	if _, err := s.store.Users.Get(r.Context(), userID, Select("id")); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	products, err := s.store.Products.ListLikedByUser(r.Context(), userID)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapSlice(products, newProductJSON))
+}
+
+func (s *Server) likeProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := pathInt64(r, "product_id")
+	if err == nil {
+		_, err = s.store.Likes.Like(r.Context(), r.PathValue("id"), productID)
+	}
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) unlikeProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := pathInt64(r, "product_id")
+	if err == nil {
+		_, err = s.store.Likes.Unlike(r.Context(), r.PathValue("id"), productID)
+	}
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addressRequest: label kosong berarti home dan country kosong berarti
+// DefaultCountry. is_default yang tidak dikirim saat update tidak mengubah default.
+type addressRequest struct {
+	UserID     string `json:"user_id"`
+	Label      string `json:"label"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	IsDefault  *bool  `json:"is_default"`
+}
+
+func (s *Server) validateAddress(ctx context.Context, req *addressRequest) error {
+	req.Label = strings.TrimSpace(req.Label)
+	if req.Label == "" {
+		req.Label = AddressHome
+	}
+	req.Country = strings.ToUpper(strings.TrimSpace(req.Country))
+	if req.Country == "" {
+		req.Country = DefaultCountry
+	}
+	req.PostalCode = strings.ToUpper(strings.TrimSpace(req.PostalCode))
+
+	ve := &ValidationError{}
+	var address Address
+	req.apply(&address)
+	if err := validateStruct(ve, &address); err != nil {
+		return err
+	}
+	if err := s.requireUser(ctx, ve, "user_id", req.UserID); err != nil {
+		return err
+	}
+	return ve.Err()
+}
+
+func (req *addressRequest) apply(a *Address) {
+	a.UserID, a.Label = req.UserID, req.Label
+	a.Line1, a.Line2 = req.Line1, req.Line2
+	a.City, a.Region = req.City, req.Region
+	a.PostalCode, a.Country = req.PostalCode, req.Country
+	if req.IsDefault != nil {
+		a.IsDefault = *req.IsDefault
+	}
+}
+
+func (s *Server) listAddresses(w http.ResponseWriter, r *http.Request) {
+	listResource(s, w, r, s.store.Addresses.Repository, newAddressJSON)
+}
+
+func (s *Server) createAddress(w http.ResponseWriter, r *http.Request) {
+	var req addressRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.validateAddress(r.Context(), &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	var address Address
+	req.apply(&address)
+	if err := s.store.Addresses.Create(r.Context(), &address); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/addresses/"+strconv.FormatInt(address.ID, 10))
+	writeJSON(w, http.StatusCreated, newAddressJSON(&address))
+}
+
+func (s *Server) getAddress(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	address, err := s.store.Addresses.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAddressJSON(address))
+}
+
+func (s *Server) updateAddress(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	var req addressRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.validateAddress(r.Context(), &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	address, err := s.store.Addresses.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	req.apply(address)
+	if err := s.store.Addresses.Update(r.Context(), address); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAddressJSON(address))
+}
+
+func (s *Server) setDefaultAddress(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	address, err := s.store.Addresses.SetDefault(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newAddressJSON(address))
+}
+
+func (s *Server) deleteAddress(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err == nil {
+		err = s.store.Addresses.Delete(r.Context(), id)
+	}
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type walletRequest struct {
+	UserID  string `json:"user_id"`
+	Balance *Money `json:"balance"`
+}
+
+func (s *Server) listWallets(w http.ResponseWriter, r *http.Request) {
+	listResource(s, w, r, s.store.Wallets.Repository, newWalletJSON)
+}
+
+func (s *Server) createWallet(w http.ResponseWriter, r *http.Request) {
+	var req walletRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	ve := &ValidationError{}
+	if req.Balance == nil {
+		req.Balance = &Money{Currency: DefaultCurrency}
+	}
+	wallet := Wallet{UserID: req.UserID, Balance: *req.Balance}
+	if err := validateStruct(ve, &wallet); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.requireUser(r.Context(), ve, "user_id", req.UserID); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := ve.Err(); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	if err := s.store.Wallets.Create(r.Context(), &wallet); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/wallets/"+strconv.FormatUint(uint64(wallet.ID), 10))
+	writeJSON(w, http.StatusCreated, newWalletJSON(&wallet))
+}
+
+func (s *Server) getWallet(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	wallet, err := s.store.Wallets.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newWalletJSON(wallet))
+}
+
+func (s *Server) deleteWallet(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err == nil {
+		err = s.store.Wallets.Delete(r.Context(), id)
+	}
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// productRequest: stock hanya dipakai saat create, setelah itu lewat restock.
+// version seperti di userRequest.
+type productRequest struct {
+	Name       string   `json:"name"`
+	Price      *Money   `json:"price"`
+	CategoryID *int64   `json:"category_id"`
+	Stock      int64    `json:"stock"`
+	Version    *Version `json:"version"`
+}
+
+func (s *Server) validateProduct(ctx context.Context, req *productRequest) error {
+	ve := &ValidationError{}
+	product := Product{Name: req.Name, Stock: req.Stock}
+	if req.Price == nil {
+		ve.Add("price", "is required")
+	} else {
+		product.Price = *req.Price
+	}
+	if err := validateStruct(ve, &product); err != nil {
+		return err
+	}
+	if err := s.requireCategory(ctx, ve, "category_id", req.CategoryID); err != nil {
+		return err
+	}
+	return ve.Err()
+}
+
+func (s *Server) listProducts(w http.ResponseWriter, r *http.Request) {
+	listResource(s, w, r, s.store.Products.Repository, newProductJSON)
+}
+
+func (s *Server) createProduct(w http.ResponseWriter, r *http.Request) {
+	var req productRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.validateProduct(r.Context(), &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	product := Product{Name: req.Name, Price: *req.Price, CategoryID: req.CategoryID, Stock: req.Stock}
+	if err := s.store.Products.Create(r.Context(), &product); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/products/"+strconv.FormatInt(product.ID, 10))
+	writeJSON(w, http.StatusCreated, newProductJSON(&product))
+}
+
+func (s *Server) getProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	product, err := s.store.Products.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newProductJSON(product))
+}
+
+func (s *Server) updateProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	var req productRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.validateProduct(r.Context(), &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if req.Stock != 0 {
+		s.fail(w, r, badRequest("stock can only be changed with POST /products/%d/restock", id))
+		return
+	}
+
+	product, err := s.store.Products.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	product.Name, product.Price, product.CategoryID = req.Name, *req.Price, req.CategoryID
+	if req.Version != nil {
+		product.Version = *req.Version
+	}
+	if err := s.store.Products.Update(r.Context(), product); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newProductJSON(product))
+}
+
+type restockRequest struct {
+	Quantity int64 `json:"quantity"`
+}
+
+func (s *Server) restockProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	var req restockRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if req.Quantity <= 0 {
+		ve := &ValidationError{}
+		ve.Add("quantity", "must be greater than zero")
+		s.fail(w, r, ve.Err())
+		return
+	}
+
+	if err := s.store.Products.Restock(r.Context(), id, req.Quantity); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	product, err := s.store.Products.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newProductJSON(product))
+}
+
+// productPrice mengembalikan harga yang berlaku pada ?at=<RFC3339>, default sekarang.
+func (s *Server) productPrice(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	at := time.Now()
+	if v := r.URL.Query().Get("at"); v != "" {
+		if at, err = time.Parse(time.RFC3339, v); err != nil {
+			s.fail(w, r, badRequest("at must be an RFC 3339 timestamp"))
+			return
+		}
+	}
+
+	price, err := s.store.Products.PriceAt(r.Context(), id, at)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newProductPriceJSON(price))
+}
+
+func (s *Server) productPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	prices, err := s.store.Products.PriceHistory(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapSlice(prices, newProductPriceJSON))
+}
+
+func (s *Server) deleteProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err == nil {
+		err = s.store.Products.Delete(r.Context(), id)
+	}
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type productLikesJSON struct {
+	Product productJSON `json:"product"`
+	Likes   int64       `json:"likes"`
+}
+
+func newProductLikesJSON(p *ProductLikes) productLikesJSON {
+	return productLikesJSON{Product: newProductJSON(&p.Product), Likes: p.Likes}
+}
+
+// queryInt membaca parameter angka dari query string, def dipakai kalau kosong.
+func queryInt(r *http.Request, name string, def, max int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 || n > max {
+		return 0, badRequest("%s must be a number between 1 and %d", name, max)
+	}
+	return n, nil
+}
+
+func (s *Server) productLikes(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if _, err := s.store.Products.Get(r.Context(), id, Select("id")); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	users, err := s.store.Likes.Likers(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"likes": len(users),
+		"users": mapSlice(users, newUserJSON),
+	})
+}
+
+func (s *Server) mostLikedProducts(w http.ResponseWriter, r *http.Request) {
+	days, err := queryInt(r, "days", 7, 365)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	limit, err := queryInt(r, "limit", DefaultPageSize, MaxPageSize)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	products, err := s.store.Likes.MostLiked(r.Context(), days, limit)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapSlice(products, newProductLikesJSON))
+}
+
+func (s *Server) alsoLikedProducts(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	limit, err := queryInt(r, "limit", DefaultPageSize, MaxPageSize)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if _, err := s.store.Products.Get(r.Context(), id, Select("id")); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	products, err := s.store.Likes.AlsoLiked(r.Context(), id, limit)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapSlice(products, newProductLikesJSON))
+}
+
+type categoryRequest struct {
+	Name     string `json:"name"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+func (s *Server) validateCategory(ctx context.Context, req *categoryRequest) error {
+	ve := &ValidationError{}
+	if err := validateStruct(ve, &Category{Name: req.Name}); err != nil {
+		return err
+	}
+	if err := s.requireCategory(ctx, ve, "parent_id", req.ParentID); err != nil {
+		return err
+	}
+	return ve.Err()
+}
+
+func (s *Server) listCategories(w http.ResponseWriter, r *http.Request) {
+	tree, err := s.store.Categories.Tree(r.Context())
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapSlice(tree, newCategoryJSON))
+}
+
+func (s *Server) createCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.validateCategory(r.Context(), &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	category := Category{Name: req.Name, ParentID: req.ParentID}
+	if err := s.store.Categories.Create(r.Context(), &category); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/categories/"+strconv.FormatInt(category.ID, 10))
+	writeJSON(w, http.StatusCreated, newCategoryJSON(&category))
+}
+
+// getCategory mengembalikan category beserta semua turunannya di children.
+func (s *Server) getCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	category, err := s.store.Categories.Subtree(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newCategoryJSON(category))
+}
+
+// updateCategory mengganti nama dan parent, parent_id kosong menjadikannya root.
+func (s *Server) updateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	var req categoryRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.validateCategory(r.Context(), &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	err = s.store.Transaction(r.Context(), func(tx *Store) error {
+		if err := tx.Categories.Move(r.Context(), id, req.ParentID); err != nil {
+			return err
+		}
+		return tx.Categories.UpdateFields(r.Context(), id, map[string]any{"name": req.Name})
+	})
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	s.getCategory(w, r)
+}
+
+func (s *Server) listCategoryProducts(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	recursive := true
+	if v := r.URL.Query().Get("recursive"); v != "" {
+		if recursive, err = strconv.ParseBool(v); err != nil {
+			s.fail(w, r, badRequest("recursive must be true or false"))
+			return
+		}
+	}
+
+	products, err := s.store.Products.ListInCategory(r.Context(), id, recursive)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapSlice(products, newProductJSON))
+}
+
+type orderItemRequest struct {
+	ProductID int64 `json:"product_id,string"`
+	Quantity  int64 `json:"quantity"`
+}
+
+type orderRequest struct {
+	UserID    string             `json:"user_id"`
+	AddressID int64              `json:"address_id"`
+	Items     []orderItemRequest `json:"items"`
+}
+
+func (req *orderRequest) validate() error {
+	ve := &ValidationError{}
+	if req.UserID == "" {
+		ve.Add("user_id", "is required")
+	}
+	if req.AddressID <= 0 {
+		ve.Add("address_id", "is required")
+	}
+	if len(req.Items) == 0 {
+		ve.Add("items", "must contain at least one item")
+	}
+	return ve.Err()
+}
+
+func (s *Server) listOrders(w http.ResponseWriter, r *http.Request) {
+	listResource(s, w, r, s.store.Orders.Repository, newOrderJSON, Preload("Items"))
+}
+
+// createOrder menjalankan checkout, order yang berhasil sudah dibayar dari wallet user.
+func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
+	var req orderRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := req.validate(); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	items := make([]CheckoutItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = CheckoutItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	order, err := s.store.Orders.Checkout(r.Context(), req.UserID, items, req.AddressID)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/orders/"+strconv.FormatInt(order.ID, 10))
+	writeJSON(w, http.StatusCreated, newOrderJSON(order))
+}
+
+func (s *Server) getOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	order, err := s.store.Orders.Get(r.Context(), id, Preload("Items"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newOrderJSON(order))
+}
+
+func (s *Server) cancelOrder(w http.ResponseWriter, r *http.Request) {
+	s.settleOrder(w, r, s.store.Orders.Cancel)
+}
+
+func (s *Server) refundOrder(w http.ResponseWriter, r *http.Request) {
+	s.settleOrder(w, r, s.store.Orders.Refund)
+}
+
+func (s *Server) settleOrder(w http.ResponseWriter, r *http.Request, settle func(context.Context, int64) (*Order, error)) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	order, err := settle(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newOrderJSON(order))
+}
+
+// todoRequest: status, priority dan tags boleh kosong, artinya status open dan
+// priority normal saat create, atau tidak berubah saat update.
+type todoRequest struct {
+	UserID   string     `json:"user_id"`
+	Task     string     `json:"task"`
+	Status   string     `json:"status"`
+	Priority int        `json:"priority"`
+	DueAt    *time.Time `json:"due_at"`
+	Tags     *[]string  `json:"tags"`
+}
+
+func (s *Server) validateTodo(ctx context.Context, req *todoRequest) error {
+	ve := &ValidationError{}
+	columns := []string{"user_id", "task"}
+	if req.Status != "" {
+		columns = append(columns, "status")
+	}
+	if req.Priority != 0 {
+		columns = append(columns, "priority")
+	}
+	todo := Todo{UserID: req.UserID, Task: req.Task, Status: req.Status, Priority: req.Priority}
+	if err := validateStruct(ve, &todo, columns...); err != nil {
+		return err
+	}
+	if err := s.requireUser(ctx, ve, "user_id", req.UserID); err != nil {
+		return err
+	}
+	return ve.Err()
+}
+
+// saveTodo menyimpan todo beserta tag-nya dalam satu transaksi lalu membaca
+// ulang supaya tag yang dikirim balik sudah dinormalisasi.
+func (s *Server) saveTodo(ctx context.Context, todo *Todo, tags *[]string, create bool) (*Todo, error) {
+	var saved *Todo
+	err := s.store.Transaction(ctx, func(tx *Store) error {
+		var err error
+		if create {
+			err = tx.Todos.Create(ctx, todo)
+		} else {
+			err = tx.Todos.Update(ctx, todo)
+		}
+		if err != nil {
+			return err
+		}
+		if tags != nil {
+			// rule Tag.Name dilaporkan sebagai field "tags" di body request
+			if err := tx.Todos.SetTags(ctx, todo.ID, *tags); err != nil {
+				return jsonFields(err, map[string]string{"name": "tags"})
+			}
+		}
+		saved, err = tx.Todos.Get(ctx, todo.ID, Preload("Tags"))
+		return err
+	})
+	return saved, err
+}
+
+// todoScopes membaca parameter overdue, due_within dan tag lalu membuangnya
+// dari query string supaya tidak dibaca sebagai filter kolom.
+func todoScopes(r *http.Request) ([]QueryOption, error) {
+	query := r.URL.Query()
+	now := time.Now()
+
+	var opts []QueryOption
+	if v := query.Get("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: overdue must be true or false", ErrInvalidFilter)
+		}
+		if overdue {
+			opts = append(opts, Overdue(now))
+		}
+	}
+	if v := query.Get("due_within"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: due_within must be a positive duration like 24h", ErrInvalidFilter)
+		}
+		opts = append(opts, DueWithin(now, d))
+	}
+	if v := query.Get("tag"); v != "" {
+		opts = append(opts, WithTag(v))
+	}
+
+	terms := strings.Split(r.URL.RawQuery, "&")
+	kept := terms[:0]
+	for _, term := range terms {
+		key, _, _ := strings.Cut(term, "=")
+		if key != "overdue" && key != "due_within" && key != "tag" {
+			kept = append(kept, term)
+		}
+	}
+	r.URL.RawQuery = strings.Join(kept, "&")
+	return opts, nil
+}
+
+func (s *Server) listTodos(w http.ResponseWriter, r *http.Request) {
+	opts, err := todoScopes(r)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	listResource(s, w, r, s.store.Todos.Repository, newTodoJSON, append(opts, Preload("Tags"))...)
+}
+
+func (s *Server) createTodo(w http.ResponseWriter, r *http.Request) {
+	var req todoRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.validateTodo(r.Context(), &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	todo := &Todo{UserID: req.UserID, Task: req.Task, Status: req.Status, Priority: req.Priority, DueAt: req.DueAt}
+	todo, err := s.saveTodo(r.Context(), todo, req.Tags, true)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/todos/"+strconv.Itoa(todo.ID))
+	writeJSON(w, http.StatusCreated, newTodoJSON(todo))
+}
+
+func (s *Server) getTodo(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	todo, err := s.store.Todos.Get(r.Context(), id, Preload("Tags"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newTodoJSON(todo))
+}
+
+func (s *Server) updateTodo(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	var req todoRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.validateTodo(r.Context(), &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	todo, err := s.store.Todos.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if req.Status != "" {
+		if err := todo.TransitionTo(req.Status, time.Now()); err != nil {
+			s.fail(w, r, err)
+			return
+		}
+	}
+	if req.Priority != 0 {
+		todo.Priority = req.Priority
+	}
+	todo.UserID, todo.Task, todo.DueAt = req.UserID, req.Task, req.DueAt
+
+	todo, err = s.saveTodo(r.Context(), todo, req.Tags, false)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newTodoJSON(todo))
+}
+
+func (s *Server) restoreTodo(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := s.store.Todos.Restore(r.Context(), int(id)); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	todo, err := s.store.Todos.Get(r.Context(), id, Preload("Tags"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newTodoJSON(todo))
+}
+
+func (s *Server) deleteTodo(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err == nil {
+		err = s.store.Todos.Delete(r.Context(), id)
+	}
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type guestBookRequest struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Message string `json:"message"`
+}
+
+func (s *Server) listGuestBooks(w http.ResponseWriter, r *http.Request) {
+	listResource(s, w, r, s.store.GuestBooks.Repository, newGuestBookJSON)
+}
+
+func (s *Server) createGuestBook(w http.ResponseWriter, r *http.Request) {
+	s.submitGuestBook(w, r, nil)
+}
+
+// createGuestBookReply membuat balasan untuk entry approved {id}.
+func (s *Server) createGuestBookReply(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	parentID := uint(id)
+	s.submitGuestBook(w, r, &parentID)
+}
+
+func (s *Server) submitGuestBook(w http.ResponseWriter, r *http.Request, parentID *uint) {
+	var req guestBookRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	entry := GuestBook{Name: req.Name, Email: req.Email, Message: req.Message, ParentID: parentID}
+	if err := s.store.GuestBooks.Submit(r.Context(), &entry); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/guest-books/"+strconv.FormatUint(uint64(entry.ID), 10))
+	writeJSON(w, http.StatusCreated, newGuestBookJSON(&entry))
+}
+
+func (s *Server) getGuestBook(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	entry, err := s.store.GuestBooks.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newGuestBookJSON(entry))
+}
+
+func (s *Server) updateGuestBook(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	var req guestBookRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	entry, err := s.store.GuestBooks.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	entry.Name, entry.Email, entry.Message = req.Name, req.Email, req.Message
+	if err := s.store.GuestBooks.Submit(r.Context(), entry); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newGuestBookJSON(entry))
+}
+
+func (s *Server) deleteGuestBook(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err == nil {
+		err = s.store.GuestBooks.Delete(r.Context(), id)
+	}
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listPublicGuestBooks(w http.ResponseWriter, r *http.Request) {
+	req, _, err := pageRequest[GuestBook](r)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	page, err := s.store.GuestBooks.ListPublic(r.Context(), req)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mapPage(page, newPublicGuestBookJSON))
+}
+
+func (s *Server) getPublicGuestBook(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	entry, err := s.store.GuestBooks.Thread(r.Context(), uint(id))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newPublicGuestBookJSON(entry))
+}
+
+// moderationRequest: IDs hanya dipakai moderasi massal.
+type moderationRequest struct {
+	IDs    []uint `json:"ids"`
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+var moderationJSONFields = map[string]string{"moderation_note": "note"}
+
+func (req *moderationRequest) validate(bulk bool) error {
+	ve := &ValidationError{}
+	if bulk && len(req.IDs) == 0 {
+		ve.Add("ids", "is required")
+	}
+	entry := GuestBook{Status: req.Status, ModerationNote: req.Note}
+	if err := validateStruct(ve, &entry, "status", "moderation_note"); err != nil {
+		return err
+	}
+	return jsonFields(ve.Err(), moderationJSONFields)
+}
+
+func (s *Server) moderateGuestBook(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt64(r, "id")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	var req moderationRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := req.validate(false); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	updated, err := s.store.GuestBooks.Moderate(r.Context(), []uint{uint(id)}, req.Status, req.Note)
+	if err == nil && updated == 0 {
+		err = &NotFoundError{Model: "GuestBook", Key: id}
+	}
+	if err != nil {
+		s.fail(w, r, jsonFields(err, moderationJSONFields))
+		return
+	}
+
+	entry, err := s.store.GuestBooks.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newGuestBookJSON(entry))
+}
+
+func (s *Server) moderateGuestBooks(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if err := req.validate(true); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	updated, err := s.store.GuestBooks.Moderate(r.Context(), req.IDs, req.Status, req.Note)
+	if err != nil {
+		s.fail(w, r, jsonFields(err, moderationJSONFields))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"updated": updated})
+}
+
+// search: GET /search?q=kopi&types=products,todos&limit=10&offset=0&total=true
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		s.fail(w, r, badRequest("q is required"))
+		return
+	}
+	req, err := pageParams(r)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	var types []string
+	if v := r.URL.Query().Get("types"); v != "" {
+		types = strings.Split(v, ",")
+	}
+	results, err := s.store.Search(r.Context(), query, types, req)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,267 @@
+package belajargorm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// testDB berisi schema + baseFixtures yang sudah di-commit. Test tidak boleh
+// memakai testDB langsung, tapi lewat beginTest supaya perubahannya di-rollback.
+var testDB *gorm.DB
+
+func TestMain(m *testing.M) {
+	// cost minimum supaya hashing fixture dan test tidak lambat
+	PasswordCost = bcrypt.MinCost
+
+	var err error
+	testDB, err = openTestDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := resetSchema(testDB); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := loadFixtures(testDB, baseFixtures()); err != nil {
+		log.Fatal(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+// openTestDB memakai sqlite in-memory, kecuali TEST_DB_DRIVER di-set sehingga
+// config dibaca dari environment TEST_DB_* (misal TEST_DB_NAME untuk postgres
+// lokal). Env DB_* milik aplikasi sengaja tidak dipakai karena resetSchema
+// menghapus semua table, dan database yang namanya tidak berakhiran _test ditolak.
+func openTestDB() (*gorm.DB, error) {
+	if os.Getenv("TEST_DB_DRIVER") == "" {
+		cfg := DefaultConfig()
+		cfg.Driver = DriverSQLite
+		cfg.SQLitePath = ":memory:?_pragma=foreign_keys(1)"
+		return Open(cfg)
+	}
+
+	cfg := DefaultConfig()
+	err := cfg.loadEnv(func(key string) (string, bool) {
+		return os.LookupEnv("TEST_" + key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	name := cfg.DBName
+	if cfg.Driver == DriverSQLite {
+		name, _, _ = strings.Cut(filepath.Base(cfg.SQLitePath), "?")
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if name != ":memory:" && !strings.HasSuffix(name, "_test") {
+		return nil, fmt.Errorf("refusing to reset database %q: test database name must end with _test", name)
+	}
+	return Open(cfg)
+}
+
+// openEmptyDB membuat database sqlite kosong di direktori sementara milik test,
+// untuk test yang butuh database sendiri di luar transaksi beginTest. Transaksinya
+// BEGIN IMMEDIATE dengan busy_timeout supaya aman dipakai banyak goroutine.
+func openEmptyDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	cfg := DefaultConfig()
+	cfg.Driver = DriverSQLite
+	cfg.SQLitePath = filepath.Join(t.TempDir(), "test.db") +
+		"?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+
+	db, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return db
+}
+
+func openMigratedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := openEmptyDB(t)
+	migrator, err := NewSchemaMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// beginTest membuka transaksi yang otomatis di-rollback ketika test selesai.
+func beginTest(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	tx := testDB.Begin()
+	if tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+
+	return tx
+}
+
+// resetSchema menghapus semua table lalu menjalankan ulang semua migration dari awal.
+func resetSchema(db *gorm.DB) error {
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if strings.HasPrefix(table, "sqlite_") {
+			continue
+		}
+		if err := db.Migrator().DropTable(table); err != nil {
+			return err
+		}
+	}
+
+	migrator, err := NewSchemaMigrator(db)
+	if err != nil {
+		return err
+	}
+	return migrator.Up(context.Background())
+}
+
+type likeFixture struct {
+	UserID    string
+	ProductID int64
+}
+
+type fixtures struct {
+	Samples   []Sample
+	Users     []User
+	Wallets   []Wallet
+	Addresses []Address
+	Products  []Product
+	Likes     []likeFixture
+}
+
+func loadFixtures(db *gorm.DB, f fixtures) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, s := range f.Samples {
+			if err := tx.Table("sample").Create(&s).Error; err != nil {
+				return fmt.Errorf("sample %s: %w", s.ID, err)
+			}
+		}
+
+		rows := []any{&f.Users, &f.Wallets, &f.Addresses, &f.Products}
+		for _, r := range rows {
+			err := tx.Omit(clause.Associations).Create(r).Error
+			if err != nil && err != gorm.ErrEmptySlice {
+				return err
+			}
+		}
+
+		for _, l := range f.Likes {
+			err := tx.Create(&UserLikeProduct{UserID: l.UserID, ProductID: l.ProductID}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return syncSequences(tx)
+	})
+}
+
+// syncSequences menyamakan sequence postgres dengan id fixture yang diisi manual,
+// kalau tidak insert berikutnya akan bentrok dengan id fixture.
+func syncSequences(db *gorm.DB) error {
+	if db.Dialector.Name() != DriverPostgres {
+		return nil
+	}
+
+	for _, table := range []string{"wallets", "addresses"} {
+		err := db.Exec(fmt.Sprintf(
+			"SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE((SELECT MAX(id) FROM %[1]s), 0) + 1, false)",
+			table,
+		)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+*	Data awal yang dipakai test:
+*	- user "1" Yonathan (bukan "User ..."), password "password"
+*	- user "2".."14" bernama "User N", password "password", kecuali user "6" password "rahasia"
+*	  dan middle name "Rahasia" (password disimpan sebagai hash jadi tidak bisa dipakai di WHERE)
+*	- wallet 1 milik user 1, wallet 2 milik user 2, wallet 3 (saldo 0) milik user 3
+*	- product productID disukai user 1 dan 2
+ */
+func baseFixtures() fixtures {
+	f := fixtures{
+		Samples: []Sample{
+			{ID: "1", Name: "Nathan"},
+			{ID: "2", Name: "Jono"},
+		},
+		Users: []User{
+			{ID: "1", Password: "password", Name: Name{FirstName: "Yonathan", LastName: "Setiadi"}},
+		},
+		Wallets: []Wallet{
+			{Model: gorm.Model{ID: 1}, UserID: "1", Balance: IDR(1000000)},
+			{Model: gorm.Model{ID: 2}, UserID: "2", Balance: IDR(2500000)},
+			{Model: gorm.Model{ID: 3}, UserID: "3", Balance: IDR(0)},
+		},
+		Addresses: []Address{
+			{ID: 1, UserID: "1", Line1: "Jalan Merdeka 1", City: "Jakarta", PostalCode: "10110", IsDefault: true},
+			{ID: 2, UserID: "1", Label: AddressWork, Line1: "Jalan Sudirman 2", City: "Jakarta", PostalCode: "10220"},
+		},
+		Products: []Product{
+			{ID: productID, Name: "Kopi Susu", Price: IDR(25000)},
+		},
+		Likes: []likeFixture{
+			{UserID: "1", ProductID: productID},
+			{UserID: "2", ProductID: productID},
+		},
+	}
+
+	for i := 2; i <= 14; i++ {
+		user := User{
+			ID:       strconv.Itoa(i),
+			Password: "password",
+			Name:     Name{FirstName: "User " + strconv.Itoa(i)},
+		}
+		if i == 6 {
+			user.Password = "rahasia"
+			user.Name.MiddleName = "Rahasia"
+		}
+
+		f.Users = append(f.Users, user)
+	}
+
+	return f
+}
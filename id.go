@@ -0,0 +1,209 @@
+package belajargorm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type IDGenerator interface {
+	NewID() string
+}
+
+// Int64IDGenerator untuk model yang primary key-nya angka, misalnya Product.
+type Int64IDGenerator interface {
+	IDGenerator
+	NewInt64() int64
+}
+
+// Strategi ID per model, bisa diganti saat startup (sebelum ada query).
+var (
+	UserIDGenerator     IDGenerator      = NewUUIDv7Generator()
+	ProductIDGenerator  Int64IDGenerator = NewSnowflakeGenerator(0)
+	TransferIDGenerator IDGenerator      = NewUUIDv7Generator()
+)
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic("belajargorm: crypto/rand failed: " + err.Error())
+	}
+}
+
+// UUIDv7Generator menghasilkan UUID versi 7 (RFC 9562). 12 bit rand_a dipakai
+// sebagai counter supaya ID yang dibuat di milidetik yang sama tetap urut.
+type UUIDv7Generator struct {
+	mu      sync.Mutex
+	lastMs  int64
+	counter uint16
+	now     func() time.Time
+}
+
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{now: time.Now}
+}
+
+func (g *UUIDv7Generator) NewID() string {
+	var b [16]byte
+	randomBytes(b[6:])
+
+	g.mu.Lock()
+	ms := g.now().UnixMilli()
+	if ms <= g.lastMs {
+		ms = g.lastMs
+		g.counter++
+		if g.counter > 0x0fff {
+			// counter habis, pinjam milidetik berikutnya
+			ms++
+			g.counter = 0
+		}
+	} else {
+		g.counter = binary.BigEndian.Uint16(b[6:8]) & 0x07ff
+	}
+	g.lastMs = ms
+	counter := g.counter
+	g.mu.Unlock()
+
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = 0x70 | byte(counter>>8)
+	b[7] = byte(counter)
+	b[8] = 0x80 | (b[8] & 0x3f)
+
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+
+	return string(out[:])
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator menghasilkan ULID monotonic: di milidetik yang sama bagian
+// random-nya dinaikkan satu sehingga urutan string sama dengan urutan pembuatan.
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	lastMs  int64
+	entropy [10]byte
+	now     func() time.Time
+}
+
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{now: time.Now}
+}
+
+func (g *ULIDGenerator) NewID() string {
+	g.mu.Lock()
+	ms := g.now().UnixMilli()
+	if ms <= g.lastMs {
+		ms = g.lastMs
+		if !incrementBytes(g.entropy[:]) {
+			ms++
+			randomBytes(g.entropy[:])
+		}
+	} else {
+		randomBytes(g.entropy[:])
+	}
+	g.lastMs = ms
+
+	var b [16]byte
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	copy(b[6:], g.entropy[:])
+	g.mu.Unlock()
+
+	return encodeCrockford(b)
+}
+
+func incrementBytes(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCrockford mengubah 128 bit menjadi 26 karakter base32 Crockford.
+func encodeCrockford(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[0:8])
+	lo := binary.BigEndian.Uint64(b[8:16])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// SnowflakeEpoch adalah titik nol timestamp snowflake (1 Januari 2024 UTC).
+var SnowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNode      = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+// SnowflakeGenerator menghasilkan int64 dengan susunan 41 bit milidetik sejak
+// SnowflakeEpoch, 10 bit node dan 12 bit sequence. Tiap instance aplikasi
+// harus memakai node yang berbeda.
+type SnowflakeGenerator struct {
+	mu       sync.Mutex
+	node     int64
+	lastMs   int64
+	sequence int64
+	now      func() time.Time
+}
+
+func NewSnowflakeGenerator(node int64) *SnowflakeGenerator {
+	if node < 0 || node > snowflakeMaxNode {
+		panic("belajargorm: snowflake node must be between 0 and 1023")
+	}
+	return &SnowflakeGenerator{node: node, now: time.Now}
+}
+
+func (g *SnowflakeGenerator) NewInt64() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().Sub(SnowflakeEpoch).Milliseconds()
+	if ms <= g.lastMs {
+		// jam mundur atau masih di milidetik yang sama: lanjutkan dari lastMs
+		ms = g.lastMs
+		g.sequence = (g.sequence + 1) & snowflakeMaxSequence
+		if g.sequence == 0 {
+			ms++
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMs = ms
+
+	return ms<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | g.sequence
+}
+
+func (g *SnowflakeGenerator) NewID() string {
+	return strconv.FormatInt(g.NewInt64(), 10)
+}
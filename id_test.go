@@ -0,0 +1,141 @@
+package belajargorm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collectIDs(gen IDGenerator, workers, perWorker int) []string {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	ids := make([]string, 0, workers*perWorker)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make([]string, perWorker)
+			for i := range local {
+				local[i] = gen.NewID()
+			}
+			mu.Lock()
+			ids = append(ids, local...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return ids
+}
+
+func TestIDGeneratorsUniqueConcurrent(t *testing.T) {
+	generators := map[string]IDGenerator{
+		"uuidv7":    NewUUIDv7Generator(),
+		"ulid":      NewULIDGenerator(),
+		"snowflake": NewSnowflakeGenerator(7),
+	}
+
+	for name, gen := range generators {
+		t.Run(name, func(t *testing.T) {
+			ids := collectIDs(gen, 16, 5000)
+
+			seen := make(map[string]struct{}, len(ids))
+			for _, id := range ids {
+				_, dup := seen[id]
+				assert.False(t, dup, id)
+				seen[id] = struct{}{}
+			}
+			assert.Equal(t, 16*5000, len(seen))
+		})
+	}
+}
+
+func TestIDGeneratorsMonotonic(t *testing.T) {
+	frozen := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	uuid := NewUUIDv7Generator()
+	uuid.now = func() time.Time { return frozen }
+	ulid := NewULIDGenerator()
+	ulid.now = func() time.Time { return frozen }
+
+	for _, gen := range []IDGenerator{uuid, ulid} {
+		var ids []string
+		for i := 0; i < 5000; i++ {
+			ids = append(ids, gen.NewID())
+		}
+		assert.True(t, sort.StringsAreSorted(ids))
+	}
+
+	snowflake := NewSnowflakeGenerator(1)
+	snowflake.now = func() time.Time { return frozen }
+	prev := int64(0)
+	for i := 0; i < 10000; i++ {
+		id := snowflake.NewInt64()
+		assert.Greater(t, id, prev)
+		prev = id
+	}
+}
+
+func TestIDFormats(t *testing.T) {
+	uuid := NewUUIDv7Generator().NewID()
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), uuid)
+
+	ulid := NewULIDGenerator().NewID()
+	assert.Regexp(t, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`), ulid)
+
+	snowflake := NewSnowflakeGenerator(3).NewInt64()
+	assert.Equal(t, int64(3), snowflake>>12&0x3ff)
+}
+
+func TestConcurrentCreateInBatchesUsers(t *testing.T) {
+	db := openMigratedDB(t)
+
+	const workers, perWorker = 8, 500
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			users := make([]User, perWorker)
+			for i := range users {
+				users[i].Name.FirstName = fmt.Sprintf("Batch %d-%d", w, i)
+			}
+			errs <- db.CreateInBatches(&users, 100).Error
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+
+	var count, distinct int64
+	db.Model(&User{}).Count(&count)
+	db.Model(&User{}).Distinct("id").Count(&distinct)
+	assert.Equal(t, int64(workers*perWorker), count)
+	assert.Equal(t, count, distinct)
+}
+
+func TestProductIDGenerated(t *testing.T) {
+	db := beginTest(t)
+
+	products := []Product{{Name: "Teh", Price: IDR(5000)}, {Name: "Kopi", Price: IDR(8000)}, {Name: "Susu", Price: IDR(7000)}}
+	err := db.Create(&products).Error
+	assert.Nil(t, err)
+
+	seen := map[int64]bool{}
+	for _, p := range products {
+		assert.NotEqual(t, int64(0), p.ID)
+		assert.False(t, seen[p.ID])
+		seen[p.ID] = true
+	}
+}
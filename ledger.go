@@ -0,0 +1,281 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EntryDebit  = "debit"
+	EntryCredit = "credit"
+)
+
+var (
+	ErrInvalidAmount       = errors.New("belajargorm: amount must be greater than zero")
+	ErrSameWallet          = errors.New("belajargorm: cannot transfer to the same user")
+	ErrInsufficientFunds   = errors.New("belajargorm: insufficient wallet balance")
+	ErrIdempotencyConflict = errors.New("belajargorm: idempotency key reused with different parameters")
+	ErrLedgerMismatch      = errors.New("belajargorm: wallet balance does not match ledger")
+)
+
+// WalletTransaction adalah satu baris ledger. Table-nya append-only, update dan
+// delete ditolak oleh trigger di database.
+type WalletTransaction struct {
+	ID           int64     `gorm:"primary_key;column:id;autoIncrement"`
+	WalletID     uint      `gorm:"column:wallet_id"`
+	TransferID   *string   `gorm:"column:transfer_id"`
+	EntryType    string    `gorm:"column:entry_type"`
+	Amount       int64     `gorm:"column:amount"`
+	BalanceAfter int64     `gorm:"column:balance_after"`
+	Description  string    `gorm:"column:description"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (w *WalletTransaction) TableName() string {
+	return "wallet_transactions"
+}
+
+// WalletTransfer mengelompokkan pasangan debit dan credit dari satu Transfer.
+type WalletTransfer struct {
+	ID             string              `gorm:"primary_key;column:id"`
+	IdempotencyKey string              `gorm:"column:idempotency_key"`
+	FromUserID     string              `gorm:"column:from_user_id"`
+	ToUserID       string              `gorm:"column:to_user_id"`
+	Amount         int64               `gorm:"column:amount"`
+	CreatedAt      time.Time           `gorm:"column:created_at;autoCreateTime"`
+	Entries        []WalletTransaction `gorm:"foreignKey:transfer_id;references:id"`
+}
+
+func (w *WalletTransfer) TableName() string {
+	return "wallet_transfers"
+}
+
+func (w *WalletTransfer) BeforeCreate(db *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = TransferIDGenerator.NewID()
+	}
+	return nil
+}
+
+type Ledger struct {
+	db *gorm.DB
+}
+
+func NewLedger(db *gorm.DB) *Ledger {
+	return &Ledger{db: db}
+}
+
+// Transfer memindahkan saldo (dalam minor unit) antar wallet user dengan currency
+// yang sama. Kedua wallet di-lock dengan urutan user id supaya dua transfer
+// berlawanan arah tidak saling deadlock. Memanggil ulang dengan idempotencyKey
+// yang sama mengembalikan transfer yang sudah ada.
+func (l *Ledger) Transfer(ctx context.Context, fromUserID, toUserID string, amount int64, idempotencyKey string) (*WalletTransfer, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if fromUserID == toUserID {
+		return nil, ErrSameWallet
+	}
+
+	request := WalletTransfer{
+		IdempotencyKey: idempotencyKey,
+		FromUserID:     fromUserID,
+		ToUserID:       toUserID,
+		Amount:         amount,
+	}
+
+	var result *WalletTransfer
+	err := l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		existing, err := findTransfer(tx, idempotencyKey)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			result, err = matchTransfer(existing, request)
+			return err
+		}
+
+		wallets, err := lockWallets(tx, fromUserID, toUserID)
+		if err != nil {
+			return err
+		}
+		from, to := wallets[fromUserID], wallets[toUserID]
+
+		if from.Currency != to.Currency {
+			return fmt.Errorf("%w: wallet %s and %s", ErrCurrencyMismatch, from.Currency, to.Currency)
+		}
+		if from.Balance.Amount < amount {
+			return ErrInsufficientFunds
+		}
+
+		transfer := request
+		if transfer.IdempotencyKey == "" {
+			// tanpa key, tiap transfer dianggap unik
+			transfer.ID = TransferIDGenerator.NewID()
+			transfer.IdempotencyKey = transfer.ID
+		}
+		if err := tx.Omit(clause.Associations).Create(&transfer).Error; err != nil {
+			return err
+		}
+
+		debit, err := postEntry(tx, from, &transfer.ID, EntryDebit, amount, "transfer to "+toUserID)
+		if err != nil {
+			return err
+		}
+		credit, err := postEntry(tx, to, &transfer.ID, EntryCredit, amount, "transfer from "+fromUserID)
+		if err != nil {
+			return err
+		}
+
+		transfer.Entries = []WalletTransaction{debit, credit}
+		result = &transfer
+		return nil
+	})
+
+	if err != nil && idempotencyKey != "" && !isLedgerError(err) {
+		// kemungkinan request lain dengan key yang sama menang duluan (unique index)
+		existing, findErr := findTransfer(l.db.WithContext(ctx), idempotencyKey)
+		if findErr == nil && existing != nil {
+			return matchTransfer(existing, request)
+		}
+	}
+
+	return result, err
+}
+
+// Deposit menambah saldo dari luar sistem (top up), hanya mencatat entry credit.
+func (l *Ledger) Deposit(ctx context.Context, userID string, amount int64, description string) (WalletTransaction, error) {
+	if amount <= 0 {
+		return WalletTransaction{}, ErrInvalidAmount
+	}
+
+	var entry WalletTransaction
+	err := l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallets, err := lockWallets(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		entry, err = postEntry(tx, wallets[userID], nil, EntryCredit, amount, description)
+		return err
+	})
+	return entry, err
+}
+
+func (l *Ledger) Entries(ctx context.Context, walletID uint) ([]WalletTransaction, error) {
+	var entries []WalletTransaction
+	err := l.db.WithContext(ctx).Where("wallet_id = ?", walletID).Order("id").Find(&entries).Error
+	return entries, err
+}
+
+// LedgerBalance menjumlahkan semua credit dikurangi debit milik wallet.
+func (l *Ledger) LedgerBalance(ctx context.Context, walletID uint) (int64, error) {
+	var sum int64
+	err := l.db.WithContext(ctx).Model(&WalletTransaction{}).
+		Select("COALESCE(SUM(CASE WHEN entry_type = ? THEN amount ELSE -amount END), 0)", EntryCredit).
+		Where("wallet_id = ?", walletID).
+		Scan(&sum).Error
+	return sum, err
+}
+
+// Reconcile memastikan kolom balance (proyeksi) sama dengan total ledger.
+func (l *Ledger) Reconcile(ctx context.Context, walletID uint) error {
+	var wallet Wallet
+	if err := l.db.WithContext(ctx).Take(&wallet, "id = ?", walletID).Error; err != nil {
+		return err
+	}
+
+	sum, err := l.LedgerBalance(ctx, walletID)
+	if err != nil {
+		return err
+	}
+	if sum != wallet.Balance.Amount {
+		return fmt.Errorf("%w: wallet %d balance %d, ledger %d", ErrLedgerMismatch, walletID, wallet.Balance.Amount, sum)
+	}
+	return nil
+}
+
+func isLedgerError(err error) bool {
+	for _, target := range []error{ErrInsufficientFunds, ErrIdempotencyConflict, ErrCurrencyMismatch, gorm.ErrRecordNotFound} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func findTransfer(tx *gorm.DB, idempotencyKey string) (*WalletTransfer, error) {
+	if idempotencyKey == "" {
+		return nil, nil
+	}
+
+	var transfer WalletTransfer
+	err := tx.Preload("Entries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Take(&transfer, "idempotency_key = ?", idempotencyKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func matchTransfer(existing *WalletTransfer, request WalletTransfer) (*WalletTransfer, error) {
+	if existing.FromUserID != request.FromUserID || existing.ToUserID != request.ToUserID || existing.Amount != request.Amount {
+		return nil, fmt.Errorf("%w: %s", ErrIdempotencyConflict, request.IdempotencyKey)
+	}
+	return existing, nil
+}
+
+// lockWallets mengambil wallet tiap user dengan SELECT ... FOR UPDATE, selalu
+// berurutan berdasarkan user id.
+func lockWallets(tx *gorm.DB, userIDs ...string) (map[string]*Wallet, error) {
+	sorted := append([]string{}, userIDs...)
+	sort.Strings(sorted)
+
+	wallets := make(map[string]*Wallet, len(sorted))
+	for _, userID := range sorted {
+		var wallet Wallet
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			Order("id").
+			Take(&wallet).Error
+		if err != nil {
+			return nil, fmt.Errorf("wallet of user %s: %w", userID, err)
+		}
+		wallets[userID] = &wallet
+	}
+	return wallets, nil
+}
+
+func postEntry(tx *gorm.DB, wallet *Wallet, transferID *string, entryType string, amount int64, description string) (WalletTransaction, error) {
+	delta := amount
+	if entryType == EntryDebit {
+		delta = -amount
+	}
+
+	wallet.Balance.Amount += delta
+	entry := WalletTransaction{
+		WalletID:     wallet.ID,
+		TransferID:   transferID,
+		EntryType:    entryType,
+		Amount:       amount,
+		BalanceAfter: wallet.Balance.Amount,
+		Description:  description,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return entry, err
+	}
+
+	// lewat Table (tanpa schema Wallet) karena kolom balance di model hanya boleh ditulis saat create
+	err := tx.Table("wallets").Where("id = ?", wallet.ID).UpdateColumn("balance", gorm.Expr("balance + ?", delta)).Error
+	return entry, err
+}
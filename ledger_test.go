@@ -0,0 +1,158 @@
+package belajargorm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func walletOf(t *testing.T, ledger *Ledger, userID string) Wallet {
+	t.Helper()
+
+	var wallet Wallet
+	err := ledger.db.Order("id").Take(&wallet, "user_id = ?", userID).Error
+	assert.Nil(t, err)
+	return wallet
+}
+
+func TestTransfer(t *testing.T) {
+	ledger := NewLedger(beginTest(t))
+	ctx := context.Background()
+
+	transfer, err := ledger.Transfer(ctx, "1", "2", 250000, "trf-1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(transfer.Entries))
+	assert.Equal(t, EntryDebit, transfer.Entries[0].EntryType)
+	assert.Equal(t, EntryCredit, transfer.Entries[1].EntryType)
+
+	from := walletOf(t, ledger, "1")
+	to := walletOf(t, ledger, "2")
+	assert.Equal(t, int64(750000), from.Balance.Amount)
+	assert.Equal(t, int64(2750000), to.Balance.Amount)
+
+	assert.Nil(t, ledger.Reconcile(ctx, from.ID))
+	assert.Nil(t, ledger.Reconcile(ctx, to.ID))
+
+	entries, err := ledger.Entries(ctx, from.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, int64(750000), entries[1].BalanceAfter)
+
+	// tanpa idempotency key, tiap panggilan adalah transfer baru
+	first, err := ledger.Transfer(ctx, "1", "2", 1000, "")
+	assert.Nil(t, err)
+	second, err := ledger.Transfer(ctx, "1", "2", 1000, "")
+	assert.Nil(t, err)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, int64(748000), walletOf(t, ledger, "1").Balance.Amount)
+}
+
+func TestTransferRejected(t *testing.T) {
+	ledger := NewLedger(beginTest(t))
+	ctx := context.Background()
+
+	_, err := ledger.Transfer(ctx, "3", "1", 1, "")
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = ledger.Transfer(ctx, "1", "1", 100, "")
+	assert.ErrorIs(t, err, ErrSameWallet)
+
+	_, err = ledger.Transfer(ctx, "1", "2", 0, "")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = ledger.Transfer(ctx, "1", "tidak-ada", 100, "")
+	assert.NotNil(t, err)
+
+	assert.Equal(t, int64(0), walletOf(t, ledger, "3").Balance.Amount)
+	assert.Equal(t, int64(1000000), walletOf(t, ledger, "1").Balance.Amount)
+}
+
+func TestTransferIdempotent(t *testing.T) {
+	ledger := NewLedger(beginTest(t))
+	ctx := context.Background()
+
+	first, err := ledger.Transfer(ctx, "2", "3", 100000, "checkout-42")
+	assert.Nil(t, err)
+
+	replay, err := ledger.Transfer(ctx, "2", "3", 100000, "checkout-42")
+	assert.Nil(t, err)
+	assert.Equal(t, first.ID, replay.ID)
+	assert.Equal(t, 2, len(replay.Entries))
+
+	assert.Equal(t, int64(2400000), walletOf(t, ledger, "2").Balance.Amount)
+	assert.Equal(t, int64(100000), walletOf(t, ledger, "3").Balance.Amount)
+
+	_, err = ledger.Transfer(ctx, "2", "3", 5, "checkout-42")
+	assert.ErrorIs(t, err, ErrIdempotencyConflict)
+}
+
+func TestWalletBalanceIsLedgerProjection(t *testing.T) {
+	db := beginTest(t)
+	ledger := NewLedger(db)
+	ctx := context.Background()
+
+	wallet := Wallet{UserID: "4", Balance: IDR(5000)}
+	err := db.Create(&wallet).Error
+	assert.Nil(t, err)
+	assert.Nil(t, ledger.Reconcile(ctx, wallet.ID))
+
+	// Balance hanya bisa diisi saat create, Save tidak mengubahnya
+	wallet.Balance = IDR(999999999)
+	err = db.Save(&wallet).Error
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5000), walletOf(t, ledger, "4").Balance.Amount)
+
+	_, err = ledger.Deposit(ctx, "4", 2500, "top up")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7500), walletOf(t, ledger, "4").Balance.Amount)
+	assert.Nil(t, ledger.Reconcile(ctx, wallet.ID))
+}
+
+func TestWalletTransactionsAppendOnly(t *testing.T) {
+	db := beginTest(t)
+
+	tx := db.SavePoint("append_only")
+	err := tx.Model(&WalletTransaction{}).Where("wallet_id = ?", 1).Update("amount", 1).Error
+	assert.NotNil(t, err)
+	tx.RollbackTo("append_only")
+
+	tx = db.SavePoint("append_only")
+	err = tx.Where("wallet_id = ?", 1).Delete(&WalletTransaction{}).Error
+	assert.NotNil(t, err)
+	tx.RollbackTo("append_only")
+}
+
+func TestTransferConcurrent(t *testing.T) {
+	db := openMigratedDB(t)
+	ledger := NewLedger(db)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b"} {
+		err := db.Create(&User{ID: id, Name: Name{FirstName: id}, Wallet: Wallet{Balance: IDR(1000)}}).Error
+		assert.Nil(t, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			from, to := "a", "b"
+			if i%2 == 1 {
+				from, to = to, from
+			}
+			_, err := ledger.Transfer(ctx, from, to, 10, fmt.Sprintf("concurrent-%d", i))
+			assert.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	a, b := walletOf(t, ledger, "a"), walletOf(t, ledger, "b")
+	assert.Equal(t, int64(2000), a.Balance.Amount+b.Balance.Amount)
+	assert.Equal(t, int64(1000), a.Balance.Amount)
+	assert.Nil(t, ledger.Reconcile(ctx, a.ID))
+	assert.Nil(t, ledger.Reconcile(ctx, b.ID))
+}
@@ -0,0 +1,217 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserLikeProduct adalah baris join table user_like_product. Model ini dipasang
+// ke association User.LikeProducts dan Product.LikedByUsers lewat SetupJoinTable,
+// jadi Append dari sisi mana pun ikut mengisi liked_at.
+type UserLikeProduct struct {
+	UserID    string    `gorm:"primary_key;column:user_id"`
+	ProductID int64     `gorm:"primary_key;column:product_id;autoIncrement:false"`
+	LikedAt   time.Time `gorm:"column:liked_at;autoCreateTime"`
+}
+
+func (u *UserLikeProduct) TableName() string {
+	return "user_like_product"
+}
+
+// BeforeCreate mengisi liked_at dalam UTC. SQLite membandingkan waktu sebagai
+// text, jadi semua baris harus memakai zona yang sama dengan parameter query.
+func (u *UserLikeProduct) BeforeCreate(db *gorm.DB) error {
+	if u.LikedAt.IsZero() {
+		u.LikedAt = time.Now()
+	}
+	u.LikedAt = u.LikedAt.UTC()
+	return nil
+}
+
+func setupLikeJoinTable(db *gorm.DB) error {
+	if err := db.SetupJoinTable(&User{}, "LikeProducts", &UserLikeProduct{}); err != nil {
+		return err
+	}
+	return db.SetupJoinTable(&Product{}, "LikedByUsers", &UserLikeProduct{})
+}
+
+// ProductLikes adalah product beserta jumlah like-nya.
+type ProductLikes struct {
+	Product Product
+	Likes   int64
+}
+
+type likeCount struct {
+	ProductID int64
+	Likes     int64
+}
+
+// Likes mengelola like user ke product. Like dan Unlike idempotent: memanggil
+// ulang tidak menambah baris dan tidak mengubah liked_at.
+type Likes struct {
+	db *gorm.DB
+}
+
+func NewLikes(db *gorm.DB) *Likes {
+	return &Likes{db: db}
+}
+
+func (l *Likes) requirePair(ctx context.Context, userID string, productID int64) error {
+	if _, err := NewRepository[User](l.db).Get(ctx, userID, Select("id")); err != nil {
+		return err
+	}
+	_, err := NewRepository[Product](l.db).Get(ctx, productID, Select("id"))
+	return err
+}
+
+// Like mengembalikan true kalau like baru dibuat, false kalau sudah ada.
+func (l *Likes) Like(ctx context.Context, userID string, productID int64) (bool, error) {
+	if err := l.requirePair(ctx, userID, productID); err != nil {
+		return false, err
+	}
+	res := l.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&UserLikeProduct{UserID: userID, ProductID: productID})
+	return res.RowsAffected > 0, res.Error
+}
+
+// Unlike mengembalikan true kalau ada like yang dihapus.
+func (l *Likes) Unlike(ctx context.Context, userID string, productID int64) (bool, error) {
+	if err := l.requirePair(ctx, userID, productID); err != nil {
+		return false, err
+	}
+	res := l.db.WithContext(ctx).
+		Where("user_id = ? AND product_id = ?", userID, productID).
+		Delete(&UserLikeProduct{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (l *Likes) Get(ctx context.Context, userID string, productID int64) (*UserLikeProduct, error) {
+	var like UserLikeProduct
+	err := l.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", userID, productID).Take(&like).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &NotFoundError{Model: "Like", Key: fmt.Sprintf("%s/%d", userID, productID)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &like, nil
+}
+
+// liveLikes adalah query like dari user dan product yang belum dihapus.
+func (l *Likes) liveLikes(ctx context.Context, alias string) *gorm.DB {
+	return l.db.WithContext(ctx).
+		Table("user_like_product AS " + alias).
+		Joins("JOIN users ON users.id = " + alias + ".user_id AND users.deleted_at IS NULL").
+		Joins("JOIN products ON products.id = " + alias + ".product_id AND products.deleted_at IS NULL")
+}
+
+func (l *Likes) Count(ctx context.Context, productID int64) (int64, error) {
+	counts, err := l.Counts(ctx, productID)
+	return counts[productID], err
+}
+
+// Counts menghitung like beberapa product sekaligus, product tanpa like bernilai 0.
+func (l *Likes) Counts(ctx context.Context, productIDs ...int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(productIDs))
+	for _, id := range productIDs {
+		counts[id] = 0
+	}
+	if len(productIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []likeCount
+	err := l.liveLikes(ctx, "ulp").
+		Select("ulp.product_id, COUNT(*) AS likes").
+		Where("ulp.product_id IN ?", productIDs).
+		Group("ulp.product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ProductID] = row.Likes
+	}
+	return counts, nil
+}
+
+// Likers mengembalikan user yang menyukai product, yang terbaru lebih dulu.
+func (l *Likes) Likers(ctx context.Context, productID int64) ([]User, error) {
+	var users []User
+	err := l.db.WithContext(ctx).
+		Joins("JOIN user_like_product AS ulp ON ulp.user_id = users.id").
+		Where("ulp.product_id = ?", productID).
+		Order("ulp.liked_at DESC, users.id").
+		Find(&users).Error
+	return users, err
+}
+
+// MostLiked mengembalikan product dengan like terbanyak dalam days hari terakhir.
+func (l *Likes) MostLiked(ctx context.Context, days int, limit int) ([]ProductLikes, error) {
+	since := time.Now().AddDate(0, 0, -days).UTC()
+
+	var rows []likeCount
+	err := l.liveLikes(ctx, "ulp").
+		Select("ulp.product_id, COUNT(*) AS likes").
+		Where("ulp.liked_at >= ?", since).
+		Group("ulp.product_id").
+		Order("likes DESC, ulp.product_id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return l.withProducts(ctx, rows)
+}
+
+// AlsoLiked mengembalikan product lain yang disukai user yang juga menyukai
+// productID, diurutkan dari jumlah user yang sama terbanyak.
+func (l *Likes) AlsoLiked(ctx context.Context, productID int64, limit int) ([]ProductLikes, error) {
+	var rows []likeCount
+	err := l.liveLikes(ctx, "other").
+		Select("other.product_id, COUNT(*) AS likes").
+		Joins("JOIN user_like_product AS liked ON liked.user_id = other.user_id").
+		Where("liked.product_id = ? AND other.product_id <> ?", productID, productID).
+		Group("other.product_id").
+		Order("likes DESC, other.product_id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return l.withProducts(ctx, rows)
+}
+
+// withProducts memuat product untuk hasil hitungan dengan urutan yang sama.
+func (l *Likes) withProducts(ctx context.Context, rows []likeCount) ([]ProductLikes, error) {
+	if len(rows) == 0 {
+		return []ProductLikes{}, nil
+	}
+
+	ids := make([]int64, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ProductID
+	}
+	products, err := NewRepository[Product](l.db).List(ctx, Where("id IN ?", ids))
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	result := make([]ProductLikes, 0, len(rows))
+	for _, row := range rows {
+		if p, ok := byID[row.ProductID]; ok {
+			result = append(result, ProductLikes{Product: p, Likes: row.Likes})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,170 @@
+package belajargorm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLikesIdempotent(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	likes := NewLikes(db)
+
+	created, err := likes.Like(ctx, "3", productID)
+	assert.Nil(t, err)
+	assert.True(t, created)
+
+	first, err := likes.Get(ctx, "3", productID)
+	assert.Nil(t, err)
+	assert.False(t, first.LikedAt.IsZero())
+
+	created, err = likes.Like(ctx, "3", productID)
+	assert.Nil(t, err)
+	assert.False(t, created)
+
+	again, err := likes.Get(ctx, "3", productID)
+	assert.Nil(t, err)
+	assert.True(t, first.LikedAt.Equal(again.LikedAt))
+
+	removed, err := likes.Unlike(ctx, "3", productID)
+	assert.Nil(t, err)
+	assert.True(t, removed)
+
+	removed, err = likes.Unlike(ctx, "3", productID)
+	assert.Nil(t, err)
+	assert.False(t, removed)
+
+	_, err = likes.Get(ctx, "3", productID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = likes.Like(ctx, "tidak-ada", productID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = likes.Like(ctx, "3", 12345)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLikesAssociationSetsLikedAt(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	likes := NewLikes(db)
+
+	var product Product
+	assert.Nil(t, db.Take(&product, "id = ?", productID).Error)
+	assert.Nil(t, db.Model(&product).Association("LikedByUsers").Append(&User{ID: "4"}))
+
+	user := User{ID: "5"}
+	assert.Nil(t, db.Model(&user).Association("LikeProducts").Append(&product))
+
+	for _, userID := range []string{"4", "5"} {
+		like, err := likes.Get(ctx, userID, productID)
+		assert.Nil(t, err)
+		assert.WithinDuration(t, time.Now(), like.LikedAt, time.Minute)
+	}
+
+	users, err := likes.Likers(ctx, productID)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(users))
+}
+
+func TestLikesPopularity(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+	likes := store.Likes
+
+	teh := Product{Name: "Teh Manis", Price: IDR(8000)}
+	roti := Product{Name: "Roti Bakar", Price: IDR(15000)}
+	lama := Product{Name: "Produk Lama", Price: IDR(5000)}
+	for _, p := range []*Product{&teh, &roti, &lama} {
+		assert.Nil(t, store.Products.Create(ctx, p))
+	}
+
+	like := func(userID string, productID int64) {
+		_, err := likes.Like(ctx, userID, productID)
+		assert.Nil(t, err)
+	}
+	like("1", teh.ID)
+	like("2", teh.ID)
+	like("3", teh.ID)
+	like("1", roti.ID)
+	like("4", roti.ID)
+	like("5", lama.ID)
+	like("6", lama.ID)
+	like("7", lama.ID)
+	like("8", lama.ID)
+
+	// like produk lama terjadi 30 hari lalu
+	err := db.Model(&UserLikeProduct{}).Where("product_id = ?", lama.ID).
+		Update("liked_at", time.Now().AddDate(0, 0, -30).UTC()).Error
+	assert.Nil(t, err)
+
+	counts, err := likes.Counts(ctx, productID, teh.ID, roti.ID, 12345)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int64]int64{productID: 2, teh.ID: 3, roti.ID: 2, 12345: 0}, counts)
+
+	popular, err := likes.MostLiked(ctx, 7, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(popular))
+	assert.Equal(t, teh.ID, popular[0].Product.ID)
+	assert.Equal(t, int64(3), popular[0].Likes)
+	assert.Equal(t, int64(2), popular[1].Likes)
+
+	popular, err = likes.MostLiked(ctx, 60, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, lama.ID, popular[0].Product.ID)
+
+	// user 1 dan 2 menyukai productID, keduanya juga menyukai teh, user 1 juga roti
+	also, err := likes.AlsoLiked(ctx, productID, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(also))
+	assert.Equal(t, teh.ID, also[0].Product.ID)
+	assert.Equal(t, int64(2), also[0].Likes)
+	assert.Equal(t, roti.ID, also[1].Product.ID)
+	assert.Equal(t, int64(1), also[1].Likes)
+
+	// product dan user yang dihapus tidak dihitung
+	assert.Nil(t, store.Products.Delete(ctx, teh.ID))
+	_, err = store.Users.DeleteCascade(ctx, "4", CascadePolicy{User: CascadeSoftDelete})
+	assert.Nil(t, err)
+
+	counts, err = likes.Counts(ctx, teh.ID, roti.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int64]int64{teh.ID: 0, roti.ID: 1}, counts)
+
+	also, err = likes.AlsoLiked(ctx, productID, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(also))
+	assert.Equal(t, roti.ID, also[0].Product.ID)
+}
+
+func TestLikesMostLikedTimeZone(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	likes := NewLikes(db)
+
+	local := time.Local
+	time.Local = time.FixedZone("WIB", 7*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	_, err := likes.Like(ctx, "3", productID)
+	assert.Nil(t, err)
+	if db.Dialector.Name() == DriverSQLite {
+		var raw string
+		assert.Nil(t, db.Raw("SELECT liked_at || '' FROM user_like_product WHERE user_id = ?", "3").Scan(&raw).Error)
+		assert.True(t, strings.HasSuffix(raw, "+00:00"), raw)
+	}
+
+	// like 20 jam lalu masih masuk jendela 1 hari walaupun zona lokal bukan UTC
+	err = db.Model(&UserLikeProduct{}).Where("product_id = ?", productID).
+		Update("liked_at", time.Now().Add(-20*time.Hour).UTC()).Error
+	assert.Nil(t, err)
+
+	popular, err := likes.MostLiked(ctx, 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(popular))
+	assert.Equal(t, int64(3), popular[0].Likes)
+}
@@ -0,0 +1,420 @@
+package belajargorm
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+var (
+	ErrChecksumMismatch = errors.New("belajargorm: migration checksum mismatch")
+	ErrMigrationLocked  = errors.New("belajargorm: migration is locked by another process")
+	ErrUnknownMigration = errors.New("belajargorm: applied migration is missing from source")
+)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type SchemaMigration struct {
+	Version   int64     `gorm:"primary_key;column:version;autoIncrement:false"`
+	Name      string    `gorm:"column:name"`
+	Checksum  string    `gorm:"column:checksum"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (s *SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type SchemaMigrationLock struct {
+	ID       int       `gorm:"primary_key;column:id;autoIncrement:false"`
+	Owner    string    `gorm:"column:owner"`
+	LockedAt time.Time `gorm:"column:locked_at"`
+}
+
+func (s *SchemaMigrationLock) TableName() string {
+	return "schema_migrations_lock"
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// LoadMigrations membaca file <version>_<name>.<up|down>.sql dari dir.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(e.Name(), ".sql")
+		direction := path.Ext(base)
+		base = strings.TrimSuffix(base, direction)
+
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected <version>_<name>.<up|down>.sql", e.Name())
+		}
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", e.Name(), err)
+		}
+
+		b, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %d: name mismatch %q and %q", version, m.Name, name)
+		}
+
+		switch direction {
+		case ".up":
+			m.Up = string(b)
+		case ".down":
+			m.Down = string(b)
+		default:
+			return nil, fmt.Errorf("migration %s: unknown direction %q", e.Name(), direction)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up script", m.Version, m.Name)
+		}
+		m.Checksum = migrationChecksum(m.Up, m.Down)
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// migrationChecksum dihitung dari script up dan down, jadi mengedit salah
+// satunya setelah dijalankan terdeteksi oleh Verify.
+func migrationChecksum(up, down string) string {
+	sum := sha256.Sum256([]byte(up + "\x00" + down))
+	return hex.EncodeToString(sum[:])
+}
+
+// DefaultLockTimeout adalah umur lock yang dianggap tertinggal oleh proses
+// migrate yang mati, lihat SchemaMigrator.LockTimeout.
+const DefaultLockTimeout = 15 * time.Minute
+
+type SchemaMigrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	owner      string
+
+	// DryRun hanya menulis SQL yang akan dijalankan ke Out tanpa mengubah database.
+	DryRun bool
+	Out    io.Writer
+
+	// LockTimeout: lock yang locked_at-nya lebih lama dari ini boleh diambil
+	// alih. locked_at diperbarui setiap satu migration selesai, jadi nilainya
+	// harus lebih lama dari migration paling lama. 0 berarti tidak pernah.
+	LockTimeout time.Duration
+}
+
+// NewSchemaMigrator memakai migration bawaan sesuai dialect db (migrations/postgres
+// atau migrations/sqlite).
+func NewSchemaMigrator(db *gorm.DB) (*SchemaMigrator, error) {
+	migrations, err := LoadMigrations(migrationFiles, path.Join("migrations", db.Dialector.Name()))
+	if err != nil {
+		return nil, err
+	}
+	return NewSchemaMigratorWith(db, migrations), nil
+}
+
+func NewSchemaMigratorWith(db *gorm.DB, migrations []Migration) *SchemaMigrator {
+	host, _ := os.Hostname()
+
+	return &SchemaMigrator{
+		db:          db,
+		migrations:  migrations,
+		owner:       fmt.Sprintf("%s:%d", host, os.Getpid()),
+		Out:         os.Stdout,
+		LockTimeout: DefaultLockTimeout,
+	}
+}
+
+func (m *SchemaMigrator) Migrations() []Migration {
+	return m.migrations
+}
+
+func (m *SchemaMigrator) ensureTables(db *gorm.DB) error {
+	return db.AutoMigrate(&SchemaMigration{}, &SchemaMigrationLock{})
+}
+
+func (m *SchemaMigrator) applied(db *gorm.DB) (map[int64]SchemaMigration, error) {
+	result := map[int64]SchemaMigration{}
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return result, nil
+	}
+
+	var rows []SchemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		result[r.Version] = r
+	}
+	return result, nil
+}
+
+// Verify memastikan migration yang sudah dijalankan tidak diedit dan masih ada di source.
+func (m *SchemaMigrator) Verify(ctx context.Context) error {
+	applied, err := m.applied(m.db.WithContext(WithPrimary(ctx)))
+	if err != nil {
+		return err
+	}
+	return m.verify(applied)
+}
+
+func (m *SchemaMigrator) verify(applied map[int64]SchemaMigration) error {
+	known := map[int64]Migration{}
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+
+	for version, a := range applied {
+		mig, ok := known[version]
+		if !ok {
+			return fmt.Errorf("%w: %d_%s", ErrUnknownMigration, version, a.Name)
+		}
+		if mig.Checksum != a.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, version, mig.Name)
+		}
+	}
+	return nil
+}
+
+func (m *SchemaMigrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(m.db.WithContext(WithPrimary(ctx)))
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		a, ok := applied[mig.Version]
+		statuses = append(statuses, MigrationStatus{
+			Migration: mig,
+			Applied:   ok,
+			AppliedAt: a.AppliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// Up menjalankan semua migration yang belum dijalankan, masing-masing di dalam transaksi.
+func (m *SchemaMigrator) Up(ctx context.Context) error {
+	return m.run(ctx, func(applied map[int64]SchemaMigration) []Migration {
+		var pending []Migration
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; !ok {
+				pending = append(pending, mig)
+			}
+		}
+		return pending
+	}, true)
+}
+
+// Down me-rollback sejumlah steps migration terakhir yang sudah dijalankan.
+// steps <= 0 berarti rollback semuanya.
+func (m *SchemaMigrator) Down(ctx context.Context, steps int) error {
+	return m.run(ctx, func(applied map[int64]SchemaMigration) []Migration {
+		var targets []Migration
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if steps > 0 && len(targets) == steps {
+				break
+			}
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				targets = append(targets, m.migrations[i])
+			}
+		}
+		return targets
+	}, false)
+}
+
+func (m *SchemaMigrator) run(ctx context.Context, plan func(map[int64]SchemaMigration) []Migration, up bool) error {
+	// status migration selalu dibaca dari primary, bukan replica
+	db := m.db.WithContext(WithPrimary(ctx))
+
+	if m.DryRun {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		return m.print(plan(applied), up)
+	}
+
+	if err := m.ensureTables(db); err != nil {
+		return err
+	}
+
+	if err := m.lock(db); err != nil {
+		return err
+	}
+	defer m.unlock(db)
+
+	applied, err := m.applied(db)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(applied); err != nil {
+		return err
+	}
+
+	for _, mig := range plan(applied) {
+		if err := m.apply(db, mig, up); err != nil {
+			return err
+		}
+		if err := m.refreshLock(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SchemaMigrator) apply(db *gorm.DB, mig Migration, up bool) error {
+	script := mig.Up
+	if !up {
+		script = mig.Down
+		if strings.TrimSpace(script) == "" {
+			return fmt.Errorf("migration %d_%s: no down script", mig.Version, mig.Name)
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(script).Error; err != nil {
+			return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+
+		if !up {
+			return tx.Delete(&SchemaMigration{}, "version = ?", mig.Version).Error
+		}
+
+		return tx.Create(&SchemaMigration{
+			Version:   mig.Version,
+			Name:      mig.Name,
+			Checksum:  mig.Checksum,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+}
+
+func (m *SchemaMigrator) print(migrations []Migration, up bool) error {
+	direction := "up"
+	if !up {
+		direction = "down"
+	}
+
+	for _, mig := range migrations {
+		script := mig.Up
+		if !up {
+			script = mig.Down
+		}
+
+		_, err := fmt.Fprintf(m.Out, "-- %06d_%s (%s)\n%s\n", mig.Version, mig.Name, direction, strings.TrimSpace(script))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lock memakai satu baris di schema_migrations_lock, insert kedua akan gagal
+// karena primary key sehingga dua proses tidak bisa migrate bersamaan. Lock
+// yang lebih tua dari LockTimeout dianggap milik proses yang sudah mati dan
+// diambil alih.
+func (m *SchemaMigrator) lock(db *gorm.DB) error {
+	err := m.insertLock(db)
+	if err == nil {
+		return nil
+	}
+
+	var holder SchemaMigrationLock
+	if db.Take(&holder, "id = ?", 1).Error != nil {
+		return err
+	}
+
+	if m.LockTimeout > 0 && time.Since(holder.LockedAt) > m.LockTimeout {
+		// kalau dua proses mengambil alih bersamaan, hanya satu yang berhasil
+		// menghapus dan insert berikutnya tetap dijaga primary key
+		stale := db.Where("id = ? AND locked_at < ?", 1, time.Now().UTC().Add(-m.LockTimeout)).Delete(&SchemaMigrationLock{})
+		if stale.Error != nil {
+			return stale.Error
+		}
+		if stale.RowsAffected == 1 && m.insertLock(db) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: held by %s since %s", ErrMigrationLocked, holder.Owner, holder.LockedAt.Format(time.RFC3339))
+}
+
+func (m *SchemaMigrator) insertLock(db *gorm.DB) error {
+	return db.Create(&SchemaMigrationLock{
+		ID:       1,
+		Owner:    m.owner,
+		LockedAt: time.Now().UTC(),
+	}).Error
+}
+
+// refreshLock memperbarui locked_at supaya lock proses yang masih berjalan
+// tidak dianggap tertinggal.
+func (m *SchemaMigrator) refreshLock(db *gorm.DB) error {
+	return db.Model(&SchemaMigrationLock{}).Where("id = ? AND owner = ?", 1, m.owner).
+		Update("locked_at", time.Now().UTC()).Error
+}
+
+func (m *SchemaMigrator) unlock(db *gorm.DB) error {
+	return db.Delete(&SchemaMigrationLock{}, "id = ? AND owner = ?", 1, m.owner).Error
+}
+
+// ForceUnlock menghapus lock yang tertinggal, misalnya karena proses migrate mati di tengah jalan.
+func (m *SchemaMigrator) ForceUnlock(ctx context.Context) error {
+	return m.db.WithContext(ctx).Delete(&SchemaMigrationLock{}, "id = ?", 1).Error
+}
@@ -0,0 +1,177 @@
+package belajargorm
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaMigratorUpDown(t *testing.T) {
+	db := openEmptyDB(t)
+	ctx := context.Background()
+
+	migrator, err := NewSchemaMigrator(db)
+	assert.Nil(t, err)
+
+	err = migrator.Up(ctx)
+	assert.Nil(t, err)
+
+	for _, table := range []string{"users", "user_logs", "addresses", "todos", "tags", "todo_tags", "wallets", "products", "categories", "product_price_history", "user_like_product", "orders", "order_items", "guest_books", "sample"} {
+		assert.True(t, db.Migrator().HasTable(table), table)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	assert.Nil(t, err)
+	for _, s := range statuses {
+		assert.True(t, s.Applied, s.Name)
+	}
+
+	err = migrator.Down(ctx, 1)
+	assert.Nil(t, err)
+
+	statuses, err = migrator.Status(ctx)
+	assert.Nil(t, err)
+	for i, s := range statuses {
+		assert.Equal(t, i < len(statuses)-1, s.Applied, s.Name)
+	}
+
+	err = migrator.Down(ctx, 0)
+	assert.Nil(t, err)
+	assert.False(t, db.Migrator().HasTable("users"))
+
+	var count int64
+	db.Model(&SchemaMigration{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	err = migrator.Up(ctx)
+	assert.Nil(t, err)
+	assert.True(t, db.Migrator().HasTable("users"))
+}
+
+func TestSchemaMigratorChecksum(t *testing.T) {
+	db := openEmptyDB(t)
+	ctx := context.Background()
+
+	migrator, err := NewSchemaMigrator(db)
+	assert.Nil(t, err)
+	assert.Nil(t, migrator.Up(ctx))
+
+	edited := append([]Migration{}, migrator.Migrations()...)
+	edited[0].Checksum = "edited"
+
+	// edit script down juga terdeteksi
+	downEdited := append([]Migration{}, migrator.Migrations()...)
+	downEdited[0].Down += "\n-- edited"
+	downEdited[0].Checksum = migrationChecksum(downEdited[0].Up, downEdited[0].Down)
+	err = NewSchemaMigratorWith(db, downEdited).Verify(ctx)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	err = NewSchemaMigratorWith(db, edited).Up(ctx)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	err = NewSchemaMigratorWith(db, edited[1:]).Verify(ctx)
+	assert.ErrorIs(t, err, ErrUnknownMigration)
+}
+
+func TestSchemaMigratorLock(t *testing.T) {
+	db := openEmptyDB(t)
+	ctx := context.Background()
+
+	migrator, err := NewSchemaMigrator(db)
+	assert.Nil(t, err)
+	assert.Nil(t, migrator.ensureTables(db))
+
+	err = db.Create(&SchemaMigrationLock{ID: 1, Owner: "deploy-lain", LockedAt: time.Now().UTC()}).Error
+	assert.Nil(t, err)
+
+	err = migrator.Up(ctx)
+	assert.ErrorIs(t, err, ErrMigrationLocked)
+	assert.False(t, db.Migrator().HasTable("users"))
+
+	assert.Nil(t, migrator.ForceUnlock(ctx))
+	assert.Nil(t, migrator.Up(ctx))
+	assert.True(t, db.Migrator().HasTable("users"))
+
+	var count int64
+	db.Model(&SchemaMigrationLock{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestSchemaMigratorStaleLock(t *testing.T) {
+	db := openEmptyDB(t)
+	ctx := context.Background()
+
+	migrator, err := NewSchemaMigrator(db)
+	assert.Nil(t, err)
+	assert.Nil(t, migrator.ensureTables(db))
+
+	// proses migrate yang mati meninggalkan lock lama
+	lockedAt := time.Now().UTC().Add(-2 * DefaultLockTimeout)
+	err = db.Create(&SchemaMigrationLock{ID: 1, Owner: "deploy-mati", LockedAt: lockedAt}).Error
+	assert.Nil(t, err)
+
+	migrator.LockTimeout = 0
+	assert.ErrorIs(t, migrator.Up(ctx), ErrMigrationLocked)
+
+	migrator.LockTimeout = DefaultLockTimeout
+	assert.Nil(t, migrator.Up(ctx))
+	assert.True(t, db.Migrator().HasTable("users"))
+
+	var count int64
+	db.Model(&SchemaMigrationLock{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestSchemaMigratorDryRun(t *testing.T) {
+	db := openEmptyDB(t)
+
+	var out bytes.Buffer
+	migrator, err := NewSchemaMigrator(db)
+	assert.Nil(t, err)
+	migrator.DryRun = true
+	migrator.Out = &out
+
+	err = migrator.Up(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(out.String(), "-- 000001_create_users (up)"))
+	assert.True(t, strings.Contains(out.String(), "CREATE TABLE users"))
+	assert.False(t, db.Migrator().HasTable("users"))
+	assert.False(t, db.Migrator().HasTable(&SchemaMigration{}))
+}
+
+func TestSchemaMigratorMergesTodoGorms(t *testing.T) {
+	db := openEmptyDB(t)
+	ctx := context.Background()
+
+	migrator, err := NewSchemaMigrator(db)
+	assert.Nil(t, err)
+
+	// jalankan migration sampai sebelum todo_gorms digabung ke todos
+	var before []Migration
+	for _, m := range migrator.Migrations() {
+		if m.Version < 11 {
+			before = append(before, m)
+		}
+	}
+	assert.Nil(t, NewSchemaMigratorWith(db, before).Up(ctx))
+
+	err = db.Exec("INSERT INTO users (id, password, first_name, created_at, updated_at) VALUES ('1', 'rahasia', 'Yonathan', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)").Error
+	assert.Nil(t, err)
+	err = db.Exec("INSERT INTO todo_gorms (user_id, task, created_at, updated_at) VALUES ('1', 'Dari todo_gorms', '2024-01-02 03:04:05', '2024-01-02 03:04:05')").Error
+	assert.Nil(t, err)
+
+	assert.Nil(t, migrator.Up(ctx))
+	assert.False(t, db.Migrator().HasTable("todo_gorms"))
+
+	var todos []Todo
+	assert.Nil(t, db.Find(&todos).Error)
+	assert.Equal(t, 1, len(todos))
+	assert.Equal(t, "Dari todo_gorms", todos[0].Task)
+	assert.Equal(t, TodoOpen, todos[0].Status)
+	assert.Equal(t, PriorityNormal, todos[0].Priority)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano(), todos[0].CreatedAt)
+}
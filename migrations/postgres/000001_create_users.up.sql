@@ -0,0 +1,19 @@
+CREATE TABLE users (
+    id          text PRIMARY KEY,
+    password    text NOT NULL DEFAULT '',
+    first_name  text NOT NULL DEFAULT '',
+    middle_name text NOT NULL DEFAULT '',
+    last_name   text NOT NULL DEFAULT '',
+    created_at  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE user_logs (
+    id         serial PRIMARY KEY,
+    user_id    text NOT NULL DEFAULT '',
+    action     text NOT NULL DEFAULT '',
+    created_at bigint NOT NULL DEFAULT 0,
+    updated_at bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_user_logs_user_id ON user_logs (user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE wallets (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id    text NOT NULL REFERENCES users (id),
+    balance    bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_wallets_deleted_at ON wallets (deleted_at);
+CREATE INDEX idx_wallets_user_id ON wallets (user_id);
@@ -0,0 +1,10 @@
+CREATE TABLE addresses (
+    id         bigserial PRIMARY KEY,
+    user_id    text NOT NULL REFERENCES users (id),
+    address    text NOT NULL DEFAULT '',
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz
+);
+
+CREATE INDEX idx_addresses_user_id ON addresses (user_id);
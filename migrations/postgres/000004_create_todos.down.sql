@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS todo_gorms;
+DROP TABLE IF EXISTS todos;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_like_product;
+DROP TABLE IF EXISTS products;
@@ -0,0 +1,11 @@
+CREATE TABLE guest_books (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name       text NOT NULL DEFAULT '',
+    email      text NOT NULL DEFAULT '',
+    message    text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_guest_books_deleted_at ON guest_books (deleted_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wallet_transactions;
+DROP FUNCTION IF EXISTS wallet_transactions_append_only();
+DROP TABLE IF EXISTS wallet_transfers;
@@ -0,0 +1,43 @@
+CREATE TABLE wallet_transfers (
+    id              text PRIMARY KEY,
+    idempotency_key text,
+    from_user_id    text NOT NULL REFERENCES users (id),
+    to_user_id      text NOT NULL REFERENCES users (id),
+    amount          bigint NOT NULL CHECK (amount > 0),
+    created_at      timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_wallet_transfers_idempotency_key ON wallet_transfers (idempotency_key);
+
+CREATE TABLE wallet_transactions (
+    id            bigserial PRIMARY KEY,
+    wallet_id     bigint NOT NULL REFERENCES wallets (id),
+    transfer_id   text REFERENCES wallet_transfers (id),
+    entry_type    text NOT NULL CHECK (entry_type IN ('debit', 'credit')),
+    amount        bigint NOT NULL CHECK (amount > 0),
+    balance_after bigint NOT NULL,
+    description   text NOT NULL DEFAULT '',
+    created_at    timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wallet_transactions_wallet_id ON wallet_transactions (wallet_id);
+CREATE INDEX idx_wallet_transactions_transfer_id ON wallet_transactions (transfer_id);
+
+CREATE OR REPLACE FUNCTION wallet_transactions_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'wallet_transactions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER wallet_transactions_append_only
+    BEFORE UPDATE OR DELETE ON wallet_transactions
+    FOR EACH ROW EXECUTE FUNCTION wallet_transactions_append_only();
+
+INSERT INTO wallet_transactions (wallet_id, entry_type, amount, balance_after, description)
+SELECT id,
+       CASE WHEN balance > 0 THEN 'credit' ELSE 'debit' END,
+       ABS(balance),
+       balance,
+       'opening balance'
+FROM wallets
+WHERE balance <> 0;
@@ -0,0 +1,6 @@
+DROP INDEX idx_user_logs_entity_record;
+
+ALTER TABLE user_logs DROP COLUMN changes;
+ALTER TABLE user_logs DROP COLUMN record_id;
+ALTER TABLE user_logs DROP COLUMN entity;
+ALTER TABLE user_logs DROP COLUMN actor;
@@ -0,0 +1,34 @@
+ALTER TABLE todos ADD COLUMN status text NOT NULL DEFAULT 'open';
+ALTER TABLE todos ADD COLUMN priority integer NOT NULL DEFAULT 2;
+ALTER TABLE todos ADD COLUMN due_at timestamptz;
+ALTER TABLE todos ADD COLUMN completed_at timestamptz;
+
+CREATE INDEX idx_todos_status_due_at ON todos (status, due_at);
+
+CREATE TABLE tags (
+    id         bigserial PRIMARY KEY,
+    name       text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_tags_name ON tags (name);
+
+CREATE TABLE todo_tags (
+    todo_id integer NOT NULL REFERENCES todos (id),
+    tag_id  bigint NOT NULL REFERENCES tags (id),
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags (tag_id);
+
+-- todo_gorms digabung ke todos, timestamp diubah ke unix nano seperti kolom todos
+INSERT INTO todos (user_id, task, created_at, updated_at, deleted_at)
+SELECT user_id,
+       task,
+       COALESCE((EXTRACT(EPOCH FROM created_at) * 1000000000)::bigint, 0),
+       COALESCE((EXTRACT(EPOCH FROM updated_at) * 1000000000)::bigint, 0),
+       COALESCE((EXTRACT(EPOCH FROM deleted_at) * 1000000000)::bigint, 0)
+FROM todo_gorms
+ORDER BY id;
+
+DROP TABLE todo_gorms;
@@ -0,0 +1,3 @@
+DROP INDEX idx_user_like_product_liked_at;
+
+ALTER TABLE user_like_product DROP COLUMN liked_at;
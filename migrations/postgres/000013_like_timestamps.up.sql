@@ -0,0 +1,3 @@
+ALTER TABLE user_like_product ADD COLUMN liked_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_user_like_product_liked_at ON user_like_product (liked_at);
@@ -0,0 +1,11 @@
+DROP TRIGGER products_price_history ON products;
+DROP FUNCTION products_price_history();
+DROP TABLE product_price_history;
+
+DROP INDEX idx_products_category_id;
+ALTER TABLE products DROP CONSTRAINT products_reserved_stock_check;
+ALTER TABLE products DROP COLUMN reserved;
+ALTER TABLE products DROP COLUMN stock;
+ALTER TABLE products DROP COLUMN category_id;
+
+DROP TABLE categories;
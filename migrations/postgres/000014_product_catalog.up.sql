@@ -0,0 +1,47 @@
+CREATE TABLE categories (
+    id         bigserial PRIMARY KEY,
+    parent_id  bigint REFERENCES categories (id),
+    name       text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+CREATE UNIQUE INDEX idx_categories_parent_name ON categories (COALESCE(parent_id, 0), name);
+
+ALTER TABLE products ADD COLUMN category_id bigint REFERENCES categories (id);
+ALTER TABLE products ADD COLUMN stock bigint NOT NULL DEFAULT 0 CHECK (stock >= 0);
+ALTER TABLE products ADD COLUMN reserved bigint NOT NULL DEFAULT 0 CHECK (reserved >= 0);
+ALTER TABLE products ADD CONSTRAINT products_reserved_stock_check CHECK (reserved <= stock);
+
+CREATE INDEX idx_products_category_id ON products (category_id);
+
+CREATE TABLE product_price_history (
+    id           bigserial PRIMARY KEY,
+    product_id   bigint NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    price        bigint NOT NULL,
+    currency     text NOT NULL,
+    effective_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_price_history_product_id ON product_price_history (product_id, effective_at);
+
+-- riwayat harga diisi trigger supaya setiap perubahan harga tercatat, termasuk
+-- update yang tidak lewat hook gorm
+CREATE OR REPLACE FUNCTION products_price_history() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.price IS DISTINCT FROM OLD.price OR NEW.currency IS DISTINCT FROM OLD.currency THEN
+        INSERT INTO product_price_history (product_id, price, currency) VALUES (NEW.id, NEW.price, NEW.currency);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_price_history
+    AFTER INSERT OR UPDATE OF price, currency ON products
+    FOR EACH ROW EXECUTE FUNCTION products_price_history();
+
+-- harga product lama dianggap berlaku sejak product dibuat
+INSERT INTO product_price_history (product_id, price, currency, effective_at)
+SELECT id, price, currency, COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM products;
@@ -0,0 +1,11 @@
+DROP INDEX idx_guest_books_email;
+DROP INDEX idx_guest_books_parent_id;
+DROP INDEX idx_guest_books_status;
+
+ALTER TABLE guest_books DROP COLUMN spam_reasons;
+ALTER TABLE guest_books DROP COLUMN spam_score;
+ALTER TABLE guest_books DROP COLUMN moderated_at;
+ALTER TABLE guest_books DROP COLUMN moderated_by;
+ALTER TABLE guest_books DROP COLUMN moderation_note;
+ALTER TABLE guest_books DROP COLUMN parent_id;
+ALTER TABLE guest_books DROP COLUMN status;
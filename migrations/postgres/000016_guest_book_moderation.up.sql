@@ -0,0 +1,13 @@
+-- entry lama sudah tampil ke publik, jadi dianggap approved
+ALTER TABLE guest_books ADD COLUMN status text NOT NULL DEFAULT 'approved'
+    CHECK (status IN ('pending', 'approved', 'rejected', 'spam'));
+ALTER TABLE guest_books ADD COLUMN parent_id bigint REFERENCES guest_books (id);
+ALTER TABLE guest_books ADD COLUMN moderation_note text NOT NULL DEFAULT '';
+ALTER TABLE guest_books ADD COLUMN moderated_by text;
+ALTER TABLE guest_books ADD COLUMN moderated_at timestamptz;
+ALTER TABLE guest_books ADD COLUMN spam_score integer NOT NULL DEFAULT 0;
+ALTER TABLE guest_books ADD COLUMN spam_reasons text NOT NULL DEFAULT '';
+
+CREATE INDEX idx_guest_books_status ON guest_books (status, created_at);
+CREATE INDEX idx_guest_books_parent_id ON guest_books (parent_id);
+CREATE INDEX idx_guest_books_email ON guest_books (email, created_at);
@@ -0,0 +1,17 @@
+DROP INDEX idx_addresses_default;
+
+ALTER TABLE addresses ADD COLUMN address text NOT NULL DEFAULT '';
+UPDATE addresses SET address = line1
+    || CASE WHEN line2 <> '' THEN ', ' || line2 ELSE '' END
+    || CASE WHEN city <> '' THEN ', ' || city ELSE '' END
+    || CASE WHEN region <> '' THEN ', ' || region ELSE '' END
+    || CASE WHEN postal_code <> '' THEN ' ' || postal_code ELSE '' END;
+
+ALTER TABLE addresses DROP COLUMN is_default;
+ALTER TABLE addresses DROP COLUMN country;
+ALTER TABLE addresses DROP COLUMN postal_code;
+ALTER TABLE addresses DROP COLUMN region;
+ALTER TABLE addresses DROP COLUMN city;
+ALTER TABLE addresses DROP COLUMN line2;
+ALTER TABLE addresses DROP COLUMN line1;
+ALTER TABLE addresses DROP COLUMN label;
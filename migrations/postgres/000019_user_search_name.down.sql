@@ -0,0 +1,3 @@
+-- extension pg_trgm dan unaccent dibiarkan, bisa saja dipakai schema lain
+DROP INDEX idx_users_search_name;
+ALTER TABLE users DROP COLUMN search_name;
@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- search_name berisi nama lengkap lowercase tanpa aksen, diisi hook User
+ALTER TABLE users ADD COLUMN search_name text NOT NULL DEFAULT '';
+UPDATE users SET search_name = lower(unaccent(
+    concat_ws(' ', nullif(trim(first_name), ''), nullif(trim(middle_name), ''), nullif(trim(last_name), ''))
+));
+
+CREATE INDEX idx_users_search_name ON users USING gin (search_name gin_trgm_ops);
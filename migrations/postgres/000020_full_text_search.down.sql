@@ -0,0 +1,7 @@
+DROP INDEX idx_todos_search_vector;
+DROP INDEX idx_guest_books_search_vector;
+DROP INDEX idx_products_search_vector;
+
+ALTER TABLE todos DROP COLUMN search_vector;
+ALTER TABLE guest_books DROP COLUMN search_vector;
+ALTER TABLE products DROP COLUMN search_vector;
@@ -0,0 +1,13 @@
+-- config 'simple' dipakai karena data kebanyakan berbahasa Indonesia, yang
+-- tidak punya stemmer bawaan Postgres. Kolom generated selalu ikut berubah
+-- bersama sumbernya, termasuk update yang tidak lewat gorm.
+ALTER TABLE products ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(name, ''))) STORED;
+ALTER TABLE guest_books ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(message, ''))) STORED;
+ALTER TABLE todos ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(task, ''))) STORED;
+
+CREATE INDEX idx_products_search_vector ON products USING gin (search_vector);
+CREATE INDEX idx_guest_books_search_vector ON guest_books USING gin (search_vector);
+CREATE INDEX idx_todos_search_vector ON todos USING gin (search_vector);
@@ -0,0 +1,2 @@
+UPDATE user_logs SET user_id = '' WHERE user_id IS NULL;
+ALTER TABLE user_logs ALTER COLUMN user_id SET NOT NULL;
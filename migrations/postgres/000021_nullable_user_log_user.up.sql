@@ -0,0 +1,2 @@
+-- user_id boleh NULL supaya log audit tetap ada setelah user dihapus permanen (CascadeNullify)
+ALTER TABLE user_logs ALTER COLUMN user_id DROP NOT NULL;
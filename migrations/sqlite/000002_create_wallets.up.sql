@@ -0,0 +1,11 @@
+CREATE TABLE wallets (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_id    text NOT NULL REFERENCES users (id),
+    balance    bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_wallets_deleted_at ON wallets (deleted_at);
+CREATE INDEX idx_wallets_user_id ON wallets (user_id);
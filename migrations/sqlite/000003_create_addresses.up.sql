@@ -0,0 +1,10 @@
+CREATE TABLE addresses (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    text NOT NULL REFERENCES users (id),
+    address    text NOT NULL DEFAULT '',
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime
+);
+
+CREATE INDEX idx_addresses_user_id ON addresses (user_id);
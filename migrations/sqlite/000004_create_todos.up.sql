@@ -0,0 +1,21 @@
+CREATE TABLE todos (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    text NOT NULL DEFAULT '',
+    task       text NOT NULL DEFAULT '',
+    created_at bigint NOT NULL DEFAULT 0,
+    updated_at bigint NOT NULL DEFAULT 0,
+    deleted_at bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_todos_user_id ON todos (user_id);
+
+CREATE TABLE todo_gorms (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_id    text NOT NULL DEFAULT '',
+    task       text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_todo_gorms_deleted_at ON todo_gorms (deleted_at);
@@ -0,0 +1,15 @@
+CREATE TABLE products (
+    id         integer PRIMARY KEY,
+    name       text NOT NULL DEFAULT '',
+    price      bigint NOT NULL DEFAULT 0,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE user_like_product (
+    user_id    text NOT NULL REFERENCES users (id),
+    product_id bigint NOT NULL REFERENCES products (id),
+    PRIMARY KEY (user_id, product_id)
+);
+
+CREATE INDEX idx_user_like_product_product_id ON user_like_product (product_id);
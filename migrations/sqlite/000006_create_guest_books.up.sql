@@ -0,0 +1,11 @@
+CREATE TABLE guest_books (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    name       text NOT NULL DEFAULT '',
+    email      text NOT NULL DEFAULT '',
+    message    text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_guest_books_deleted_at ON guest_books (deleted_at);
@@ -0,0 +1,4 @@
+CREATE TABLE sample (
+    id   text PRIMARY KEY,
+    name text NOT NULL DEFAULT ''
+);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS wallet_transactions_no_delete;
+DROP TRIGGER IF EXISTS wallet_transactions_no_update;
+DROP TABLE IF EXISTS wallet_transactions;
+DROP TABLE IF EXISTS wallet_transfers;
@@ -0,0 +1,43 @@
+CREATE TABLE wallet_transfers (
+    id              text PRIMARY KEY,
+    idempotency_key text,
+    from_user_id    text NOT NULL REFERENCES users (id),
+    to_user_id      text NOT NULL REFERENCES users (id),
+    amount          bigint NOT NULL CHECK (amount > 0),
+    created_at      datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_wallet_transfers_idempotency_key ON wallet_transfers (idempotency_key);
+
+CREATE TABLE wallet_transactions (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    wallet_id     bigint NOT NULL REFERENCES wallets (id),
+    transfer_id   text REFERENCES wallet_transfers (id),
+    entry_type    text NOT NULL CHECK (entry_type IN ('debit', 'credit')),
+    amount        bigint NOT NULL CHECK (amount > 0),
+    balance_after bigint NOT NULL,
+    description   text NOT NULL DEFAULT '',
+    created_at    datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wallet_transactions_wallet_id ON wallet_transactions (wallet_id);
+CREATE INDEX idx_wallet_transactions_transfer_id ON wallet_transactions (transfer_id);
+
+CREATE TRIGGER wallet_transactions_no_update BEFORE UPDATE ON wallet_transactions
+BEGIN
+    SELECT RAISE(ABORT, 'wallet_transactions is append-only');
+END;
+
+CREATE TRIGGER wallet_transactions_no_delete BEFORE DELETE ON wallet_transactions
+BEGIN
+    SELECT RAISE(ABORT, 'wallet_transactions is append-only');
+END;
+
+INSERT INTO wallet_transactions (wallet_id, entry_type, amount, balance_after, description)
+SELECT id,
+       CASE WHEN balance > 0 THEN 'credit' ELSE 'debit' END,
+       ABS(balance),
+       balance,
+       'opening balance'
+FROM wallets
+WHERE balance <> 0;
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP COLUMN currency;
+ALTER TABLE wallets DROP COLUMN currency;
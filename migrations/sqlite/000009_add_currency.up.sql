@@ -0,0 +1,2 @@
+ALTER TABLE wallets ADD COLUMN currency text NOT NULL DEFAULT 'IDR';
+ALTER TABLE products ADD COLUMN currency text NOT NULL DEFAULT 'IDR';
@@ -0,0 +1,6 @@
+ALTER TABLE user_logs ADD COLUMN actor text NOT NULL DEFAULT '';
+ALTER TABLE user_logs ADD COLUMN entity text NOT NULL DEFAULT '';
+ALTER TABLE user_logs ADD COLUMN record_id text NOT NULL DEFAULT '';
+ALTER TABLE user_logs ADD COLUMN changes text NOT NULL DEFAULT '';
+
+CREATE INDEX idx_user_logs_entity_record ON user_logs (entity, record_id);
@@ -0,0 +1,21 @@
+-- data todo_gorms yang sudah digabung ke todos tidak dipisahkan lagi
+CREATE TABLE todo_gorms (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_id    text NOT NULL DEFAULT '',
+    task       text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_todo_gorms_deleted_at ON todo_gorms (deleted_at);
+
+DROP TABLE todo_tags;
+DROP TABLE tags;
+
+DROP INDEX idx_todos_status_due_at;
+
+ALTER TABLE todos DROP COLUMN completed_at;
+ALTER TABLE todos DROP COLUMN due_at;
+ALTER TABLE todos DROP COLUMN priority;
+ALTER TABLE todos DROP COLUMN status;
@@ -0,0 +1,34 @@
+ALTER TABLE todos ADD COLUMN status text NOT NULL DEFAULT 'open';
+ALTER TABLE todos ADD COLUMN priority integer NOT NULL DEFAULT 2;
+ALTER TABLE todos ADD COLUMN due_at datetime;
+ALTER TABLE todos ADD COLUMN completed_at datetime;
+
+CREATE INDEX idx_todos_status_due_at ON todos (status, due_at);
+
+CREATE TABLE tags (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    name       text NOT NULL,
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_tags_name ON tags (name);
+
+CREATE TABLE todo_tags (
+    todo_id integer NOT NULL REFERENCES todos (id),
+    tag_id  integer NOT NULL REFERENCES tags (id),
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags (tag_id);
+
+-- todo_gorms digabung ke todos, timestamp diubah ke unix nano seperti kolom todos
+INSERT INTO todos (user_id, task, created_at, updated_at, deleted_at)
+SELECT user_id,
+       task,
+       COALESCE(CAST(strftime('%s', created_at) AS INTEGER) * 1000000000, 0),
+       COALESCE(CAST(strftime('%s', updated_at) AS INTEGER) * 1000000000, 0),
+       COALESCE(CAST(strftime('%s', deleted_at) AS INTEGER) * 1000000000, 0)
+FROM todo_gorms
+ORDER BY id;
+
+DROP TABLE todo_gorms;
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN deleted_at datetime;
+ALTER TABLE products ADD COLUMN deleted_at datetime;
+
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX idx_products_deleted_at ON products (deleted_at);
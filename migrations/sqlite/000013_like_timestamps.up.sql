@@ -0,0 +1,9 @@
+-- SQLite tidak mengizinkan ADD COLUMN dengan default CURRENT_TIMESTAMP, jadi
+-- kolom diisi dulu untuk data lama dan selanjutnya diisi aplikasi
+ALTER TABLE user_like_product ADD COLUMN liked_at datetime;
+
+-- formatnya sama dengan time.Time UTC dari driver supaya perbandingan text
+-- dengan liked_at yang ditulis aplikasi tetap benar
+UPDATE user_like_product SET liked_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE liked_at IS NULL;
+
+CREATE INDEX idx_user_like_product_liked_at ON user_like_product (liked_at);
@@ -0,0 +1,29 @@
+DROP TRIGGER products_price_history_update;
+DROP TRIGGER products_price_history_insert;
+DROP TABLE product_price_history;
+
+-- SQLite tidak bisa DROP COLUMN yang dipakai foreign key atau CHECK kolom lain,
+-- jadi table products dibuat ulang tanpa kolom baru
+DROP INDEX idx_products_category_id;
+DROP INDEX idx_products_deleted_at;
+
+CREATE TABLE products_old (
+    id         integer PRIMARY KEY,
+    name       text NOT NULL DEFAULT '',
+    price      bigint NOT NULL DEFAULT 0,
+    created_at datetime,
+    updated_at datetime,
+    currency   text NOT NULL DEFAULT 'IDR',
+    deleted_at datetime
+);
+
+INSERT INTO products_old (id, name, price, created_at, updated_at, currency, deleted_at)
+SELECT id, name, price, created_at, updated_at, currency, deleted_at
+FROM products;
+
+DROP TABLE products;
+ALTER TABLE products_old RENAME TO products;
+
+CREATE INDEX idx_products_deleted_at ON products (deleted_at);
+
+DROP TABLE categories;
@@ -0,0 +1,46 @@
+CREATE TABLE categories (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    parent_id  bigint REFERENCES categories (id),
+    name       text NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+CREATE UNIQUE INDEX idx_categories_parent_name ON categories (COALESCE(parent_id, 0), name);
+
+ALTER TABLE products ADD COLUMN category_id bigint REFERENCES categories (id);
+ALTER TABLE products ADD COLUMN stock bigint NOT NULL DEFAULT 0 CHECK (stock >= 0);
+ALTER TABLE products ADD COLUMN reserved bigint NOT NULL DEFAULT 0 CHECK (reserved >= 0 AND reserved <= stock);
+
+CREATE INDEX idx_products_category_id ON products (category_id);
+
+-- effective_at memakai format yang sama dengan time.Time dari driver supaya
+-- perbandingan text di SQLite tetap benar
+CREATE TABLE product_price_history (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    product_id   bigint NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    price        bigint NOT NULL,
+    currency     text NOT NULL,
+    effective_at datetime NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX idx_product_price_history_product_id ON product_price_history (product_id, effective_at);
+
+-- riwayat harga diisi trigger supaya setiap perubahan harga tercatat, termasuk
+-- update yang tidak lewat hook gorm
+CREATE TRIGGER products_price_history_insert AFTER INSERT ON products
+BEGIN
+    INSERT INTO product_price_history (product_id, price, currency) VALUES (NEW.id, NEW.price, NEW.currency);
+END;
+
+CREATE TRIGGER products_price_history_update AFTER UPDATE OF price, currency ON products
+WHEN NEW.price IS NOT OLD.price OR NEW.currency IS NOT OLD.currency
+BEGIN
+    INSERT INTO product_price_history (product_id, price, currency) VALUES (NEW.id, NEW.price, NEW.currency);
+END;
+
+-- harga product lama dianggap berlaku sejak product dibuat
+INSERT INTO product_price_history (product_id, price, currency, effective_at)
+SELECT id, price, currency, COALESCE(created_at, strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+FROM products;
@@ -0,0 +1,33 @@
+CREATE TABLE orders (
+    id                     integer PRIMARY KEY AUTOINCREMENT,
+    user_id                text NOT NULL REFERENCES users (id),
+    status                 text NOT NULL CHECK (status IN ('pending', 'paid', 'cancelled', 'refunded')),
+    total                  bigint NOT NULL CHECK (total >= 0),
+    currency               text NOT NULL,
+    address_id             bigint REFERENCES addresses (id),
+    shipping_address       text NOT NULL DEFAULT '',
+    payment_transaction_id bigint REFERENCES wallet_transactions (id),
+    refund_transaction_id  bigint REFERENCES wallet_transactions (id),
+    paid_at                datetime,
+    cancelled_at           datetime,
+    refunded_at            datetime,
+    created_at             datetime,
+    updated_at             datetime
+);
+
+CREATE INDEX idx_orders_user_id ON orders (user_id);
+CREATE INDEX idx_orders_status ON orders (status);
+
+CREATE TABLE order_items (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    order_id     bigint NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id   bigint NOT NULL REFERENCES products (id),
+    product_name text NOT NULL,
+    unit_price   bigint NOT NULL CHECK (unit_price >= 0),
+    currency     text NOT NULL,
+    quantity     bigint NOT NULL CHECK (quantity > 0),
+    subtotal     bigint NOT NULL CHECK (subtotal >= 0)
+);
+
+CREATE INDEX idx_order_items_order_id ON order_items (order_id);
+CREATE INDEX idx_order_items_product_id ON order_items (product_id);
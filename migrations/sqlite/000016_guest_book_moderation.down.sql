@@ -0,0 +1,25 @@
+-- SQLite tidak bisa DROP COLUMN yang dipakai foreign key, jadi table
+-- guest_books dibuat ulang tanpa kolom moderasi
+DROP INDEX idx_guest_books_email;
+DROP INDEX idx_guest_books_parent_id;
+DROP INDEX idx_guest_books_status;
+DROP INDEX idx_guest_books_deleted_at;
+
+CREATE TABLE guest_books_old (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    name       text NOT NULL DEFAULT '',
+    email      text NOT NULL DEFAULT '',
+    message    text NOT NULL DEFAULT ''
+);
+
+INSERT INTO guest_books_old (id, created_at, updated_at, deleted_at, name, email, message)
+SELECT id, created_at, updated_at, deleted_at, name, email, message
+FROM guest_books;
+
+DROP TABLE guest_books;
+ALTER TABLE guest_books_old RENAME TO guest_books;
+
+CREATE INDEX idx_guest_books_deleted_at ON guest_books (deleted_at);
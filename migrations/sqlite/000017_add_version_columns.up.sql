@@ -0,0 +1,5 @@
+-- version dipakai optimistic lock: setiap update menaikkan version dan gagal
+-- kalau version di database sudah berbeda dari yang terakhir dibaca
+ALTER TABLE users ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE wallets ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE products ADD COLUMN version integer NOT NULL DEFAULT 1;
@@ -0,0 +1,19 @@
+ALTER TABLE addresses ADD COLUMN label text NOT NULL DEFAULT 'home'
+    CHECK (label IN ('home', 'work'));
+ALTER TABLE addresses ADD COLUMN line1 text NOT NULL DEFAULT '';
+ALTER TABLE addresses ADD COLUMN line2 text NOT NULL DEFAULT '';
+ALTER TABLE addresses ADD COLUMN city text NOT NULL DEFAULT '';
+ALTER TABLE addresses ADD COLUMN region text NOT NULL DEFAULT '';
+ALTER TABLE addresses ADD COLUMN postal_code text NOT NULL DEFAULT '';
+ALTER TABLE addresses ADD COLUMN country text NOT NULL DEFAULT 'ID';
+ALTER TABLE addresses ADD COLUMN is_default boolean NOT NULL DEFAULT false;
+
+-- alamat lama berupa text bebas, dipindah apa adanya ke line1
+UPDATE addresses SET line1 = address;
+ALTER TABLE addresses DROP COLUMN address;
+
+-- alamat paling lama setiap user menjadi default
+UPDATE addresses SET is_default = true
+WHERE id IN (SELECT MIN(id) FROM addresses WHERE deleted_at IS NULL GROUP BY user_id);
+
+CREATE UNIQUE INDEX idx_addresses_default ON addresses (user_id) WHERE is_default AND deleted_at IS NULL;
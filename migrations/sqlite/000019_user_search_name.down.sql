@@ -0,0 +1,2 @@
+DROP INDEX idx_users_search_name;
+ALTER TABLE users DROP COLUMN search_name;
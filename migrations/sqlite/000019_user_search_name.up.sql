@@ -0,0 +1,9 @@
+-- search_name berisi nama lengkap lowercase tanpa aksen, diisi hook User.
+-- SQLite tidak punya unaccent, data lama dinormalkan ulang dengan
+-- UserRepository.RebuildSearchNames
+ALTER TABLE users ADD COLUMN search_name text NOT NULL DEFAULT '';
+UPDATE users SET search_name = lower(trim(replace(
+    trim(first_name) || ' ' || trim(middle_name) || ' ' || trim(last_name), '  ', ' '
+)));
+
+CREATE INDEX idx_users_search_name ON users (search_name);
@@ -0,0 +1,14 @@
+DROP TRIGGER todos_fts_update;
+DROP TRIGGER todos_fts_delete;
+DROP TRIGGER todos_fts_insert;
+DROP TABLE todos_fts;
+
+DROP TRIGGER guest_books_fts_update;
+DROP TRIGGER guest_books_fts_delete;
+DROP TRIGGER guest_books_fts_insert;
+DROP TABLE guest_books_fts;
+
+DROP TRIGGER products_fts_update;
+DROP TRIGGER products_fts_delete;
+DROP TRIGGER products_fts_insert;
+DROP TABLE products_fts;
@@ -0,0 +1,65 @@
+-- index FTS5 external content: text tidak disalin, hanya index-nya. Trigger
+-- menjaga index tetap sama dengan table sumber.
+
+CREATE VIRTUAL TABLE products_fts USING fts5(
+    name, content='products', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+);
+INSERT INTO products_fts (rowid, name) SELECT id, name FROM products;
+
+CREATE TRIGGER products_fts_insert AFTER INSERT ON products
+BEGIN
+    INSERT INTO products_fts (rowid, name) VALUES (NEW.id, NEW.name);
+END;
+
+CREATE TRIGGER products_fts_delete AFTER DELETE ON products
+BEGIN
+    INSERT INTO products_fts (products_fts, rowid, name) VALUES ('delete', OLD.id, OLD.name);
+END;
+
+CREATE TRIGGER products_fts_update AFTER UPDATE OF name ON products
+BEGIN
+    INSERT INTO products_fts (products_fts, rowid, name) VALUES ('delete', OLD.id, OLD.name);
+    INSERT INTO products_fts (rowid, name) VALUES (NEW.id, NEW.name);
+END;
+
+CREATE VIRTUAL TABLE guest_books_fts USING fts5(
+    message, content='guest_books', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+);
+INSERT INTO guest_books_fts (rowid, message) SELECT id, message FROM guest_books;
+
+CREATE TRIGGER guest_books_fts_insert AFTER INSERT ON guest_books
+BEGIN
+    INSERT INTO guest_books_fts (rowid, message) VALUES (NEW.id, NEW.message);
+END;
+
+CREATE TRIGGER guest_books_fts_delete AFTER DELETE ON guest_books
+BEGIN
+    INSERT INTO guest_books_fts (guest_books_fts, rowid, message) VALUES ('delete', OLD.id, OLD.message);
+END;
+
+CREATE TRIGGER guest_books_fts_update AFTER UPDATE OF message ON guest_books
+BEGIN
+    INSERT INTO guest_books_fts (guest_books_fts, rowid, message) VALUES ('delete', OLD.id, OLD.message);
+    INSERT INTO guest_books_fts (rowid, message) VALUES (NEW.id, NEW.message);
+END;
+
+CREATE VIRTUAL TABLE todos_fts USING fts5(
+    task, content='todos', content_rowid='id', tokenize='unicode61 remove_diacritics 2'
+);
+INSERT INTO todos_fts (rowid, task) SELECT id, task FROM todos;
+
+CREATE TRIGGER todos_fts_insert AFTER INSERT ON todos
+BEGIN
+    INSERT INTO todos_fts (rowid, task) VALUES (NEW.id, NEW.task);
+END;
+
+CREATE TRIGGER todos_fts_delete AFTER DELETE ON todos
+BEGIN
+    INSERT INTO todos_fts (todos_fts, rowid, task) VALUES ('delete', OLD.id, OLD.task);
+END;
+
+CREATE TRIGGER todos_fts_update AFTER UPDATE OF task ON todos
+BEGIN
+    INSERT INTO todos_fts (todos_fts, rowid, task) VALUES ('delete', OLD.id, OLD.task);
+    INSERT INTO todos_fts (rowid, task) VALUES (NEW.id, NEW.task);
+END;
@@ -0,0 +1,24 @@
+DROP INDEX idx_user_logs_user_id;
+DROP INDEX idx_user_logs_entity_record;
+
+CREATE TABLE user_logs_old (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    text NOT NULL DEFAULT '',
+    action     text NOT NULL DEFAULT '',
+    created_at bigint NOT NULL DEFAULT 0,
+    updated_at bigint NOT NULL DEFAULT 0,
+    actor      text NOT NULL DEFAULT '',
+    entity     text NOT NULL DEFAULT '',
+    record_id  text NOT NULL DEFAULT '',
+    changes    text NOT NULL DEFAULT ''
+);
+
+INSERT INTO user_logs_old (id, user_id, action, created_at, updated_at, actor, entity, record_id, changes)
+SELECT id, COALESCE(user_id, ''), action, created_at, updated_at, actor, entity, record_id, changes
+FROM user_logs;
+
+DROP TABLE user_logs;
+ALTER TABLE user_logs_old RENAME TO user_logs;
+
+CREATE INDEX idx_user_logs_user_id ON user_logs (user_id);
+CREATE INDEX idx_user_logs_entity_record ON user_logs (entity, record_id);
@@ -0,0 +1,266 @@
+package belajargorm
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("belajargorm: currency mismatch")
+	ErrInvalidCurrency  = errors.New("belajargorm: invalid currency code")
+	ErrMoneyOverflow    = errors.New("belajargorm: money amount overflow")
+)
+
+// DefaultCurrency dipakai untuk data lama yang belum punya currency.
+const DefaultCurrency = "IDR"
+
+// currencyExponents berisi jumlah digit minor unit (ISO 4217) untuk currency
+// yang tidak memakai 2 digit.
+var currencyExponents = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+}
+
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+func ValidCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Money menyimpan nominal dalam minor unit (sen, dsb) beserta kode currency ISO 4217.
+// Di database hanya Amount yang disimpan ke kolom nominal (balance, price) supaya
+// SUM/AVG tetap jalan, sedangkan currency disimpan di kolom currency milik model.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+type RoundingMode int
+
+const (
+	RoundHalfUp RoundingMode = iota
+	RoundHalfEven
+	RoundDown
+	RoundUp
+)
+
+func NewMoney(amount int64, currency string) (Money, error) {
+	if !ValidCurrency(currency) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func IDR(amount int64) Money {
+	return Money{Amount: amount, Currency: "IDR"}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return m.Add(o.Neg())
+}
+
+// Cmp mengembalikan -1, 0 atau 1 seperti strings.Compare.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < o.Amount:
+		return -1, nil
+	case m.Amount > o.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// MulRat mengalikan dengan num/den, misalnya diskon 15% = MulRat(85, 100, RoundHalfUp).
+// Pembulatan ke minor unit harus disebutkan secara eksplisit.
+func (m Money) MulRat(num, den int64, mode RoundingMode) (Money, error) {
+	if den == 0 {
+		return Money{}, errors.New("belajargorm: division by zero")
+	}
+
+	n := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	d := big.NewInt(den)
+	if d.Sign() < 0 {
+		n.Neg(n)
+		d.Neg(d)
+	}
+
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() != 0 {
+		// bandingkan 2*|r| dengan d untuk tahu posisi sisa terhadap setengah
+		twice := new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2))
+		half := twice.Cmp(d)
+		awayFromZero := false
+
+		switch mode {
+		case RoundHalfUp:
+			awayFromZero = half >= 0
+		case RoundHalfEven:
+			awayFromZero = half > 0 || (half == 0 && q.Bit(0) == 1)
+		case RoundUp:
+			awayFromZero = true
+		case RoundDown:
+		}
+
+		if awayFromZero {
+			q.Add(q, big.NewInt(int64(r.Sign())))
+		}
+	}
+
+	if !q.IsInt64() {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{Amount: q.Int64(), Currency: m.Currency}, nil
+}
+
+// String memformat sesuai minor unit currency, misalnya "IDR 1000.00".
+func (m Money) String() string {
+	exp := CurrencyExponent(m.Currency)
+	if exp == 0 {
+		return fmt.Sprintf("%s %d", m.Currency, m.Amount)
+	}
+
+	sign := ""
+	amount := new(big.Int).SetInt64(m.Amount)
+	if amount.Sign() < 0 {
+		sign = "-"
+		amount.Neg(amount)
+	}
+
+	digits := amount.String()
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return fmt.Sprintf("%s %s%s.%s", m.Currency, sign, digits[:len(digits)-exp], digits[len(digits)-exp:])
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.Amount, nil
+}
+
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		m.Amount = 0
+	case int64:
+		m.Amount = v
+	case float64:
+		if v != math.Trunc(v) {
+			return fmt.Errorf("belajargorm: cannot scan fractional %v into Money", v)
+		}
+		m.Amount = int64(v)
+	case []byte:
+		return m.Scan(string(v))
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("belajargorm: cannot scan %q into Money: %w", v, err)
+		}
+		m.Amount = n
+	default:
+		return fmt.Errorf("belajargorm: cannot scan %T into Money", value)
+	}
+	return nil
+}
+
+func (Money) GormDataType() string {
+	return "bigint"
+}
+
+type moneyJSON struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Amount, Currency: m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	money, err := NewMoney(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+// syncCurrency menyamakan Money.Currency dengan kolom currency milik model
+// sebelum disimpan. Money yang jadi acuan, kalau kosong pakai kolom currency,
+// kalau dua-duanya kosong pakai DefaultCurrency.
+func syncCurrency(m *Money, currency *string) error {
+	switch {
+	case m.Currency == "" && *currency == "":
+		m.Currency = DefaultCurrency
+	case m.Currency == "":
+		m.Currency = *currency
+	}
+
+	if !ValidCurrency(m.Currency) {
+		return fmt.Errorf("%w: %q", ErrInvalidCurrency, m.Currency)
+	}
+	*currency = m.Currency
+	return nil
+}
@@ -0,0 +1,128 @@
+package belajargorm
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := IDR(1000).Add(IDR(250))
+	assert.Nil(t, err)
+	assert.Equal(t, IDR(1250), sum)
+
+	diff, err := IDR(1000).Sub(IDR(1250))
+	assert.Nil(t, err)
+	assert.Equal(t, IDR(-250), diff)
+
+	usd, err := NewMoney(500, "USD")
+	assert.Nil(t, err)
+
+	_, err = IDR(1000).Add(usd)
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = IDR(1000).Cmp(usd)
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = IDR(math.MaxInt64).Add(IDR(1))
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = NewMoney(1, "rupiah")
+	assert.ErrorIs(t, err, ErrInvalidCurrency)
+}
+
+func TestMoneyRounding(t *testing.T) {
+	cases := []struct {
+		amount int64
+		mode   RoundingMode
+		want   int64
+	}{
+		{25, RoundHalfUp, 13},
+		{25, RoundHalfEven, 12},
+		{27, RoundHalfEven, 14},
+		{25, RoundDown, 12},
+		{25, RoundUp, 13},
+		{-25, RoundHalfUp, -13},
+		{-25, RoundDown, -12},
+	}
+
+	for _, c := range cases {
+		got, err := IDR(c.amount).MulRat(1, 2, c.mode)
+		assert.Nil(t, err)
+		assert.Equal(t, c.want, got.Amount, "%d mode %d", c.amount, c.mode)
+	}
+
+	discounted, err := IDR(99999).MulRat(85, 100, RoundHalfUp)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(84999), discounted.Amount)
+}
+
+func TestMoneyFormatAndJSON(t *testing.T) {
+	assert.Equal(t, "IDR 1000.50", IDR(100050).String())
+	assert.Equal(t, "IDR -0.05", IDR(-5).String())
+	assert.Equal(t, "JPY 500", Money{Amount: 500, Currency: "JPY"}.String())
+	assert.Equal(t, "KWD 1.250", Money{Amount: 1250, Currency: "KWD"}.String())
+
+	b, err := json.Marshal(IDR(100000))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"amount":100000,"currency":"IDR"}`, string(b))
+
+	var m Money
+	assert.Nil(t, json.Unmarshal([]byte(`{"amount":42,"currency":"USD"}`), &m))
+	assert.Equal(t, Money{Amount: 42, Currency: "USD"}, m)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":42,"currency":"usd"}`), &m), ErrInvalidCurrency)
+}
+
+func TestMoneyScanValue(t *testing.T) {
+	v, err := IDR(77).Value()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(77), v)
+
+	var m Money
+	assert.Nil(t, m.Scan(int64(5)))
+	assert.Equal(t, int64(5), m.Amount)
+	assert.Nil(t, m.Scan([]byte("12")))
+	assert.Equal(t, int64(12), m.Amount)
+	assert.NotNil(t, m.Scan(1.5))
+}
+
+func TestMoneyPersisted(t *testing.T) {
+	db := beginTest(t)
+
+	usd, err := NewMoney(1999, "USD")
+	assert.Nil(t, err)
+
+	product := Product{Name: "Mug", Price: usd}
+	assert.Nil(t, db.Create(&product).Error)
+	assert.Equal(t, "USD", product.Currency)
+
+	var loaded Product
+	assert.Nil(t, db.Take(&loaded, "id = ?", product.ID).Error)
+	assert.Equal(t, usd, loaded.Price)
+
+	var wallet Wallet
+	assert.Nil(t, db.Take(&wallet, "id = ?", 1).Error)
+	assert.Equal(t, IDR(1000000), wallet.Balance)
+
+	var wallets []Wallet
+	err = db.Scopes(BalanceAtLeast(IDR(2000000))).Find(&wallets).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(wallets))
+	assert.Equal(t, "2", wallets[0].UserID)
+}
+
+func TestTransferCurrencyMismatch(t *testing.T) {
+	db := beginTest(t)
+
+	usd, err := NewMoney(5000, "USD")
+	assert.Nil(t, err)
+	err = db.Create(&Wallet{UserID: "5", Balance: usd}).Error
+	assert.Nil(t, err)
+
+	_, err = NewLedger(db).Transfer(context.Background(), "1", "5", 100, "")
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
@@ -0,0 +1,228 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrStaleObject = errors.New("belajargorm: stale object")
+
+// Version menandai kolom optimistic lock. Model yang punya field bertipe
+// Version otomatis memakai OptimisticLockPlugin.
+type Version int64
+
+// StaleObjectError dikembalikan ketika baris sudah diubah update lain sejak
+// dibaca, errors.Is(err, ErrStaleObject) bernilai true.
+type StaleObjectError struct {
+	Model   string
+	Key     any
+	Version Version
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("belajargorm: %s %v version %d was modified by another update", e.Model, e.Key, e.Version)
+}
+
+func (e *StaleObjectError) Unwrap() error {
+	return ErrStaleObject
+}
+
+/**
+*	OptimisticLockPlugin mengisi version 1 saat create, lalu setiap update
+*	model yang punya field Version menaikkan version. Kalau version yang
+*	dibaca diketahui (dari struct yang di-Save/Updates, model di
+*	db.Model(&x), atau key "version" di map), update ditambah
+*	WHERE version = ? dan StaleObjectError dikembalikan kalau tidak ada baris
+*	yang berubah. Update lewat Table() tanpa model tidak menaikkan version.
+ */
+type OptimisticLockPlugin struct{}
+
+func (OptimisticLockPlugin) Name() string {
+	return "belajargorm:optimistic_lock"
+}
+
+func (OptimisticLockPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().Before("gorm:create").Register("optimistic_lock:before_create", versionBeforeCreate); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("optimistic_lock:before_update", versionBeforeUpdate); err != nil {
+		return err
+	}
+	return callback.Update().After("gorm:update").Register("optimistic_lock:after_update", versionAfterUpdate)
+}
+
+var versionType = reflect.TypeOf(Version(0))
+
+// versionCheckKey menyimpan versionCheck di Settings statement yang sedang diupdate.
+type versionCheckKey struct{}
+
+type versionCheck struct {
+	field    *schema.Field
+	expected Version
+}
+
+func versionField(stmt *gorm.Statement) *schema.Field {
+	if stmt.Schema == nil {
+		return nil
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.FieldType == versionType && field.DBName != "" {
+			return field
+		}
+	}
+	return nil
+}
+
+func versionBeforeCreate(db *gorm.DB) {
+	field := versionField(db.Statement)
+	if db.Error != nil || field == nil {
+		return
+	}
+
+	ctx, value := db.Statement.Context, db.Statement.ReflectValue
+	setInitial := func(v reflect.Value) {
+		if _, zero := field.ValueOf(ctx, v); zero {
+			db.AddError(field.Set(ctx, v, Version(1)))
+		}
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			setInitial(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		setInitial(value)
+	}
+}
+
+// expectedVersion mencari version yang terakhir dibaca caller, 0 kalau tidak diketahui.
+func expectedVersion(stmt *gorm.Statement, field *schema.Field) Version {
+	if dest, ok := stmt.Dest.(map[string]any); ok {
+		for key, v := range dest {
+			if stmt.Schema.LookUpField(key) != field {
+				continue
+			}
+			switch v := v.(type) {
+			case Version:
+				return v
+			case int:
+				return Version(v)
+			case int64:
+				return Version(v)
+			}
+		}
+	}
+
+	for _, v := range []reflect.Value{reflect.Indirect(reflect.ValueOf(stmt.Dest)), stmt.ReflectValue} {
+		if v.Kind() != reflect.Struct || v.Type() != stmt.Schema.ModelType {
+			continue
+		}
+		if version, zero := field.ValueOf(stmt.Context, v); !zero {
+			return version.(Version)
+		}
+	}
+	return 0
+}
+
+func versionBeforeUpdate(db *gorm.DB) {
+	stmt := db.Statement
+	field := versionField(stmt)
+	if db.Error != nil || field == nil {
+		return
+	}
+	// SET yang dipasang sendiri lewat Clauses dibiarkan apa adanya
+	if _, ok := stmt.Clauses["SET"]; ok {
+		return
+	}
+
+	expected := expectedVersion(stmt, field)
+	// SET dibuat di sini (bukan di gorm:update) supaya kolom version bisa ditambahkan
+	set := callbacks.ConvertToAssignments(stmt)
+	if db.Error != nil || len(set) == 0 {
+		return
+	}
+
+	next := clause.Assignment{
+		Column: clause.Column{Name: field.DBName},
+		Value:  gorm.Expr(stmt.Quote(field.DBName) + " + 1"),
+	}
+	if expected > 0 {
+		next.Value = expected + 1
+		stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: expected},
+		}})
+	}
+
+	assignments := make(clause.Set, 0, len(set)+1)
+	for _, assignment := range set {
+		if assignment.Column.Name != field.DBName {
+			assignments = append(assignments, assignment)
+		}
+	}
+	stmt.AddClause(append(assignments, next))
+	stmt.Settings.Store(versionCheckKey{}, versionCheck{field: field, expected: expected})
+}
+
+func versionAfterUpdate(db *gorm.DB) {
+	stmt := db.Statement
+	value, ok := stmt.Settings.LoadAndDelete(versionCheckKey{})
+	if !ok {
+		return
+	}
+	delete(stmt.Clauses, "SET")
+
+	check := value.(versionCheck)
+	if db.Error != nil || check.expected == 0 {
+		return
+	}
+
+	if db.RowsAffected == 0 {
+		stale := &StaleObjectError{Model: stmt.Schema.Name, Version: check.expected}
+		if primary := stmt.Schema.PrioritizedPrimaryField; primary != nil && stmt.ReflectValue.Kind() == reflect.Struct {
+			stale.Key, _ = primary.ValueOf(stmt.Context, stmt.ReflectValue)
+		}
+		db.AddError(stale)
+		return
+	}
+
+	// struct yang dipakai caller ikut membawa version baru untuk update berikutnya
+	for _, v := range []reflect.Value{reflect.Indirect(reflect.ValueOf(stmt.Dest)), stmt.ReflectValue} {
+		if v.Kind() == reflect.Struct && v.CanAddr() && v.Type() == stmt.Schema.ModelType {
+			db.AddError(check.field.Set(stmt.Context, v, check.expected+1))
+		}
+	}
+}
+
+// UpdateWithRetry memuat ulang item id, menjalankan mutate lalu menyimpannya
+// dengan Update. Kalau gagal karena ErrStaleObject, semua langkah diulang
+// sampai attempts kali. Error dari mutate langsung dikembalikan.
+func (r *Repository[T]) UpdateWithRetry(ctx context.Context, id any, attempts int, mutate func(item *T) error) (*T, error) {
+	// version dari replica bisa tertinggal dan membuat retry selalu gagal
+	ctx = WithPrimary(ctx)
+	var err error
+	for i := 0; i < attempts || i == 0; i++ {
+		var item *T
+		if item, err = r.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		if err = mutate(item); err != nil {
+			return nil, err
+		}
+		if err = r.Update(ctx, item); err == nil {
+			return item, nil
+		}
+		if !errors.Is(err, ErrStaleObject) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
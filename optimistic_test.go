@@ -0,0 +1,131 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimisticLock(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	user := User{ID: "versi", Name: Name{FirstName: "Versi"}}
+	assert.Nil(t, store.Users.Create(ctx, &user))
+	assert.Equal(t, Version(1), user.Version)
+
+	first, err := store.Users.Get(ctx, "versi")
+	assert.Nil(t, err)
+	second, err := store.Users.Get(ctx, "versi")
+	assert.Nil(t, err)
+
+	first.Name.LastName = "Pertama"
+	assert.Nil(t, store.Users.Update(ctx, first))
+	assert.Equal(t, Version(2), first.Version)
+
+	second.Name.LastName = "Kedua"
+	err = store.Users.Update(ctx, second)
+	assert.ErrorIs(t, err, ErrStaleObject)
+	var stale *StaleObjectError
+	assert.True(t, errors.As(err, &stale))
+	assert.Equal(t, "versi", stale.Key)
+	assert.Equal(t, Version(1), stale.Version)
+
+	loaded, err := store.Users.Get(ctx, "versi")
+	assert.Nil(t, err)
+	assert.Equal(t, "Pertama", loaded.Name.LastName)
+
+	// update tanpa version yang diketahui tetap menaikkan version
+	assert.Nil(t, db.Model(&User{}).Where("id = ?", "versi").Update("middle_name", "Tengah").Error)
+	assert.Nil(t, db.Where("id = ?", "versi").Updates(&User{Name: Name{FirstName: "Baru"}}).Error)
+	loaded, err = store.Users.Get(ctx, "versi")
+	assert.Nil(t, err)
+	assert.Equal(t, Version(4), loaded.Version)
+
+	// version di map dipakai sebagai syarat
+	err = store.Users.UpdateFields(ctx, "versi", map[string]any{"last_name": "Lama", "version": 2})
+	assert.ErrorIs(t, err, ErrStaleObject)
+	assert.Nil(t, store.Users.UpdateFields(ctx, "versi", map[string]any{"last_name": "Lama", "version": 4}))
+
+	// loaded masih version 4
+	loaded.Name.FirstName = "Pilih"
+	assert.ErrorIs(t, db.Model(loaded).Select("first_name").Updates(loaded).Error, ErrStaleObject)
+
+	// Select hanya menulis kolom yang dipilih, version tetap naik
+	loaded, err = store.Users.Get(ctx, "versi")
+	assert.Nil(t, err)
+	loaded.Name.FirstName = "Pilih"
+	assert.Nil(t, db.Model(loaded).Select("first_name").Updates(loaded).Error)
+	assert.Equal(t, Version(6), loaded.Version)
+
+	product, err := store.Products.Get(ctx, productID)
+	assert.Nil(t, err)
+	assert.Nil(t, store.Products.UpdateFields(ctx, productID, map[string]any{"price": IDR(30000)}))
+	product.Name = "Kopi Basi"
+	assert.ErrorIs(t, store.Products.Update(ctx, product), ErrStaleObject)
+}
+
+func TestUpdateWithRetry(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	attempts := 0
+	product, err := store.Products.UpdateWithRetry(ctx, productID, 3, func(p *Product) error {
+		attempts++
+		if attempts == 1 {
+			// update lain masuk di antara baca dan simpan
+			if err := store.Products.UpdateFields(ctx, productID, map[string]any{"name": "Kopi Hitam"}); err != nil {
+				return err
+			}
+		}
+		p.Name += " Spesial"
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, "Kopi Hitam Spesial", product.Name)
+
+	_, err = store.Products.UpdateWithRetry(ctx, productID, 2, func(p *Product) error {
+		p.Name = "Kalah"
+		return db.Model(&Product{}).Where("id = ?", productID).Update("name", "Menang").Error
+	})
+	assert.ErrorIs(t, err, ErrStaleObject)
+
+	_, err = store.Products.UpdateWithRetry(ctx, 999, 2, func(p *Product) error { return nil })
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUpdateWithRetryConcurrent(t *testing.T) {
+	db := openMigratedDB(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	product := Product{Name: "Antre", Price: IDR(1000)}
+	assert.Nil(t, store.Products.Create(ctx, &product))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Products.UpdateWithRetry(ctx, product.ID, 50, func(p *Product) error {
+				p.Name += "!"
+				return nil
+			})
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// tidak ada update yang hilang
+	loaded, err := store.Products.Get(ctx, product.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "Antre"+strings.Repeat("!", 10), loaded.Name)
+	assert.Equal(t, Version(11), loaded.Version)
+}
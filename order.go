@@ -0,0 +1,336 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderCancelled = "cancelled"
+	OrderRefunded  = "refunded"
+)
+
+var (
+	ErrEmptyOrder             = errors.New("belajargorm: order must contain at least one item")
+	ErrInvalidOrderTransition = errors.New("belajargorm: invalid order status transition")
+	ErrAddressNotOwnedByBuyer = errors.New("belajargorm: address does not belong to the buyer")
+)
+
+/**
+*	orderTransitions berisi status tujuan yang boleh dari tiap status.
+*	cancelled berarti order batal sebelum dikirim (stock dikembalikan),
+*	refunded berarti uang dikembalikan setelah dikirim (stock tidak kembali).
+*	Keduanya mengembalikan saldo wallet kalau order sudah dibayar.
+ */
+var orderTransitions = map[string][]string{
+	OrderPending:   {OrderPaid, OrderCancelled},
+	OrderPaid:      {OrderCancelled, OrderRefunded},
+	OrderCancelled: nil,
+	OrderRefunded:  nil,
+}
+
+// Order menyimpan snapshot harga (OrderItem.UnitPrice) dan alamat pengiriman
+// saat checkout, jadi perubahan product atau address setelahnya tidak mengubah order.
+type Order struct {
+	ID                   int64       `gorm:"primary_key;column:id;autoIncrement"`
+	UserID               string      `gorm:"column:user_id" validate:"required"`
+	Status               string      `gorm:"column:status" validate:"oneof=pending paid cancelled refunded"`
+	Total                Money       `gorm:"column:total"`
+	Currency             string      `gorm:"column:currency"`
+	AddressID            *int64      `gorm:"column:address_id"`
+	ShippingAddress      string      `gorm:"column:shipping_address"`
+	PaymentTransactionID *int64      `gorm:"column:payment_transaction_id"`
+	RefundTransactionID  *int64      `gorm:"column:refund_transaction_id"`
+	PaidAt               *time.Time  `gorm:"column:paid_at"`
+	CancelledAt          *time.Time  `gorm:"column:cancelled_at"`
+	RefundedAt           *time.Time  `gorm:"column:refunded_at"`
+	CreatedAt            time.Time   `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt            time.Time   `gorm:"column:updated_at;autoUpdateTime"`
+	Items                []OrderItem `gorm:"foreignKey:order_id;references:id"`
+}
+
+func (o *Order) TableName() string {
+	return "orders"
+}
+
+func (o *Order) FilterFields() FilterFields {
+	return FilterFields{
+		Filter: []string{"id", "user_id", "status", "total", "currency", "created_at"},
+		Sort:   []string{"id", "total", "created_at"},
+	}
+}
+
+func (o *Order) BeforeSave(db *gorm.DB) error {
+	if o.Status == "" {
+		o.Status = OrderPending
+	}
+	if err := syncCurrency(&o.Total, &o.Currency); err != nil {
+		return err
+	}
+	return validateModel(db, o)
+}
+
+func (o *Order) AfterFind(db *gorm.DB) error {
+	o.Total.Currency = o.Currency
+	return nil
+}
+
+// TransitionTo memindahkan status order dan mengisi waktu perubahannya.
+func (o *Order) TransitionTo(status string, now time.Time) error {
+	next, ok := orderTransitions[o.Status]
+	if !ok {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidOrderTransition, o.Status)
+	}
+
+	allowed := false
+	for _, s := range next {
+		if s == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidOrderTransition, o.Status, status)
+	}
+
+	o.Status = status
+	switch status {
+	case OrderPaid:
+		o.PaidAt = &now
+	case OrderCancelled:
+		o.CancelledAt = &now
+	case OrderRefunded:
+		o.RefundedAt = &now
+	}
+	return nil
+}
+
+type OrderItem struct {
+	ID          int64  `gorm:"primary_key;column:id;autoIncrement"`
+	OrderID     int64  `gorm:"column:order_id"`
+	ProductID   int64  `gorm:"column:product_id"`
+	ProductName string `gorm:"column:product_name"`
+	UnitPrice   Money  `gorm:"column:unit_price"`
+	Currency    string `gorm:"column:currency"`
+	Quantity    int64  `gorm:"column:quantity" validate:"min=1"`
+	Subtotal    Money  `gorm:"column:subtotal"`
+}
+
+func (o *OrderItem) TableName() string {
+	return "order_items"
+}
+
+func (o *OrderItem) BeforeSave(db *gorm.DB) error {
+	return validateModel(db, o)
+}
+
+func (o *OrderItem) AfterFind(db *gorm.DB) error {
+	o.UnitPrice.Currency = o.Currency
+	o.Subtotal.Currency = o.Currency
+	return nil
+}
+
+type CheckoutItem struct {
+	ProductID int64
+	Quantity  int64
+}
+
+// mergeCheckoutItems menggabungkan product yang sama dan mengurutkan per product
+// id, supaya lock product selalu diambil dengan urutan yang sama.
+func mergeCheckoutItems(items []CheckoutItem) ([]CheckoutItem, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyOrder
+	}
+
+	quantities := map[int64]int64{}
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: product %d", ErrInvalidQuantity, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	merged := make([]CheckoutItem, 0, len(quantities))
+	for id, quantity := range quantities {
+		merged = append(merged, CheckoutItem{ProductID: id, Quantity: quantity})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].ProductID < merged[j].ProductID })
+	return merged, nil
+}
+
+type OrderRepository struct {
+	*Repository[Order]
+}
+
+/**
+*	Checkout membuat order dan membayarnya dari wallet user dalam satu
+*	transaksi: wallet di-lock, product di-lock berurutan per id, saldo dicek
+*	lalu didebit lewat ledger, stock dikurangi, dan harga serta alamat disalin
+*	ke order. Kalau satu langkah gagal tidak ada yang berubah.
+ */
+func (r *OrderRepository) Checkout(ctx context.Context, userID string, items []CheckoutItem, addressID int64) (*Order, error) {
+	items, err := mergeCheckoutItems(items)
+	if err != nil {
+		return nil, err
+	}
+
+	var order *Order
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := NewRepository[User](tx).Get(ctx, userID, Select("id")); err != nil {
+			return err
+		}
+		wallet, err := lockBuyerWallet(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		address, err := NewRepository[Address](tx).Get(ctx, addressID)
+		if err != nil {
+			return err
+		}
+		if address.UserID != userID {
+			return fmt.Errorf("%w: address %d", ErrAddressNotOwnedByBuyer, addressID)
+		}
+
+		order = &Order{
+			UserID:          userID,
+			Status:          OrderPending,
+			Total:           Money{Currency: wallet.Currency},
+			AddressID:       &address.ID,
+			ShippingAddress: address.String(),
+		}
+
+		products := &ProductRepository{NewRepository[Product](tx)}
+		for _, item := range items {
+			product, err := products.Get(ctx, item.ProductID, lockForUpdate)
+			if err != nil {
+				return err
+			}
+			if product.Currency != wallet.Currency {
+				return fmt.Errorf("%w: product %d is %s, wallet is %s", ErrCurrencyMismatch, product.ID, product.Currency, wallet.Currency)
+			}
+
+			subtotal, err := product.Price.MulRat(item.Quantity, 1, RoundHalfUp)
+			if err != nil {
+				return err
+			}
+			if order.Total, err = order.Total.Add(subtotal); err != nil {
+				return err
+			}
+			order.Items = append(order.Items, OrderItem{
+				ProductID:   product.ID,
+				ProductName: product.Name,
+				UnitPrice:   product.Price,
+				Currency:    product.Currency,
+				Quantity:    item.Quantity,
+				Subtotal:    subtotal,
+			})
+		}
+
+		if wallet.Balance.Amount < order.Total.Amount {
+			return ErrInsufficientFunds
+		}
+		for _, item := range items {
+			if err := products.Sell(ctx, item.ProductID, item.Quantity); err != nil {
+				return fmt.Errorf("product %d: %w", item.ProductID, err)
+			}
+		}
+
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		if order.Total.Amount > 0 {
+			entry, err := postEntry(tx, wallet, nil, EntryDebit, order.Total.Amount, fmt.Sprintf("payment order %d", order.ID))
+			if err != nil {
+				return err
+			}
+			order.PaymentTransactionID = &entry.ID
+		}
+		if err := order.TransitionTo(OrderPaid, time.Now()); err != nil {
+			return err
+		}
+		return tx.Select("status", "paid_at", "payment_transaction_id", "updated_at").Updates(order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Cancel membatalkan order pending atau paid: stock dikembalikan dan saldo
+// dikembalikan ke wallet kalau sudah dibayar.
+func (r *OrderRepository) Cancel(ctx context.Context, id int64) (*Order, error) {
+	return r.settle(ctx, id, OrderCancelled)
+}
+
+// Refund mengembalikan saldo order yang sudah dibayar tanpa mengembalikan stock.
+func (r *OrderRepository) Refund(ctx context.Context, id int64) (*Order, error) {
+	return r.settle(ctx, id, OrderRefunded)
+}
+
+func (r *OrderRepository) settle(ctx context.Context, id int64, status string) (*Order, error) {
+	var order *Order
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = NewRepository[Order](tx).Get(ctx, id, Preload("Items"), lockForUpdate)
+		if err != nil {
+			return err
+		}
+
+		paid := order.Status == OrderPaid
+		if err := order.TransitionTo(status, time.Now()); err != nil {
+			return err
+		}
+
+		if paid && order.Total.Amount > 0 {
+			wallet, err := lockBuyerWallet(tx, order.UserID)
+			if err != nil {
+				return err
+			}
+			entry, err := postEntry(tx, wallet, nil, EntryCredit, order.Total.Amount, fmt.Sprintf("%s order %d", status, order.ID))
+			if err != nil {
+				return err
+			}
+			order.RefundTransactionID = &entry.ID
+		}
+
+		if status == OrderCancelled {
+			for _, item := range order.Items {
+				// lewat Table supaya stock product yang sudah dihapus tetap kembali
+				err := tx.Table("products").Where("id = ?", item.ProductID).UpdateColumns(map[string]any{
+					"stock":      gorm.Expr("stock + ?", item.Quantity),
+					"updated_at": time.Now(),
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Select("status", "cancelled_at", "refunded_at", "refund_transaction_id", "updated_at").Updates(order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// lockBuyerWallet seperti lockWallets untuk satu user, wallet yang tidak ada
+// dikembalikan sebagai NotFoundError.
+func lockBuyerWallet(tx *gorm.DB, userID string) (*Wallet, error) {
+	wallets, err := lockWallets(tx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &NotFoundError{Model: "Wallet", Key: "user " + userID}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return wallets[userID], nil
+}
@@ -0,0 +1,207 @@
+package belajargorm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func walletBalance(t *testing.T, store *Store, userID string) int64 {
+	t.Helper()
+	wallet, err := store.Wallets.GetByUserID(context.Background(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wallet.Balance.Amount
+}
+
+func TestCheckout(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	assert.Nil(t, store.Products.Restock(ctx, productID, 10))
+	roti := Product{Name: "Roti Bakar", Price: IDR(10000), Stock: 5}
+	assert.Nil(t, store.Products.Create(ctx, &roti))
+
+	order, err := store.Orders.Checkout(ctx, "1", []CheckoutItem{
+		{ProductID: productID, Quantity: 2},
+		{ProductID: roti.ID, Quantity: 1},
+		{ProductID: productID, Quantity: 1},
+	}, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, OrderPaid, order.Status)
+	assert.Equal(t, IDR(85000), order.Total)
+	assert.Equal(t, "Jalan Merdeka 1, Jakarta, 10110 ID", order.ShippingAddress)
+	assert.NotNil(t, order.PaidAt)
+	assert.NotNil(t, order.PaymentTransactionID)
+	assert.Equal(t, int64(915000), walletBalance(t, store, "1"))
+	assert.Nil(t, NewLedger(db).Reconcile(ctx, 1))
+
+	kopi, err := store.Products.Get(ctx, productID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), kopi.Stock)
+
+	// perubahan harga dan alamat setelah checkout tidak mengubah order
+	kopi.Price = IDR(30000)
+	assert.Nil(t, store.Products.Update(ctx, kopi))
+	assert.Nil(t, store.Addresses.UpdateFields(ctx, int64(1), map[string]any{"line1": "Jalan Baru 9"}))
+
+	loaded, err := store.Orders.Get(ctx, order.ID, Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("product_name")
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, "Jalan Merdeka 1, Jakarta, 10110 ID", loaded.ShippingAddress)
+	assert.Equal(t, 2, len(loaded.Items))
+	assert.Equal(t, "Kopi Susu", loaded.Items[0].ProductName)
+	assert.Equal(t, IDR(25000), loaded.Items[0].UnitPrice)
+	assert.Equal(t, int64(3), loaded.Items[0].Quantity)
+	assert.Equal(t, IDR(75000), loaded.Items[0].Subtotal)
+}
+
+func TestCheckoutFailures(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	assert.Nil(t, store.Products.Restock(ctx, productID, 2))
+	address := Address{UserID: "3", Line1: "Jalan Kosong 3"}
+	assert.Nil(t, store.Addresses.Create(ctx, &address))
+
+	one := []CheckoutItem{{ProductID: productID, Quantity: 1}}
+
+	_, err := store.Orders.Checkout(ctx, "3", one, address.ID)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.Orders.Checkout(ctx, "1", []CheckoutItem{{ProductID: productID, Quantity: 3}}, 1)
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+
+	_, err = store.Orders.Checkout(ctx, "1", one, address.ID)
+	assert.ErrorIs(t, err, ErrAddressNotOwnedByBuyer)
+
+	_, err = store.Orders.Checkout(ctx, "1", nil, 1)
+	assert.ErrorIs(t, err, ErrEmptyOrder)
+
+	_, err = store.Orders.Checkout(ctx, "1", []CheckoutItem{{ProductID: productID, Quantity: 0}}, 1)
+	assert.ErrorIs(t, err, ErrInvalidQuantity)
+
+	_, err = store.Orders.Checkout(ctx, "1", []CheckoutItem{{ProductID: 12345, Quantity: 1}}, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// user 4 tidak punya wallet
+	_, err = store.Orders.Checkout(ctx, "4", one, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	usd := Product{Name: "Imported Coffee", Price: Money{Amount: 500, Currency: "USD"}, Stock: 1}
+	assert.Nil(t, store.Products.Create(ctx, &usd))
+	_, err = store.Orders.Checkout(ctx, "1", []CheckoutItem{{ProductID: usd.ID, Quantity: 1}}, 1)
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	// checkout yang gagal tidak mengubah apa pun
+	assert.Equal(t, int64(1000000), walletBalance(t, store, "1"))
+	kopi, err := store.Products.Get(ctx, productID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), kopi.Stock)
+	count, err := store.Orders.Count(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestOrderCancelAndRefund(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	assert.Nil(t, store.Products.Restock(ctx, productID, 5))
+	items := []CheckoutItem{{ProductID: productID, Quantity: 2}}
+
+	first, err := store.Orders.Checkout(ctx, "1", items, 1)
+	assert.Nil(t, err)
+	second, err := store.Orders.Checkout(ctx, "1", items, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(900000), walletBalance(t, store, "1"))
+
+	cancelled, err := store.Orders.Cancel(ctx, first.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, OrderCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.CancelledAt)
+	assert.NotNil(t, cancelled.RefundTransactionID)
+	assert.Equal(t, int64(950000), walletBalance(t, store, "1"))
+
+	kopi, err := store.Products.Get(ctx, productID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), kopi.Stock)
+
+	refunded, err := store.Orders.Refund(ctx, second.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, OrderRefunded, refunded.Status)
+	assert.Equal(t, int64(1000000), walletBalance(t, store, "1"))
+
+	// refund tidak mengembalikan stock
+	kopi, err = store.Products.Get(ctx, productID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), kopi.Stock)
+
+	_, err = store.Orders.Cancel(ctx, first.ID)
+	assert.ErrorIs(t, err, ErrInvalidOrderTransition)
+	_, err = store.Orders.Refund(ctx, first.ID)
+	assert.ErrorIs(t, err, ErrInvalidOrderTransition)
+	_, err = store.Orders.Cancel(ctx, 999)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Nil(t, NewLedger(db).Reconcile(ctx, 1))
+}
+
+func TestOrderTransition(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	order := Order{Status: OrderPending}
+	assert.ErrorIs(t, order.TransitionTo(OrderRefunded, now), ErrInvalidOrderTransition)
+	assert.Nil(t, order.TransitionTo(OrderCancelled, now))
+	assert.Equal(t, now, *order.CancelledAt)
+	assert.ErrorIs(t, order.TransitionTo(OrderPaid, now), ErrInvalidOrderTransition)
+}
+
+func TestCheckoutConcurrent(t *testing.T) {
+	db := openMigratedDB(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	user := User{ID: "buyer", Name: Name{FirstName: "Buyer"}, Wallet: Wallet{Balance: IDR(1000000)}}
+	assert.Nil(t, db.Create(&user).Error)
+	address := Address{UserID: user.ID, Line1: "Jalan Pembeli 1"}
+	assert.Nil(t, db.Create(&address).Error)
+	product := Product{Name: "Edisi Terbatas", Price: IDR(10000), Stock: 5}
+	assert.Nil(t, db.Create(&product).Error)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		paid     int
+		rejected int
+	)
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Orders.Checkout(ctx, user.ID, []CheckoutItem{{ProductID: product.ID, Quantity: 1}}, address.ID)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				paid++
+			case assert.ErrorIs(t, err, ErrInsufficientStock):
+				rejected++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, paid)
+	assert.Equal(t, 7, rejected)
+	assert.Equal(t, int64(950000), walletBalance(t, store, user.ID))
+}
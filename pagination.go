@@ -0,0 +1,441 @@
+package belajargorm
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("belajargorm: invalid page cursor")
+	ErrInvalidSort   = errors.New("belajargorm: invalid sort field")
+)
+
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+/**
+*	PageRequest memilih mode pagination:
+*	- Cursor kosong dan Offset 0: halaman pertama
+*	- Cursor terisi: keyset, lanjut dari baris yang ada di cursor (Offset diabaikan)
+*	- Offset > 0 tanpa Cursor: offset biasa
+*	Primary key selalu ditambahkan di akhir Sort supaya urutan stabil walaupun
+*	ada nilai yang sama.
+ */
+type PageRequest struct {
+	Limit     int
+	Offset    int
+	Cursor    string
+	Sort      []SortField
+	WithTotal bool
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+type cursorDirection string
+
+const (
+	cursorNext cursorDirection = "next"
+	cursorPrev cursorDirection = "prev"
+)
+
+// pageCursor adalah isi cursor sebelum di-encode base64. Sort ikut disimpan
+// supaya cursor tidak dipakai dengan urutan yang berbeda.
+type pageCursor struct {
+	Direction cursorDirection   `json:"d"`
+	Sort      string            `json:"s"`
+	Values    []json.RawMessage `json:"v"`
+}
+
+// sortColumn adalah kolom urutan. Untuk kolom association ("Wallet.balance")
+// relation terisi dan table berisi nama association yang di-join. nullable
+// untuk field pointer dan kolom association (LEFT JOIN bisa kosong).
+type sortColumn struct {
+	field    *schema.Field
+	desc     bool
+	table    string
+	relation *schema.Relationship
+	nullable bool
+}
+
+func (c sortColumn) column() clause.Column {
+	return clause.Column{Table: c.table, Name: c.field.DBName}
+}
+
+func (c sortColumn) name() string {
+	if c.relation != nil {
+		return c.relation.Name + "." + c.field.DBName
+	}
+	return c.field.DBName
+}
+
+// value mengambil nilai kolom dari item hasil query. Pointer nil dan
+// association yang tidak ada (hasil LEFT JOIN kosong) bernilai nil.
+func (c sortColumn) value(ctx context.Context, rv reflect.Value) any {
+	if c.relation != nil {
+		rv = reflect.Indirect(c.relation.Field.ReflectValueOf(ctx, rv))
+		if !rv.IsValid() {
+			return nil
+		}
+		if pk := c.relation.FieldSchema.PrioritizedPrimaryField; pk != nil {
+			if _, zero := pk.ValueOf(ctx, rv); zero {
+				return nil
+			}
+		}
+	}
+	value, _ := c.field.ValueOf(ctx, rv)
+	if c.field.FieldType.Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil() {
+		return nil
+	}
+	// Money, Version dan tipe lain dengan driver.Valuer disimpan sebagai nilai
+	// database-nya, karena Money dari association yang di-join tidak membawa currency
+	if valuer, ok := value.(driver.Valuer); ok {
+		if v, err := valuer.Value(); err == nil {
+			switch v.(type) {
+			case int64, float64, bool, string:
+				return v
+			}
+		}
+	}
+	return value
+}
+
+func (p PageRequest) limit() int {
+	switch {
+	case p.Limit <= 0:
+		return DefaultPageSize
+	case p.Limit > MaxPageSize:
+		return MaxPageSize
+	default:
+		return p.Limit
+	}
+}
+
+// Paginate menjalankan query db (boleh sudah berisi Where/Joins) untuk model T
+// dan mengembalikan satu halaman.
+func Paginate[T any](ctx context.Context, db *gorm.DB, req PageRequest) (*Page[T], error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+
+	columns, err := sortColumns(stmt.Schema, req.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// association yang dipakai untuk sort di-join kalau belum di-join filter
+	for _, c := range columns {
+		if c.relation != nil && !joined(db, c.relation.Name) {
+			db = db.Joins(c.relation.Name)
+		}
+	}
+
+	base := db.WithContext(ctx).Model(new(T)).Session(&gorm.Session{})
+	page := &Page[T]{Limit: req.limit()}
+
+	if req.WithTotal {
+		var total int64
+		if err := base.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	direction := cursorNext
+	query := base
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor, columns)
+		if err != nil {
+			return nil, err
+		}
+		direction = cursor.Direction
+		values, err := cursorValues(cursor, columns)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(keysetCondition(columns, values, direction))
+	} else if req.Offset > 0 {
+		page.Offset = req.Offset
+		query = query.Offset(req.Offset)
+	}
+
+	var items []T
+	err = query.Clauses(orderBy(columns, direction == cursorPrev)).Limit(page.Limit + 1).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(items) > page.Limit
+	if more {
+		items = items[:page.Limit]
+	}
+	if direction == cursorPrev {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	page.Items = items
+
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	hasNext, hasPrev := more, req.Cursor != "" || req.Offset > 0
+	if direction == cursorPrev {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		if page.NextCursor, err = encodeCursor(ctx, cursorNext, columns, &items[len(items)-1]); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if page.PrevCursor, err = encodeCursor(ctx, cursorPrev, columns, &items[0]); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func (r *Repository[T]) Page(ctx context.Context, req PageRequest, opts ...QueryOption) (*Page[T], error) {
+	return Paginate[T](ctx, r.query(ctx, opts), req)
+}
+
+func joined(db *gorm.DB, name string) bool {
+	for _, join := range db.Statement.Joins {
+		if join.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortColumns mencari kolom untuk setiap SortField. Kolom association ditulis
+// "Association.kolom" dan hanya boleh dari relasi has one / belongs to, sama
+// seperti filter, supaya join tidak menggandakan baris.
+func sortColumns(s *schema.Schema, sort []SortField) ([]sortColumn, error) {
+	columns := make([]sortColumn, 0, len(sort)+1)
+	seen := map[string]bool{}
+	for _, sf := range sort {
+		c := sortColumn{desc: sf.Desc, table: clause.CurrentTable}
+		fieldSchema, fieldName := s, sf.Column
+		if rel, column, ok := strings.Cut(sf.Column, "."); ok {
+			relation := s.Relationships.Relations[rel]
+			if relation == nil || (relation.Type != schema.HasOne && relation.Type != schema.BelongsTo) {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidSort, sf.Column)
+			}
+			c.relation, c.table = relation, relation.Name
+			fieldSchema, fieldName = relation.FieldSchema, column
+		}
+
+		c.field = fieldSchema.LookUpField(fieldName)
+		if c.field == nil || c.field.DBName == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSort, sf.Column)
+		}
+		if seen[c.name()] {
+			continue
+		}
+		seen[c.name()] = true
+		c.nullable = c.relation != nil || c.field.FieldType.Kind() == reflect.Ptr
+		columns = append(columns, c)
+	}
+
+	pk := s.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, fmt.Errorf("%w: %s has no primary key", ErrInvalidSort, s.Name)
+	}
+	if !seen[pk.DBName] {
+		columns = append(columns, sortColumn{field: pk, table: clause.CurrentTable})
+	}
+	return columns, nil
+}
+
+func sortSignature(columns []sortColumn) string {
+	parts := make([]string, len(columns))
+	for i, c := range columns {
+		parts[i] = c.name()
+		if c.desc {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// orderBy selalu menaruh NULL di akhir urutan (NULLS LAST) untuk asc maupun
+// desc. Ditulis sebagai "kolom IS NULL" karena SQLite lama tidak mengenal
+// NULLS LAST. reverse membalik seluruh urutan, termasuk posisi NULL.
+func orderBy(columns []sortColumn, reverse bool) clause.OrderBy {
+	exprs := make([]string, 0, len(columns))
+	vars := make([]any, 0, len(columns))
+	for _, c := range columns {
+		if c.nullable {
+			exprs = append(exprs, "? IS NULL"+sortDirection(reverse))
+			vars = append(vars, c.column())
+		}
+		exprs = append(exprs, "?"+sortDirection(c.desc != reverse))
+		vars = append(vars, c.column())
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(exprs, ", "), Vars: vars}}
+}
+
+func sortDirection(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return ""
+}
+
+/**
+*	keysetCondition membuat kondisi "setelah baris cursor" untuk urutan campuran
+*	asc/desc, misalnya sort (a asc, b desc, id asc):
+*	a > ? OR (a = ? AND b < ?) OR (a = ? AND b = ? AND id > ?)
+*	Untuk kolom nullable, NULL berada di akhir urutan:
+*	- maju dari nilai v: a > v OR a IS NULL; dari NULL: tidak ada yang lebih besar
+*	- mundur dari nilai v: a < v; dari NULL: a IS NOT NULL
+*	dan a = NULL ditulis a IS NULL.
+ */
+func keysetCondition(columns []sortColumn, values []any, direction cursorDirection) clause.Expression {
+	ors := make([]clause.Expression, 0, len(columns))
+	for i, c := range columns {
+		ands := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			// clause.Eq dengan nilai nil ditulis IS NULL
+			ands = append(ands, clause.Eq{Column: columns[j].column(), Value: values[j]})
+		}
+
+		column := c.column()
+		var cmp clause.Expression
+		switch {
+		case values[i] == nil && direction == cursorNext:
+			continue
+		case values[i] == nil:
+			cmp = clause.Neq{Column: column, Value: nil}
+		case c.desc == (direction == cursorPrev):
+			cmp = clause.Gt{Column: column, Value: values[i]}
+		default:
+			cmp = clause.Lt{Column: column, Value: values[i]}
+		}
+		if c.nullable && values[i] != nil && direction == cursorNext {
+			cmp = clause.Or(cmp, clause.Eq{Column: column, Value: nil})
+		}
+		ors = append(ors, clause.And(append(ands, cmp)...))
+	}
+	if len(ors) == 0 {
+		// cursor di baris terakhir yang semua kolomnya NULL
+		return clause.Expr{SQL: "1 = 0"}
+	}
+	return clause.Or(ors...)
+}
+
+func encodeCursor(ctx context.Context, direction cursorDirection, columns []sortColumn, item any) (string, error) {
+	rv := reflect.ValueOf(item).Elem()
+	cursor := pageCursor{Direction: direction, Sort: sortSignature(columns)}
+	for _, c := range columns {
+		b, err := json.Marshal(c.value(ctx, rv))
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, b)
+	}
+
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string, columns []sortColumn) (pageCursor, error) {
+	var cursor pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if cursor.Direction != cursorNext && cursor.Direction != cursorPrev {
+		return cursor, ErrInvalidCursor
+	}
+	if cursor.Sort != sortSignature(columns) || len(cursor.Values) != len(columns) {
+		return cursor, fmt.Errorf("%w: cursor was created for a different sort", ErrInvalidCursor)
+	}
+	return cursor, nil
+}
+
+// cursorValues mengembalikan nilai cursor ke tipe field aslinya (time.Time, Money,
+// dll) supaya driver mengirim parameter dengan format yang sama seperti saat insert.
+func cursorValues(cursor pageCursor, columns []sortColumn) ([]any, error) {
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		if string(cursor.Values[i]) == "null" {
+			if !c.nullable {
+				return nil, ErrInvalidCursor
+			}
+			continue
+		}
+		ptr := reflect.New(c.field.FieldType)
+		if scanCursorValue(cursor.Values[i], ptr.Interface()) {
+			values[i] = ptr.Elem().Interface()
+			continue
+		}
+		if err := json.Unmarshal(cursor.Values[i], ptr.Interface()); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values[i] = ptr.Elem().Interface()
+	}
+	return values, nil
+}
+
+// scanCursorValue mengisi dst lewat sql.Scanner dari nilai database yang
+// disimpan sortColumn.value.
+func scanCursorValue(raw json.RawMessage, dst any) bool {
+	scanner, ok := dst.(sql.Scanner)
+	if !ok {
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var v any
+	if decoder.Decode(&v) != nil || v == nil {
+		return false
+	}
+	switch n := v.(type) {
+	case json.Number:
+		if v, err := n.Int64(); err == nil {
+			return scanner.Scan(v) == nil
+		}
+		f, err := n.Float64()
+		return err == nil && scanner.Scan(f) == nil
+	case string, bool:
+		return scanner.Scan(n) == nil
+	}
+	return false
+}
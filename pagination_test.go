@@ -0,0 +1,208 @@
+package belajargorm
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func userIDs(users []User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+func TestPaginateOffset(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+
+	page, err := Paginate[User](ctx, db, PageRequest{
+		Limit:     5,
+		Offset:    5,
+		Sort:      []SortField{{Column: "id"}},
+		WithTotal: true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"14", "2", "3", "4", "5"}, userIDs(page.Items))
+	assert.Equal(t, int64(14), *page.Total)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.NotEmpty(t, page.PrevCursor)
+
+	// cursor dari halaman offset bisa dipakai lanjut dengan keyset
+	next, err := Paginate[User](ctx, db, PageRequest{Limit: 5, Cursor: page.NextCursor, Sort: []SortField{{Column: "id"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"6", "7", "8", "9"}, userIDs(next.Items))
+	assert.Empty(t, next.NextCursor)
+}
+
+func TestPaginateKeyset(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+
+	// beberapa user dengan middle_name sama untuk menguji nilai kembar
+	err := db.Model(&User{}).Where("id IN ?", []string{"3", "7", "11"}).Update("middle_name", "Kembar").Error
+	assert.Nil(t, err)
+	for _, id := range []string{"500", "501", "502"} {
+		assert.Nil(t, db.Create(&User{ID: id, Name: Name{FirstName: "User 1", MiddleName: "Kembar"}}).Error)
+	}
+
+	sorts := map[string][]SortField{
+		"mixed":      {{Column: "middle_name", Desc: true}, {Column: "first_name"}},
+		"created_at": {{Column: "created_at", Desc: true}},
+		"struct":     {{Column: "FirstName", Desc: true}, {Column: "id", Desc: true}},
+	}
+
+	for name, sort := range sorts {
+		t.Run(name, func(t *testing.T) {
+			var expected []User
+			err := db.Clauses(orderBy(mustSortColumns(t, sort), false)).Find(&expected).Error
+			assert.Nil(t, err)
+
+			var pages [][]string
+			var walked []string
+			req := PageRequest{Limit: 4, Sort: sort}
+			for {
+				page, err := Paginate[User](ctx, db, req)
+				assert.Nil(t, err)
+				pages = append(pages, userIDs(page.Items))
+				walked = append(walked, userIDs(page.Items)...)
+				if page.NextCursor == "" {
+					break
+				}
+				req.Cursor = page.NextCursor
+			}
+			assert.Equal(t, userIDs(expected), walked)
+			assert.Equal(t, 5, len(pages))
+
+			// jalan mundur dari halaman terakhir dengan prev cursor
+			page, err := Paginate[User](ctx, db, req)
+			assert.Nil(t, err)
+			for i := len(pages) - 2; i >= 0; i-- {
+				assert.NotEmpty(t, page.PrevCursor)
+				page, err = Paginate[User](ctx, db, PageRequest{Limit: 4, Sort: sort, Cursor: page.PrevCursor})
+				assert.Nil(t, err)
+				assert.Equal(t, pages[i], userIDs(page.Items))
+			}
+			assert.Empty(t, page.PrevCursor)
+		})
+	}
+}
+
+func TestPaginateNulls(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	// hanya dua todo yang punya due_at, sisanya NULL
+	due := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	var ids []int
+	for i, dueAt := range []*time.Time{nil, &due, nil, nil, ptr(due.Add(time.Hour))} {
+		todo := Todo{UserID: "9", Task: "Null " + strconv.Itoa(i), DueAt: dueAt}
+		assert.Nil(t, db.Create(&todo).Error)
+		ids = append(ids, todo.ID)
+	}
+
+	todoIDs := func(todos []Todo) []int {
+		result := make([]int, len(todos))
+		for i, todo := range todos {
+			result[i] = todo.ID
+		}
+		return result
+	}
+
+	// NULL selalu di akhir, baik asc maupun desc
+	expected := map[bool][]int{
+		false: {ids[1], ids[4], ids[0], ids[2], ids[3]},
+		true:  {ids[4], ids[1], ids[0], ids[2], ids[3]},
+	}
+	for desc, want := range expected {
+		sort := []SortField{{Column: "due_at", Desc: desc}}
+		req := PageRequest{Limit: 2, Sort: sort}
+		var walked []int
+		var page *Page[Todo]
+		for {
+			var err error
+			page, err = store.Todos.Page(ctx, req, Where("user_id = ?", "9"))
+			assert.Nil(t, err)
+			walked = append(walked, todoIDs(page.Items)...)
+			if page.NextCursor == "" {
+				break
+			}
+			req.Cursor = page.NextCursor
+		}
+		assert.Equal(t, want, walked, "desc=%v", desc)
+
+		var back []int
+		for page.PrevCursor != "" {
+			var err error
+			page, err = store.Todos.Page(ctx, PageRequest{Limit: 2, Sort: sort, Cursor: page.PrevCursor}, Where("user_id = ?", "9"))
+			assert.Nil(t, err)
+			back = append(todoIDs(page.Items), back...)
+		}
+		assert.Equal(t, want[:4], back, "desc=%v", desc)
+	}
+
+	// kolom association dari LEFT JOIN juga bisa NULL (user tanpa wallet)
+	req := PageRequest{Limit: 4, Sort: []SortField{{Column: "Wallet.balance"}}}
+	var walked []string
+	for {
+		page, err := Paginate[User](ctx, db, req)
+		assert.Nil(t, err)
+		walked = append(walked, userIDs(page.Items)...)
+		if page.NextCursor == "" {
+			break
+		}
+		req.Cursor = page.NextCursor
+	}
+	assert.Equal(t, 14, len(walked))
+	assert.Equal(t, []string{"3", "1", "2"}, walked[:3])
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func mustSortColumns(t *testing.T, sort []SortField) []sortColumn {
+	t.Helper()
+
+	stmt := &gorm.Statement{DB: testDB}
+	assert.Nil(t, stmt.Parse(&User{}))
+	columns, err := sortColumns(stmt.Schema, sort)
+	assert.Nil(t, err)
+	return columns
+}
+
+func TestPaginateInvalid(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+
+	_, err := Paginate[User](ctx, db, PageRequest{Sort: []SortField{{Column: "password; DROP TABLE users"}}})
+	assert.ErrorIs(t, err, ErrInvalidSort)
+
+	_, err = Paginate[User](ctx, db, PageRequest{Cursor: "bukan-cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	page, err := Paginate[User](ctx, db, PageRequest{Limit: 2, Sort: []SortField{{Column: "first_name"}}})
+	assert.Nil(t, err)
+	_, err = Paginate[User](ctx, db, PageRequest{Limit: 2, Cursor: page.NextCursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestRepositoryPage(t *testing.T) {
+	store := NewStore(beginTest(t))
+
+	page, err := store.Users.Page(context.Background(),
+		PageRequest{Limit: 3, WithTotal: true},
+		Where("first_name LIKE ?", "User 1%"),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10", "11", "12"}, userIDs(page.Items))
+	assert.Equal(t, int64(5), *page.Total)
+	assert.Equal(t, DefaultPageSize, PageRequest{}.limit())
+}
@@ -0,0 +1,111 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCredentials = errors.New("belajargorm: invalid user id or password")
+
+// PasswordCost adalah cost bcrypt untuk hash baru. Kalau dinaikkan, hash lama
+// akan di-rehash otomatis saat user berhasil login lewat Authenticate.
+var PasswordCost = bcrypt.DefaultCost
+
+var dummyHashes sync.Map // cost bcrypt -> []byte
+
+// dummyHash dipakai ketika user tidak ditemukan supaya waktu respon Authenticate
+// sama dengan ketika password salah. Hash dibuat dengan PasswordCost yang
+// sedang berlaku, jadi tetap sama walaupun cost-nya dinaikkan.
+func dummyHash() []byte {
+	cost := PasswordCost
+	if hash, ok := dummyHashes.Load(cost); ok {
+		return hash.([]byte)
+	}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("belajar-gorm"), cost)
+	dummyHashes.Store(cost, hash)
+	return hash
+}
+
+// isPasswordHash hanya dipakai untuk menolak input yang berbentuk hash bcrypt,
+// bukan untuk menentukan apakah password perlu di-hash.
+func isPasswordHash(s string) bool {
+	_, err := bcrypt.Cost([]byte(s))
+	return err == nil
+}
+
+// HashPassword meng-hash password plain text. Password kosong dikembalikan apa
+// adanya. Input selalu dianggap plain text walaupun bentuknya seperti hash bcrypt.
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return password, nil
+	}
+
+	b, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// VerifyPassword membandingkan password dengan hash milik user secara constant time.
+func (u *User) VerifyPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) == nil
+}
+
+func (u *User) NeedsRehash() bool {
+	cost, err := bcrypt.Cost([]byte(u.Password))
+	return err != nil || cost < PasswordCost
+}
+
+// Authenticate mencari user berdasarkan id lalu mencocokkan password. Kalau cocok
+// dan hash-nya dibuat dengan cost yang lebih rendah dari PasswordCost, hash-nya
+// diperbarui.
+func Authenticate(ctx context.Context, db *gorm.DB, userID string, password string) (*User, error) {
+	var user User
+	err := db.WithContext(ctx).Take(&user, "id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.VerifyPassword(password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.NeedsRehash() {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost)
+		if err != nil {
+			return nil, err
+		}
+
+		err = db.WithContext(ctx).Model(&user).UpdateColumn("password", string(hashed)).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+func hashPasswordMap(values map[string]interface{}) error {
+	for _, key := range []string{"password", "Password"} {
+		plain, ok := values[key].(string)
+		if !ok {
+			continue
+		}
+
+		hashed, err := HashPassword(plain)
+		if err != nil {
+			return err
+		}
+		values[key] = hashed
+	}
+	return nil
+}
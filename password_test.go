@@ -0,0 +1,133 @@
+package belajargorm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHashedOnCreate(t *testing.T) {
+	db := beginTest(t)
+
+	user := User{ID: "200", Password: "rahasia", Name: Name{FirstName: "Hash"}}
+	err := db.Create(&user).Error
+	assert.Nil(t, err)
+
+	var stored User
+	err = db.Take(&stored, "id = ?", "200").Error
+	assert.Nil(t, err)
+	assert.NotEqual(t, "rahasia", stored.Password)
+	assert.True(t, stored.VerifyPassword("rahasia"))
+	assert.False(t, stored.VerifyPassword("salah"))
+
+	// Save ulang user yang sudah di-load tidak boleh meng-hash hash-nya lagi
+	stored.Name.LastName = "Ulang"
+	err = db.Save(&stored).Error
+	assert.Nil(t, err)
+
+	var reloaded User
+	err = db.Take(&reloaded, "id = ?", "200").Error
+	assert.Nil(t, err)
+	assert.Equal(t, stored.Password, reloaded.Password)
+	assert.True(t, reloaded.VerifyPassword("rahasia"))
+}
+
+func TestPasswordShapedLikeHash(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+
+	// input yang bentuknya seperti hash bcrypt tetap di-hash dengan PasswordCost
+	plain := "$2a$04$" + strings.Repeat("x", 53)
+	user := User{ID: "201", Password: plain, Name: Name{FirstName: "Hash"}}
+	assert.Nil(t, db.Create(&user).Error)
+	assert.NotEqual(t, plain, user.Password)
+	cost, err := bcrypt.Cost([]byte(user.Password))
+	assert.Nil(t, err)
+	assert.Equal(t, PasswordCost, cost)
+
+	_, err = Authenticate(ctx, db, "201", plain)
+	assert.Nil(t, err)
+
+	// hash milik user lain yang disalin ke password juga dianggap plain text
+	var other User
+	assert.Nil(t, db.Take(&other, "id = ?", "6").Error)
+	assert.Nil(t, db.Model(&User{}).Where("id = ?", "201").Update("password", other.Password).Error)
+	_, err = Authenticate(ctx, db, "201", "rahasia")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = Authenticate(ctx, db, "201", other.Password)
+	assert.Nil(t, err)
+}
+
+func TestDummyHashCost(t *testing.T) {
+	oldCost := PasswordCost
+	PasswordCost = bcrypt.MinCost + 1
+	t.Cleanup(func() { PasswordCost = oldCost })
+
+	cost, err := bcrypt.Cost(dummyHash())
+	assert.Nil(t, err)
+	assert.Equal(t, bcrypt.MinCost+1, cost)
+}
+
+func TestPasswordHashedOnUpdate(t *testing.T) {
+	db := beginTest(t)
+
+	err := db.Model(&User{}).Where("id = ?", "3").Update("password", "baru").Error
+	assert.Nil(t, err)
+
+	err = db.Model(&User{}).Where("id = ?", "4").Updates(map[string]interface{}{"password": "baru"}).Error
+	assert.Nil(t, err)
+
+	err = db.Model(&User{}).Where("id = ?", "5").Updates(User{Password: "baru"}).Error
+	assert.Nil(t, err)
+
+	var users []User
+	err = db.Find(&users, "id IN ?", []string{"3", "4", "5"}).Error
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(users))
+
+	for _, u := range users {
+		assert.NotEqual(t, "baru", u.Password)
+		assert.True(t, u.VerifyPassword("baru"), u.ID)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+
+	user, err := Authenticate(ctx, db, "6", "rahasia")
+	assert.Nil(t, err)
+	assert.Equal(t, "6", user.ID)
+
+	_, err = Authenticate(ctx, db, "6", "salah")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = Authenticate(ctx, db, "tidak-ada", "rahasia")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestAuthenticateRehash(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+
+	oldCost := PasswordCost
+	PasswordCost = bcrypt.MinCost + 1
+	t.Cleanup(func() { PasswordCost = oldCost })
+
+	user, err := Authenticate(ctx, db, "6", "rahasia")
+	assert.Nil(t, err)
+	assert.False(t, user.NeedsRehash())
+
+	var stored User
+	err = db.Take(&stored, "id = ?", "6").Error
+	assert.Nil(t, err)
+
+	cost, err := bcrypt.Cost([]byte(stored.Password))
+	assert.Nil(t, err)
+	assert.Equal(t, bcrypt.MinCost+1, cost)
+	assert.False(t, stored.NeedsRehash())
+	assert.True(t, stored.VerifyPassword("rahasia"))
+}
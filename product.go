@@ -1,16 +1,61 @@
 package belajargorm
 
-import "time"
+import (
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// Stock hanya boleh diisi saat create (stock awal), setelah itu berubah lewat
+// ProductRepository.Restock, Reserve, Release dan Fulfill. Setiap perubahan
+// Price tercatat di product_price_history, lihat ProductRepository.PriceAt.
 type Product struct {
-	ID           int64     `gorm:"primary_key;column:id"`
-	Name         string    `gorm:"column:name"`
-	Price        int64     `gorm:"column:price"`
-	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
-	LikedByUsers []User    `gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:product_id;references:id;joinReferences:user_id"`
+	ID           int64          `gorm:"primary_key;column:id;autoIncrement:false"`
+	Name         string         `gorm:"column:name" validate:"required,max=200"`
+	Price        Money          `gorm:"column:price" validate:"min=1"`
+	Currency     string         `gorm:"column:currency"`
+	CategoryID   *int64         `gorm:"column:category_id"`
+	Stock        int64          `gorm:"column:stock;<-:create" validate:"min=0"`
+	Reserved     int64          `gorm:"column:reserved;->"`
+	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at"`
+	Version      Version        `gorm:"column:version"`
+	Category     *Category      `gorm:"foreignKey:category_id;references:id"`
+	LikedByUsers []User         `gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:product_id;references:id;joinReferences:user_id"`
 }
 
 func (p *Product) TableName() string {
 	return "products"
 }
+
+func (p *Product) FilterFields() FilterFields {
+	return FilterFields{
+		Filter: []string{"id", "name", "price", "currency", "category_id", "stock", "created_at"},
+		Sort:   []string{"id", "name", "price", "stock", "created_at"},
+	}
+}
+
+func (p *Product) BeforeCreate(db *gorm.DB) error {
+	if p.ID == 0 {
+		p.ID = ProductIDGenerator.NewInt64()
+	}
+	return nil
+}
+
+func (p *Product) BeforeSave(db *gorm.DB) error {
+	if err := syncCurrency(&p.Price, &p.Currency); err != nil {
+		return err
+	}
+	return validateModel(db, p)
+}
+
+func (p *Product) AfterFind(db *gorm.DB) error {
+	p.Price.Currency = p.Currency
+	return nil
+}
+
+// Available adalah stock yang belum di-Reserve.
+func (p *Product) Available() int64 {
+	return p.Stock - p.Reserved
+}
@@ -0,0 +1,129 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+const replicaPluginName = "belajargorm:replica"
+
+type primaryKey struct{}
+
+// WithPrimary memaksa semua query dengan ctx ini dibaca dari primary. Dipakai
+// tepat setelah menulis supaya data yang baru ditulis pasti terbaca walaupun
+// replica belum menerima perubahannya (read-your-writes).
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func primaryRequested(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
+
+/**
+*	ReplicaPlugin membagi query ke primary dan read replica. Query baca
+*	(Find, First, Count, Preload, Raw SELECT) bergantian dikirim ke Replicas,
+*	kecuali:
+*	- di dalam transaksi (db.Transaction, Begin), karena transaksi selalu
+*	  dibuka di primary
+*	- memakai clause.Locking (FOR UPDATE/SHARE)
+*	- context dari WithPrimary
+*	Create, Update, Delete dan Exec selalu ke primary. Open memasang plugin
+*	ini kalau Config.Replicas terisi.
+ */
+type ReplicaPlugin struct {
+	Replicas []gorm.ConnPool
+
+	primary gorm.ConnPool
+	next    atomic.Uint64
+}
+
+func NewReplicaPlugin(replicas ...gorm.ConnPool) *ReplicaPlugin {
+	return &ReplicaPlugin{Replicas: replicas}
+}
+
+func (p *ReplicaPlugin) Name() string {
+	return replicaPluginName
+}
+
+func (p *ReplicaPlugin) Initialize(db *gorm.DB) error {
+	p.primary = db.ConnPool
+	callback := db.Callback()
+
+	if err := callback.Query().Before("gorm:query").Register("replica:query", p.routeRead); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("replica:row", p.routeRead); err != nil {
+		return err
+	}
+	// statement yang sama bisa dipakai untuk baca lalu tulis, jadi koneksinya
+	// dikembalikan ke primary sebelum transaksi tulis dibuka
+	if err := callback.Create().Before("gorm:begin_transaction").Register("replica:create", p.routeWrite); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:begin_transaction").Register("replica:update", p.routeWrite); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:begin_transaction").Register("replica:delete", p.routeWrite); err != nil {
+		return err
+	}
+	return callback.Raw().Before("gorm:raw").Register("replica:raw", p.routeWrite)
+}
+
+// inTransaction bernilai true kalau statement memakai koneksi transaksi (*sql.Tx).
+func inTransaction(pool gorm.ConnPool) bool {
+	_, ok := pool.(gorm.TxCommitter)
+	return ok
+}
+
+func (p *ReplicaPlugin) routeRead(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || len(p.Replicas) == 0 || inTransaction(stmt.ConnPool) {
+		return
+	}
+
+	_, locking := stmt.Clauses["FOR"]
+	// Raw selain SELECT (misalnya UPDATE ... RETURNING lewat Scan) harus ke primary
+	raw := strings.TrimSpace(stmt.SQL.String())
+	write := raw != "" && !strings.EqualFold(firstWord(raw), "select")
+	if locking || write || primaryRequested(stmt.Context) {
+		stmt.ConnPool = p.primary
+		return
+	}
+
+	i := p.next.Add(1) - 1
+	stmt.ConnPool = p.Replicas[i%uint64(len(p.Replicas))]
+}
+
+func (p *ReplicaPlugin) routeWrite(db *gorm.DB) {
+	if !inTransaction(db.Statement.ConnPool) {
+		db.Statement.ConnPool = p.primary
+	}
+}
+
+func firstWord(sql string) string {
+	if i := strings.IndexFunc(sql, func(r rune) bool { return r == ' ' || r == '\n' || r == '\t' || r == '(' }); i >= 0 {
+		return sql[:i]
+	}
+	return sql
+}
+
+// Close menutup koneksi semua replica. Koneksi primary tetap ditutup lewat db.DB().
+func (p *ReplicaPlugin) Close() error {
+	var errs []error
+	for _, replica := range p.Replicas {
+		if closer, ok := replica.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,105 @@
+package belajargorm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// seedReplicaDB membuat database sqlite ter-migrate berisi user "rw" yang
+// FirstName dan kota alamatnya sama dengan name, supaya terlihat query dibaca
+// dari file yang mana.
+func seedReplicaDB(t *testing.T, cfg Config, name string) {
+	t.Helper()
+
+	db, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Close(db)
+
+	migrator, err := NewSchemaMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewStore(db)
+	ctx := context.Background()
+	user := User{ID: "rw", Password: "rahasia", Name: Name{FirstName: name}}
+	if err := store.Users.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Addresses.Create(ctx, &Address{UserID: "rw", Line1: "Jalan " + name, City: name}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplicaRouting(t *testing.T) {
+	dir := t.TempDir()
+	dsn := func(name string) string {
+		return filepath.Join(dir, name+".db") + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+	}
+
+	cfg := DefaultConfig()
+	cfg.Driver = DriverSQLite
+	cfg.LogLevel = "silent"
+	cfg.SQLitePath = dsn("primary")
+	seedReplicaDB(t, cfg, "primary")
+	cfg.SQLitePath = dsn("replica")
+	seedReplicaDB(t, cfg, "replica")
+
+	cfg.SQLitePath = dsn("primary")
+	cfg.Replicas = []string{dsn("replica")}
+	db, err := Open(cfg)
+	assert.Nil(t, err)
+	t.Cleanup(func() { Close(db) })
+
+	ctx := context.Background()
+	store := NewStore(db)
+	firstName := func(db *gorm.DB) string {
+		var user User
+		assert.Nil(t, db.First(&user, "id = ?", "rw").Error)
+		return user.Name.FirstName
+	}
+
+	// baca ke replica, termasuk preload dan Raw SELECT
+	user, err := store.Users.Get(ctx, "rw", Preload("Addresses"))
+	assert.Nil(t, err)
+	assert.Equal(t, "replica", user.Name.FirstName)
+	assert.Equal(t, "replica", user.Addresses[0].City)
+	var name string
+	assert.Nil(t, db.Raw("SELECT first_name FROM users WHERE id = ?", "rw").Scan(&name).Error)
+	assert.Equal(t, "replica", name)
+
+	// primary dipaksa lewat context, transaksi dan locking
+	assert.Equal(t, "primary", firstName(db.WithContext(WithPrimary(ctx))))
+	assert.Equal(t, "primary", firstName(db.Clauses(clause.Locking{Strength: "UPDATE"})))
+	assert.Nil(t, db.Transaction(func(tx *gorm.DB) error {
+		assert.Equal(t, "primary", firstName(tx))
+		return nil
+	}))
+
+	// tulis selalu ke primary; replica di test tidak ikut tersinkron
+	assert.Nil(t, store.Users.UpdateFields(ctx, "rw", map[string]any{"last_name": "Baru"}))
+	assert.Nil(t, db.Exec("UPDATE addresses SET city = ? WHERE user_id = ?", "Bandung", "rw").Error)
+	user, err = store.Users.Get(WithPrimary(ctx), "rw", Preload("Addresses"))
+	assert.Nil(t, err)
+	assert.Equal(t, "Baru", user.Name.LastName)
+	assert.Equal(t, "Bandung", user.Addresses[0].City)
+	user, err = store.Users.Get(ctx, "rw")
+	assert.Nil(t, err)
+	assert.Equal(t, "", user.Name.LastName)
+
+	// request yang menulis lewat REST API membaca dari primary: version 2
+	// hasil UpdateFields di atas belum ada di replica
+	rec := doRequest(t, NewServer(store), "PUT", "/users/rw", map[string]any{"name": map[string]any{"first_name": "Utama"}})
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"version":3`)
+}
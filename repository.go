@@ -0,0 +1,360 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNotFound = errors.New("belajargorm: record not found")
+
+// NotFoundError dikembalikan repository ketika data tidak ada,
+// errors.Is(err, ErrNotFound) bernilai true.
+type NotFoundError struct {
+	Model string
+	Key   any
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Key == nil {
+		return fmt.Sprintf("belajargorm: %s not found", e.Model)
+	}
+	return fmt.Sprintf("belajargorm: %s %v not found", e.Model, e.Key)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// QueryOption mengubah query List/Count/Exists tanpa perlu memegang *gorm.DB.
+type QueryOption func(db *gorm.DB) *gorm.DB
+
+func Where(query any, args ...any) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(query, args...)
+	}
+}
+
+func OrderBy(order string) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(order)
+	}
+}
+
+func Limit(n int) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Limit(n)
+	}
+}
+
+func Offset(n int) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset(n)
+	}
+}
+
+func Select(columns ...string) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select(columns)
+	}
+}
+
+var lockForUpdate QueryOption = func(db *gorm.DB) *gorm.DB {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+func Preload(association string, args ...any) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Preload(association, args...)
+	}
+}
+
+type Repository[T any] struct {
+	db    *gorm.DB
+	model string
+}
+
+func NewRepository[T any](db *gorm.DB) *Repository[T] {
+	return &Repository[T]{
+		db:    db,
+		model: reflect.TypeOf((*T)(nil)).Elem().Name(),
+	}
+}
+
+func (r *Repository[T]) query(ctx context.Context, opts []QueryOption) *gorm.DB {
+	db := r.db.WithContext(ctx).Model(new(T))
+	for _, opt := range opts {
+		db = opt(db)
+	}
+	return db
+}
+
+func (r *Repository[T]) notFound(err error, key any) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &NotFoundError{Model: r.model, Key: key}
+	}
+	return err
+}
+
+func (r *Repository[T]) Get(ctx context.Context, id any, opts ...QueryOption) (*T, error) {
+	var item T
+	err := r.query(ctx, opts).Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).Take(&item).Error
+	if err != nil {
+		return nil, r.notFound(err, id)
+	}
+	return &item, nil
+}
+
+// First mengambil satu data pertama yang cocok dengan opts.
+func (r *Repository[T]) First(ctx context.Context, opts ...QueryOption) (*T, error) {
+	var item T
+	if err := r.query(ctx, opts).Take(&item).Error; err != nil {
+		return nil, r.notFound(err, nil)
+	}
+	return &item, nil
+}
+
+func (r *Repository[T]) List(ctx context.Context, opts ...QueryOption) ([]T, error) {
+	var items []T
+	err := r.query(ctx, opts).Find(&items).Error
+	return items, err
+}
+
+func (r *Repository[T]) Create(ctx context.Context, item *T) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+// Update menyimpan semua kolom item (Save), item harus sudah ada.
+func (r *Repository[T]) Update(ctx context.Context, item *T) error {
+	return r.db.WithContext(ctx).Save(item).Error
+}
+
+// UpdateFields hanya mengubah kolom yang ada di fields.
+func (r *Repository[T]) UpdateFields(ctx context.Context, id any, fields map[string]any) error {
+	res := r.db.WithContext(ctx).Model(new(T)).
+		Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).
+		Updates(fields)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return &NotFoundError{Model: r.model, Key: id}
+	}
+	return nil
+}
+
+func (r *Repository[T]) Delete(ctx context.Context, id any) error {
+	res := r.db.WithContext(ctx).Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).Delete(new(T))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return &NotFoundError{Model: r.model, Key: id}
+	}
+	return nil
+}
+
+func (r *Repository[T]) Exists(ctx context.Context, opts ...QueryOption) (bool, error) {
+	var found int
+	err := r.query(ctx, opts).Select("1").Limit(1).Find(&found).Error
+	return found == 1, err
+}
+
+func (r *Repository[T]) Count(ctx context.Context, opts ...QueryOption) (int64, error) {
+	var count int64
+	err := r.query(ctx, opts).Count(&count).Error
+	return count, err
+}
+
+// likePrefix meng-escape karakter wildcard LIKE supaya input user dicari apa adanya.
+func likePrefix(prefix string) string {
+	return escapeLike(prefix) + "%"
+}
+
+type UserRepository struct {
+	*Repository[User]
+}
+
+func (r *UserRepository) FindUsersByFirstNamePrefix(ctx context.Context, prefix string) ([]User, error) {
+	return r.List(ctx, Where(`first_name LIKE ? ESCAPE '\'`, likePrefix(prefix)), OrderBy("id"))
+}
+
+// LikeProduct mencatat user menyukai product, memanggil ulang tidak menambah baris.
+func (r *UserRepository) LikeProduct(ctx context.Context, userID string, productID int64) error {
+	_, err := NewLikes(r.db).Like(ctx, userID, productID)
+	return err
+}
+
+func (r *UserRepository) UnlikeProduct(ctx context.Context, userID string, productID int64) error {
+	_, err := NewLikes(r.db).Unlike(ctx, userID, productID)
+	return err
+}
+
+func (r *UserRepository) GetWithWallet(ctx context.Context, id string) (*User, error) {
+	return r.Get(ctx, id, Preload("Wallet"))
+}
+
+type WalletRepository struct {
+	*Repository[Wallet]
+}
+
+func (r *WalletRepository) GetByUserID(ctx context.Context, userID string) (*Wallet, error) {
+	wallet, err := r.First(ctx, Where("user_id = ?", userID), OrderBy("id"))
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		notFound.Key = "user " + userID
+	}
+	return wallet, err
+}
+
+func (r *WalletRepository) ListBalanceAtLeast(ctx context.Context, balance Money) ([]Wallet, error) {
+	return r.List(ctx, QueryOption(BalanceAtLeast(balance)), OrderBy("id"))
+}
+
+type AddressRepository struct {
+	*Repository[Address]
+}
+
+type ProductRepository struct {
+	*Repository[Product]
+}
+
+func (r *ProductRepository) ListLikedByUser(ctx context.Context, userID string) ([]Product, error) {
+	return r.List(ctx,
+		Where("id IN (?)", r.db.Table("user_like_product").Select("product_id").Where("user_id = ?", userID)),
+		OrderBy("id"),
+	)
+}
+
+func (r *ProductRepository) FindByNamePrefix(ctx context.Context, prefix string) ([]Product, error) {
+	return r.List(ctx, Where(`name LIKE ? ESCAPE '\'`, likePrefix(prefix)), OrderBy("name"))
+}
+
+type TodoRepository struct {
+	*Repository[Todo]
+}
+
+func (r *TodoRepository) ListTodosForUser(ctx context.Context, userID string) ([]Todo, error) {
+	return r.List(ctx, Where("user_id = ?", userID), OrderBy("id"))
+}
+
+func (r *TodoRepository) ListOverdue(ctx context.Context, userID string, now time.Time) ([]Todo, error) {
+	return r.List(ctx, Where("user_id = ?", userID), QueryOption(Overdue(now)), OrderBy("due_at, id"))
+}
+
+func (r *TodoRepository) ListDueSoon(ctx context.Context, userID string, now time.Time, within time.Duration) ([]Todo, error) {
+	return r.List(ctx, Where("user_id = ?", userID), QueryOption(DueWithin(now, within)), OrderBy("due_at, id"))
+}
+
+func (r *TodoRepository) ListByTag(ctx context.Context, userID string, tag string) ([]Todo, error) {
+	return r.List(ctx, Where("user_id = ?", userID), QueryOption(WithTag(tag)), Preload("Tags"), OrderBy("id"))
+}
+
+// SetStatus memindahkan status todo sesuai workflow, lihat Todo.TransitionTo.
+func (r *TodoRepository) SetStatus(ctx context.Context, id int, status string) (*Todo, error) {
+	var todo *Todo
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		todo, err = NewRepository[Todo](tx).Get(ctx, id, lockForUpdate)
+		if err != nil {
+			return err
+		}
+		if err := todo.TransitionTo(status, time.Now()); err != nil {
+			return err
+		}
+		return tx.Select("status", "completed_at", "updated_at").Updates(todo).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// SetTags mengganti semua tag todo, tag yang belum ada dibuat otomatis.
+func (r *TodoRepository) SetTags(ctx context.Context, id int, names []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := NewRepository[Todo](tx).Get(ctx, id, Select("id")); err != nil {
+			return err
+		}
+
+		tags, err := ensureTags(tx, names)
+		if err != nil {
+			return err
+		}
+
+		tagIDs := make([]int64, len(tags))
+		rows := make([]map[string]any, len(tags))
+		for i, tag := range tags {
+			tagIDs[i] = tag.ID
+			rows[i] = map[string]any{"todo_id": id, "tag_id": tag.ID}
+		}
+
+		remove := tx.Where("todo_id = ?", id)
+		if len(tagIDs) > 0 {
+			remove = remove.Where("tag_id NOT IN ?", tagIDs)
+		}
+		if err := remove.Delete(&todoTag{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Model(&todoTag{}).Clauses(clause.OnConflict{DoNothing: true}).Create(rows).Error
+	})
+}
+
+type GuestBookRepository struct {
+	*Repository[GuestBook]
+	// SpamFilter dipakai Submit, nil berarti DefaultSpamFilter.
+	SpamFilter *SpamFilter
+}
+
+func (r *GuestBookRepository) ListByEmail(ctx context.Context, email string) ([]GuestBook, error) {
+	return r.List(ctx, Where("email = ?", email), OrderBy("id"))
+}
+
+func (r *GuestBookRepository) ListRecent(ctx context.Context, limit int) ([]GuestBook, error) {
+	return r.List(ctx, OrderBy("created_at DESC, id DESC"), Limit(limit))
+}
+
+// Store mengumpulkan semua repository yang memakai koneksi (atau transaksi) yang sama.
+type Store struct {
+	db         *gorm.DB
+	Users      *UserRepository
+	Wallets    *WalletRepository
+	Addresses  *AddressRepository
+	Products   *ProductRepository
+	Categories *CategoryRepository
+	Orders     *OrderRepository
+	Todos      *TodoRepository
+	GuestBooks *GuestBookRepository
+	Likes      *Likes
+}
+
+func NewStore(db *gorm.DB) *Store {
+	return &Store{
+		db:         db,
+		Users:      &UserRepository{NewRepository[User](db)},
+		Wallets:    &WalletRepository{NewRepository[Wallet](db)},
+		Addresses:  &AddressRepository{NewRepository[Address](db)},
+		Products:   &ProductRepository{NewRepository[Product](db)},
+		Categories: &CategoryRepository{NewRepository[Category](db)},
+		Orders:     &OrderRepository{NewRepository[Order](db)},
+		Todos:      &TodoRepository{NewRepository[Todo](db)},
+		GuestBooks: &GuestBookRepository{Repository: NewRepository[GuestBook](db)},
+		Likes:      NewLikes(db),
+	}
+}
+
+// Transaction menjalankan fn dengan Store yang semua repository-nya ada di dalam
+// satu transaksi. Kalau fn mengembalikan error, semua perubahan di-rollback.
+func (s *Store) Transaction(ctx context.Context, fn func(tx *Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewStore(tx))
+	})
+}
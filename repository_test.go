@@ -0,0 +1,141 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryCRUD(t *testing.T) {
+	store := NewStore(beginTest(t))
+	ctx := context.Background()
+
+	user := User{ID: "300", Password: "rahasia", Name: Name{FirstName: "Repo"}}
+	assert.Nil(t, store.Users.Create(ctx, &user))
+
+	got, err := store.Users.Get(ctx, "300")
+	assert.Nil(t, err)
+	assert.Equal(t, "Repo", got.Name.FirstName)
+
+	got.Name.LastName = "Sitory"
+	assert.Nil(t, store.Users.Update(ctx, got))
+
+	err = store.Users.UpdateFields(ctx, "300", map[string]any{"middle_name": "Generik"})
+	assert.Nil(t, err)
+
+	got, err = store.Users.Get(ctx, "300")
+	assert.Nil(t, err)
+	assert.Equal(t, "Sitory", got.Name.LastName)
+	assert.Equal(t, "Generik", got.Name.MiddleName)
+
+	exists, err := store.Users.Exists(ctx, Where("id = ?", "300"))
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	_, err = store.Users.Delete(ctx, "300")
+	assert.Nil(t, err)
+
+	exists, err = store.Users.Exists(ctx, Where("id = ?", "300"))
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+func TestRepositoryNotFound(t *testing.T) {
+	store := NewStore(beginTest(t))
+	ctx := context.Background()
+
+	_, err := store.Users.Get(ctx, "tidak-ada")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var notFound *NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "User", notFound.Model)
+	assert.Equal(t, "tidak-ada", notFound.Key)
+
+	assert.ErrorIs(t, store.Products.Delete(ctx, int64(1)), ErrNotFound)
+	assert.ErrorIs(t, store.Todos.UpdateFields(ctx, 999, map[string]any{"task": "x"}), ErrNotFound)
+
+	_, err = store.Wallets.GetByUserID(ctx, "14")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRepositoryListAndCount(t *testing.T) {
+	store := NewStore(beginTest(t))
+	ctx := context.Background()
+
+	users, err := store.Users.List(ctx, OrderBy("id"), Limit(3), Offset(1))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10", "11", "12"}, []string{users[0].ID, users[1].ID, users[2].ID})
+
+	count, err := store.Users.Count(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(14), count)
+
+	count, err = store.Users.Count(ctx, Where("first_name LIKE ?", "User%"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(13), count)
+}
+
+func TestRepositoryDomainQueries(t *testing.T) {
+	store := NewStore(beginTest(t))
+	ctx := context.Background()
+
+	users, err := store.Users.FindUsersByFirstNamePrefix(ctx, "User 1")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(users))
+
+	// wildcard dari input tidak boleh ikut dianggap wildcard
+	users, err = store.Users.FindUsersByFirstNamePrefix(ctx, "%")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(users))
+
+	addresses, err := store.Addresses.ListAddressesForUser(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(addresses))
+
+	wallet, err := store.Wallets.GetByUserID(ctx, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, IDR(2500000), wallet.Balance)
+
+	wallets, err := store.Wallets.ListBalanceAtLeast(ctx, IDR(1000000))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(wallets))
+
+	products, err := store.Products.ListLikedByUser(ctx, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(products))
+	assert.Equal(t, productID, products[0].ID)
+
+	user, err := store.Users.GetWithWallet(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), user.Wallet.ID)
+
+	assert.Nil(t, store.GuestBooks.Create(ctx, &GuestBook{Name: "Tamu", Email: "tamu@example.com", Message: "Halo"}))
+	entries, err := store.GuestBooks.ListByEmail(ctx, "tamu@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+
+	assert.Nil(t, store.Todos.Create(ctx, &Todo{UserID: "1", Task: "Belajar generic"}))
+	todos, err := store.Todos.ListTodosForUser(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(todos))
+}
+
+func TestStoreTransactionRollback(t *testing.T) {
+	store := NewStore(beginTest(t))
+	ctx := context.Background()
+
+	errBatal := errors.New("batal")
+	err := store.Transaction(ctx, func(tx *Store) error {
+		if err := tx.Users.Create(ctx, &User{ID: "301", Name: Name{FirstName: "Batal"}}); err != nil {
+			return err
+		}
+		return errBatal
+	})
+	assert.ErrorIs(t, err, errBatal)
+
+	_, err = store.Users.Get(ctx, "301")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,155 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	SearchProducts   = "products"
+	SearchGuestBooks = "guest_books"
+	SearchTodos      = "todos"
+)
+
+var ErrInvalidSearchType = errors.New("belajargorm: invalid search type")
+
+// SearchTypes adalah semua type yang dicari Search kalau types kosong.
+var SearchTypes = []string{SearchProducts, SearchGuestBooks, SearchTodos}
+
+/**
+*	searchSource menjelaskan kolom yang diindex untuk satu type. Di Postgres
+*	index-nya kolom generated search_vector (GIN), di SQLite virtual table
+*	FTS5 <table>_fts, keduanya dibuat migration 000020. visible membatasi
+*	baris yang boleh muncul di hasil pencarian.
+ */
+type searchSource struct {
+	table   string
+	column  string
+	visible string
+}
+
+var searchSources = map[string]searchSource{
+	SearchProducts:   {table: "products", column: "name", visible: "products.deleted_at IS NULL"},
+	SearchGuestBooks: {table: "guest_books", column: "message", visible: "guest_books.deleted_at IS NULL AND guest_books.status = 'approved'"},
+	SearchTodos:      {table: "todos", column: "task", visible: "todos.deleted_at = 0"},
+}
+
+// SearchHit adalah satu hasil pencarian. Highlight sudah di-escape untuk HTML,
+// kata yang cocok dibungkus <mark>. Rank yang lebih besar lebih relevan.
+type SearchHit struct {
+	ID        int64   `json:"id"`
+	Highlight string  `json:"highlight"`
+	Rank      float64 `json:"rank"`
+}
+
+// penanda highlight dari database, diganti <mark> setelah text di-escape
+const (
+	markStart = "\x02"
+	markEnd   = "\x03"
+)
+
+func searchWords(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchQuery mengubah query user menjadi query full-text: setiap kata wajib
+// ada dan boleh berupa awalan kata, misalnya "kop sus" cocok dengan "Kopi Susu".
+func searchQuery(dialect string, words []string) string {
+	terms := make([]string, len(words))
+	for i, word := range words {
+		if dialect == DriverPostgres {
+			terms[i] = word + ":*"
+		} else {
+			terms[i] = `"` + word + `"*`
+		}
+	}
+	if dialect == DriverPostgres {
+		return strings.Join(terms, " & ")
+	}
+	return strings.Join(terms, " ")
+}
+
+func highlight(text string) string {
+	text = html.EscapeString(text)
+	return strings.NewReplacer(markStart, "<mark>", markEnd, "</mark>").Replace(text)
+}
+
+/**
+*	Search mencari query di nama product, pesan guest book (yang approved)
+*	dan task todo, lalu mengembalikan hasil per type yang diurutkan dari yang
+*	paling relevan. types kosong berarti semua SearchTypes. Pagination memakai
+*	Limit dan Offset page, berlaku untuk setiap type; Cursor dan Sort tidak
+*	didukung karena urutan ditentukan relevansi.
+ */
+func (s *Store) Search(ctx context.Context, query string, types []string, page PageRequest) (map[string]*Page[SearchHit], error) {
+	if len(types) == 0 {
+		types = SearchTypes
+	}
+	for _, t := range types {
+		if _, ok := searchSources[t]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSearchType, t)
+		}
+	}
+	if page.Cursor != "" || len(page.Sort) > 0 {
+		return nil, fmt.Errorf("%w: search only supports limit and offset", ErrInvalidCursor)
+	}
+
+	words := searchWords(query)
+	results := make(map[string]*Page[SearchHit], len(types))
+	for _, t := range types {
+		result := &Page[SearchHit]{Items: []SearchHit{}, Limit: page.limit(), Offset: page.Offset}
+		if len(words) > 0 {
+			if err := s.searchSource(ctx, searchSources[t], words, page, result); err != nil {
+				return nil, err
+			}
+		} else if page.WithTotal {
+			result.Total = new(int64)
+		}
+		results[t] = result
+	}
+	return results, nil
+}
+
+func (s *Store) searchSource(ctx context.Context, source searchSource, words []string, page PageRequest, result *Page[SearchHit]) error {
+	db := s.db.WithContext(ctx)
+	dialect := db.Dialector.Name()
+	match := searchQuery(dialect, words)
+
+	var from, selects string
+	var args []any
+	if dialect == DriverPostgres {
+		from = fmt.Sprintf("%s, to_tsquery('simple', ?) AS q WHERE %[1]s.search_vector @@ q AND %s", source.table, source.visible)
+		selects = fmt.Sprintf("%s.id, ts_headline('simple', %[1]s.%s, q, ?) AS highlight, ts_rank(%[1]s.search_vector, q) AS rank", source.table, source.column)
+		args = []any{"StartSel=" + markStart + ", StopSel=" + markEnd}
+	} else {
+		fts := source.table + "_fts"
+		from = fmt.Sprintf("%s JOIN %s ON %[2]s.id = %[1]s.rowid WHERE %[1]s MATCH ? AND %[3]s", fts, source.table, source.visible)
+		selects = fmt.Sprintf("%s.id, snippet(%s, 0, ?, ?, '…', 32) AS highlight, -bm25(%[2]s) AS rank", source.table, fts)
+		args = []any{markStart, markEnd}
+	}
+
+	if page.WithTotal {
+		var total int64
+		if err := db.Raw("SELECT count(*) FROM "+from, match).Scan(&total).Error; err != nil {
+			return err
+		}
+		result.Total = &total
+	}
+
+	args = append(args, match, page.limit(), page.Offset)
+	err := db.Raw("SELECT "+selects+" FROM "+from+" ORDER BY rank DESC, id LIMIT ? OFFSET ?", args...).
+		Scan(&result.Items).Error
+	if err != nil {
+		return err
+	}
+	for i := range result.Items {
+		result.Items[i].Highlight = highlight(result.Items[i].Highlight)
+	}
+	return nil
+}
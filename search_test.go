@@ -0,0 +1,88 @@
+package belajargorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	db := beginTest(t)
+	ctx := WithActor(context.Background(), "moderator")
+	store := NewStore(db)
+
+	latte := Product{Name: "Kopi Latte <Panas>", Price: IDR(30000)}
+	aren := Product{Name: "Es Kopi Susu Gula Arèn", Price: IDR(22000)}
+	teh := Product{Name: "Teh Tarik", Price: IDR(15000)}
+	for _, product := range []*Product{&latte, &aren, &teh} {
+		assert.Nil(t, store.Products.Create(ctx, product))
+	}
+
+	approved := GuestBook{Name: "Tamu", Email: "tamu@example.com", Message: "Kopinya enak, kopi susu terbaik!"}
+	pending := GuestBook{Name: "Anon", Email: "anon@example.com", Message: "Kopi apa ini"}
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &approved))
+	assert.Nil(t, store.GuestBooks.Submit(ctx, &pending))
+	_, err := store.GuestBooks.Approve(ctx, []uint{approved.ID}, "")
+	assert.Nil(t, err)
+
+	todo := Todo{UserID: "1", Task: "Beli biji kopi"}
+	assert.Nil(t, store.Todos.Create(ctx, &todo))
+
+	results, err := store.Search(ctx, "kopi", nil, PageRequest{WithTotal: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+
+	products := results[SearchProducts]
+	assert.Equal(t, int64(3), *products.Total) // termasuk fixture Kopi Susu
+	assert.Equal(t, "<mark>Kopi</mark> Latte &lt;Panas&gt;", findHit(products.Items, latte.ID).Highlight)
+	for i := 1; i < len(products.Items); i++ {
+		assert.GreaterOrEqual(t, products.Items[i-1].Rank, products.Items[i].Rank)
+	}
+
+	// guest book yang belum approved tidak ikut dicari
+	guestBooks := results[SearchGuestBooks]
+	assert.Equal(t, 1, len(guestBooks.Items))
+	assert.Equal(t, approved.ID, uint(guestBooks.Items[0].ID))
+	assert.Contains(t, guestBooks.Items[0].Highlight, "<mark>Kopinya</mark>")
+	assert.Equal(t, todo.ID, int(results[SearchTodos].Items[0].ID))
+
+	// awalan kata, tanpa aksen dan semua kata wajib ada
+	results, err = store.Search(ctx, "sus AREN", []string{SearchProducts}, PageRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, aren.ID, results[SearchProducts].Items[0].ID)
+
+	// index ikut berubah saat update dan delete
+	assert.Nil(t, store.Products.UpdateFields(ctx, teh.ID, map[string]any{"name": "Teh Kopi"}))
+	assert.Nil(t, store.Products.Delete(ctx, latte.ID))
+	assert.Nil(t, store.Todos.Delete(ctx, todo.ID))
+	results, err = store.Search(ctx, "kopi", []string{SearchProducts, SearchTodos}, PageRequest{Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(results[SearchProducts].Items))
+	assert.Nil(t, findHit(results[SearchProducts].Items, latte.ID))
+	assert.Equal(t, 0, len(results[SearchTodos].Items))
+
+	results, err = store.Search(ctx, "kopi", []string{SearchProducts}, PageRequest{Limit: 2, Offset: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results[SearchProducts].Items))
+
+	// karakter sintaks full-text diabaikan
+	results, err = store.Search(ctx, `"kopi" OR * -(`, []string{SearchProducts}, PageRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(results[SearchProducts].Items))
+
+	_, err = store.Search(ctx, "kopi", []string{"users"}, PageRequest{})
+	assert.ErrorIs(t, err, ErrInvalidSearchType)
+	_, err = store.Search(ctx, "kopi", nil, PageRequest{Cursor: "abc"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func findHit(hits []SearchHit, id int64) *SearchHit {
+	for i := range hits {
+		if hits[i].ID == id {
+			return &hits[i]
+		}
+	}
+	return nil
+}
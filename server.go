@@ -0,0 +1,364 @@
+package belajargorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const maxRequestBody = 1 << 20
+
+// Server adalah JSON REST API di atas Store. Semua query memakai context
+// dari request, jadi query ikut dibatalkan ketika client memutus koneksi.
+type Server struct {
+	store  *Store
+	mux    *http.ServeMux
+	Logger *log.Logger
+}
+
+func NewServer(store *Store) *Server {
+	s := &Server{store: store, mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /users", s.listUsers)
+	s.mux.HandleFunc("POST /users", s.createUser)
+	s.mux.HandleFunc("GET /users/search", s.searchUsers)
+	s.mux.HandleFunc("GET /users/{id}", s.getUser)
+	s.mux.HandleFunc("PUT /users/{id}", s.updateUser)
+	s.mux.HandleFunc("DELETE /users/{id}", s.deleteUser)
+	s.mux.HandleFunc("GET /users/{id}/addresses", s.listUserAddresses)
+	s.mux.HandleFunc("GET /users/{id}/likes", s.listUserLikes)
+	s.mux.HandleFunc("PUT /users/{id}/likes/{product_id}", s.likeProduct)
+	s.mux.HandleFunc("DELETE /users/{id}/likes/{product_id}", s.unlikeProduct)
+
+	s.mux.HandleFunc("GET /addresses", s.listAddresses)
+	s.mux.HandleFunc("POST /addresses", s.createAddress)
+	s.mux.HandleFunc("GET /addresses/{id}", s.getAddress)
+	s.mux.HandleFunc("PUT /addresses/{id}", s.updateAddress)
+	s.mux.HandleFunc("PUT /addresses/{id}/default", s.setDefaultAddress)
+	s.mux.HandleFunc("DELETE /addresses/{id}", s.deleteAddress)
+
+	// saldo wallet hanya berubah lewat Ledger, jadi tidak ada PUT
+	s.mux.HandleFunc("GET /wallets", s.listWallets)
+	s.mux.HandleFunc("POST /wallets", s.createWallet)
+	s.mux.HandleFunc("GET /wallets/{id}", s.getWallet)
+	s.mux.HandleFunc("DELETE /wallets/{id}", s.deleteWallet)
+
+	s.mux.HandleFunc("GET /products", s.listProducts)
+	s.mux.HandleFunc("POST /products", s.createProduct)
+	s.mux.HandleFunc("GET /products/{id}", s.getProduct)
+	s.mux.HandleFunc("PUT /products/{id}", s.updateProduct)
+	s.mux.HandleFunc("DELETE /products/{id}", s.deleteProduct)
+	s.mux.HandleFunc("GET /products/most-liked", s.mostLikedProducts)
+	s.mux.HandleFunc("GET /products/{id}/likes", s.productLikes)
+	s.mux.HandleFunc("GET /products/{id}/also-liked", s.alsoLikedProducts)
+	s.mux.HandleFunc("POST /products/{id}/restock", s.restockProduct)
+	s.mux.HandleFunc("GET /products/{id}/price", s.productPrice)
+	s.mux.HandleFunc("GET /products/{id}/price-history", s.productPriceHistory)
+
+	s.mux.HandleFunc("GET /categories", s.listCategories)
+	s.mux.HandleFunc("POST /categories", s.createCategory)
+	s.mux.HandleFunc("GET /categories/{id}", s.getCategory)
+	s.mux.HandleFunc("PUT /categories/{id}", s.updateCategory)
+	s.mux.HandleFunc("GET /categories/{id}/products", s.listCategoryProducts)
+
+	s.mux.HandleFunc("GET /orders", s.listOrders)
+	s.mux.HandleFunc("POST /orders", s.createOrder)
+	s.mux.HandleFunc("GET /orders/{id}", s.getOrder)
+	s.mux.HandleFunc("POST /orders/{id}/cancel", s.cancelOrder)
+	s.mux.HandleFunc("POST /orders/{id}/refund", s.refundOrder)
+
+	s.mux.HandleFunc("GET /todos", s.listTodos)
+	s.mux.HandleFunc("POST /todos", s.createTodo)
+	s.mux.HandleFunc("GET /todos/{id}", s.getTodo)
+	s.mux.HandleFunc("PUT /todos/{id}", s.updateTodo)
+	s.mux.HandleFunc("DELETE /todos/{id}", s.deleteTodo)
+	s.mux.HandleFunc("POST /todos/{id}/restore", s.restoreTodo)
+
+	s.mux.HandleFunc("GET /guest-books", s.listGuestBooks)
+	s.mux.HandleFunc("POST /guest-books", s.createGuestBook)
+	s.mux.HandleFunc("GET /guest-books/{id}", s.getGuestBook)
+	s.mux.HandleFunc("PUT /guest-books/{id}", s.updateGuestBook)
+	s.mux.HandleFunc("DELETE /guest-books/{id}", s.deleteGuestBook)
+	s.mux.HandleFunc("POST /guest-books/{id}/replies", s.createGuestBookReply)
+	s.mux.HandleFunc("POST /guest-books/{id}/moderation", s.moderateGuestBook)
+	s.mux.HandleFunc("POST /guest-books/moderation", s.moderateGuestBooks)
+	// tampilan publik hanya berisi entry approved beserta balasannya
+	s.mux.HandleFunc("GET /guest-books/public", s.listPublicGuestBooks)
+	s.mux.HandleFunc("GET /guest-books/public/{id}", s.getPublicGuestBook)
+
+	s.mux.HandleFunc("GET /search", s.search)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h, pattern := s.mux.Handler(r); pattern == "" {
+		// route tidak cocok: ServeMux membalas 404/405 dalam bentuk text,
+		// ganti dengan body error JSON yang sama seperti handler lain
+		rec := &headerRecorder{header: http.Header{}}
+		h.ServeHTTP(rec, r)
+		for key, values := range rec.header {
+			if key != "Content-Type" && key != "X-Content-Type-Options" {
+				w.Header()[key] = values
+			}
+		}
+
+		switch rec.status {
+		case http.StatusNotFound:
+			writeError(w, &requestError{status: http.StatusNotFound, code: "not_found", message: "route not found"})
+		case http.StatusMethodNotAllowed:
+			writeError(w, &requestError{status: http.StatusMethodNotAllowed, code: "method_not_allowed", message: "method not allowed"})
+		default:
+			w.WriteHeader(rec.status)
+		}
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		// request yang menulis juga membaca dari primary supaya hasil tulisnya
+		// langsung terlihat di response (read-your-writes)
+		r = r.WithContext(WithPrimary(r.Context()))
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+type headerRecorder struct {
+	header http.Header
+	status int
+}
+
+func (h *headerRecorder) Header() http.Header {
+	return h.header
+}
+
+func (h *headerRecorder) Write(b []byte) (int, error) {
+	if h.status == 0 {
+		h.status = http.StatusOK
+	}
+	return len(b), nil
+}
+
+func (h *headerRecorder) WriteHeader(status int) {
+	h.status = status
+}
+
+type apiError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+type errorBody struct {
+	Error apiError `json:"error"`
+}
+
+// requestError adalah error yang sudah tahu status HTTP-nya, misalnya JSON rusak.
+type requestError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...any) error {
+	return &requestError{status: http.StatusBadRequest, code: "bad_request", message: fmt.Sprintf(format, args...)}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status, body := errorResponse(err)
+	writeJSON(w, status, errorBody{Error: body})
+}
+
+func errorMessage(err error) string {
+	return strings.TrimPrefix(err.Error(), "belajargorm: ")
+}
+
+func errorResponse(err error) (int, apiError) {
+	var (
+		reqErr        *requestError
+		validationErr *ValidationError
+	)
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.status, apiError{Code: reqErr.code, Message: reqErr.message}
+	case errors.As(err, &validationErr):
+		return http.StatusUnprocessableEntity, apiError{Code: "validation_failed", Message: "validation failed", Fields: validationErr.Fields}
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound, apiError{Code: "not_found", Message: errorMessage(err)}
+	case errors.Is(err, ErrInvalidFilter), errors.Is(err, ErrInvalidSort), errors.Is(err, ErrInvalidCursor),
+		errors.Is(err, ErrInvalidSearchType):
+		return http.StatusBadRequest, apiError{Code: "invalid_query", Message: errorMessage(err)}
+	case errors.Is(err, ErrInvalidCurrency), errors.Is(err, ErrCurrencyMismatch), errors.Is(err, ErrInsufficientFunds),
+		errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrInvalidTodoStatus), errors.Is(err, ErrInvalidPriority),
+		errors.Is(err, ErrCategoryCycle), errors.Is(err, ErrInsufficientStock), errors.Is(err, ErrReleaseExceeded),
+		errors.Is(err, ErrInvalidQuantity), errors.Is(err, ErrEmptyOrder), errors.Is(err, ErrInvalidOrderTransition),
+		errors.Is(err, ErrAddressNotOwnedByBuyer), errors.Is(err, ErrInvalidModerationStatus):
+		return http.StatusUnprocessableEntity, apiError{Code: "unprocessable", Message: errorMessage(err)}
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return http.StatusConflict, apiError{Code: "conflict", Message: "resource already exists"}
+	case errors.Is(err, ErrStaleObject):
+		return http.StatusConflict, apiError{Code: "stale_object", Message: errorMessage(err)}
+	case errors.Is(err, ErrRestricted), errors.Is(err, ErrDefaultAddressRequired):
+		return http.StatusConflict, apiError{Code: "conflict", Message: errorMessage(err)}
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return http.StatusConflict, apiError{Code: "conflict", Message: "resource is still referenced"}
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, apiError{Code: "timeout", Message: "request timed out"}
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, apiError{Code: "request_cancelled", Message: "request was cancelled"}
+	default:
+		return http.StatusInternalServerError, apiError{Code: "internal_error", Message: "internal server error"}
+	}
+}
+
+// fail menulis error dan mencatat error yang tidak dikenal (status 500).
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, err error) {
+	status, _ := errorResponse(err)
+	if status == http.StatusInternalServerError {
+		logger := s.Logger
+		if logger == nil {
+			logger = log.Default()
+		}
+		logger.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	}
+	writeError(w, err)
+}
+
+// decodeJSON membaca body ke dst, field yang tidak dikenal ditolak.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var (
+			maxErr    *http.MaxBytesError
+			syntaxErr *json.SyntaxError
+			typeErr   *json.UnmarshalTypeError
+		)
+		switch {
+		case errors.As(err, &maxErr):
+			return &requestError{status: http.StatusRequestEntityTooLarge, code: "body_too_large", message: "request body is too large"}
+		case errors.Is(err, io.EOF):
+			return badRequest("request body is empty")
+		case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+			return badRequest("request body is not valid JSON")
+		case errors.As(err, &typeErr):
+			return badRequest("field %s must be %s", typeErr.Field, typeErr.Type)
+		case errors.Is(err, ErrInvalidCurrency):
+			return err
+		default:
+			return badRequest("%s", strings.TrimPrefix(err.Error(), "json: "))
+		}
+	}
+	if dec.More() {
+		return badRequest("request body must contain a single JSON object")
+	}
+	return nil
+}
+
+func pathInt64(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, badRequest("invalid %s %q", name, r.PathValue(name))
+	}
+	return id, nil
+}
+
+// pageRequest membaca filter, sort dan parameter pagination (limit, offset,
+// cursor, total) dari query string.
+func pageRequest[T any](r *http.Request) (PageRequest, *Filter, error) {
+	filter, err := ParseFilter[T](r.URL.RawQuery)
+	if err != nil {
+		return PageRequest{}, nil, err
+	}
+	req, err := pageParams(r)
+	if err != nil {
+		return req, nil, err
+	}
+	req.Sort = filter.Sort
+	return req, filter, nil
+}
+
+// pageParams hanya membaca limit, offset, cursor dan total, tanpa filter dan sort.
+func pageParams(r *http.Request) (PageRequest, error) {
+	var (
+		req PageRequest
+		err error
+	)
+	query := r.URL.Query()
+	for name, dst := range map[string]*int{"limit": &req.Limit, "offset": &req.Offset} {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return req, fmt.Errorf("%w: %s must be a positive number", ErrInvalidFilter, name)
+			}
+			*dst = n
+		}
+	}
+	if v := query.Get("total"); v != "" {
+		if req.WithTotal, err = strconv.ParseBool(v); err != nil {
+			return req, fmt.Errorf("%w: total must be true or false", ErrInvalidFilter)
+		}
+	}
+	req.Cursor = query.Get("cursor")
+	return req, nil
+}
+
+func listResource[T any, R any](s *Server, w http.ResponseWriter, r *http.Request, repo *Repository[T], toJSON func(*T) R, opts ...QueryOption) {
+	req, filter, err := pageRequest[T](r)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	page, err := repo.Page(r.Context(), req, append(opts, filter.Where())...)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mapPage(page, toJSON))
+}
+
+func mapPage[T any, R any](page *Page[T], toJSON func(*T) R) *Page[R] {
+	items := make([]R, len(page.Items))
+	for i := range page.Items {
+		items[i] = toJSON(&page.Items[i])
+	}
+	return &Page[R]{
+		Items:      items,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+		Total:      page.Total,
+	}
+}
+
+func mapSlice[T any, R any](items []T, toJSON func(*T) R) []R {
+	out := make([]R, len(items))
+	for i := range items {
+		out[i] = toJSON(&items[i])
+	}
+	return out
+}
@@ -0,0 +1,664 @@
+package belajargorm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) *Server {
+	return NewServer(NewStore(beginTest(t)))
+}
+
+func doRequest(t *testing.T, h http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(b)
+	default:
+		raw, err := json.Marshal(b)
+		assert.Nil(t, err)
+		reader = bytes.NewReader(raw)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, reader))
+	return rec
+}
+
+func decodeBody[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &v), rec.Body.String())
+	return v
+}
+
+func TestServerUserCRUD(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := doRequest(t, srv, http.MethodPost, "/users", map[string]any{
+		"id":       "600",
+		"password": "rahasia123",
+		"name":     map[string]string{"first_name": "Server", "last_name": "Http"},
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, "/users/600", rec.Header().Get("Location"))
+	assert.NotContains(t, rec.Body.String(), "password")
+
+	user := decodeBody[userJSON](t, rec)
+	assert.Equal(t, "Server", user.Name.FirstName)
+	assert.Equal(t, Version(1), user.Version)
+
+	rec = doRequest(t, srv, http.MethodPut, "/users/600", map[string]any{
+		"name":    map[string]string{"first_name": "Server", "middle_name": "Rest", "last_name": "Http"},
+		"version": user.Version,
+	})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "Rest", decodeBody[userJSON](t, rec).Name.MiddleName)
+
+	rec = doRequest(t, srv, http.MethodGet, "/users/search?q=rest+HTTP", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	found := decodeBody[[]userJSON](t, rec)
+	assert.Equal(t, 1, len(found))
+	assert.Equal(t, "Server Rest Http", found[0].FullName)
+
+	rec = doRequest(t, srv, http.MethodGet, "/users/search", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// version lama ditolak
+	rec = doRequest(t, srv, http.MethodPut, "/users/600", map[string]any{
+		"name":    map[string]string{"first_name": "Basi"},
+		"version": user.Version,
+	})
+	assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+	assert.Equal(t, "stale_object", decodeBody[errorBody](t, rec).Error.Code)
+
+	rec = doRequest(t, srv, http.MethodGet, "/users/600", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Rest", decodeBody[userJSON](t, rec).Name.MiddleName)
+
+	rec = doRequest(t, srv, http.MethodGet, "/users?first_name=Server", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, len(decodeBody[Page[userJSON]](t, rec).Items))
+
+	rec = doRequest(t, srv, http.MethodDelete, "/users/600", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(1), decodeBody[map[string]CascadeSummary](t, rec)["deleted"]["users"])
+
+	rec = doRequest(t, srv, http.MethodGet, "/users/600", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "not_found", decodeBody[errorBody](t, rec).Error.Code)
+}
+
+func TestServerValidation(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := doRequest(t, srv, http.MethodPost, "/users", map[string]any{"name": map[string]string{}})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	body := decodeBody[errorBody](t, rec)
+	assert.Equal(t, "validation_failed", body.Error.Code)
+	assert.Equal(t, map[string]string{
+		"name.first_name": "is required",
+		"password":        "is required",
+	}, body.Error.Fields)
+
+	rec = doRequest(t, srv, http.MethodPost, "/users", `{"name": `)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "bad_request", decodeBody[errorBody](t, rec).Error.Code)
+
+	rec = doRequest(t, srv, http.MethodPost, "/users", `{"password": "rahasia123", "name": {"first_name": "A"}, "admin": true}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, decodeBody[errorBody](t, rec).Error.Message, "admin")
+
+	rec = doRequest(t, srv, http.MethodPost, "/users", `{"password": 123}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// hash bcrypt tidak boleh dikirim sebagai password
+	rec = doRequest(t, srv, http.MethodPost, "/users", map[string]any{
+		"password": "$2a$04$" + strings.Repeat("x", 53), "name": map[string]string{"first_name": "Hash"},
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "must be plain text, not a hash", decodeBody[errorBody](t, rec).Error.Fields["password"])
+
+	rec = doRequest(t, srv, http.MethodPost, "/users", map[string]any{
+		"id": "1", "password": "rahasia123", "name": map[string]string{"first_name": "Kembar"},
+	})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodPost, "/addresses", map[string]string{"user_id": "tidak-ada", "line1": "Jalan", "city": "Bandung"})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "does not exist", decodeBody[errorBody](t, rec).Error.Fields["user_id"])
+
+	rec = doRequest(t, srv, http.MethodPost, "/guest-books", map[string]string{"name": "Tamu", "email": "bukan email", "message": "Halo"})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "must be a valid email address", decodeBody[errorBody](t, rec).Error.Fields["email"])
+
+	rec = doRequest(t, srv, http.MethodPost, "/products", `{"name": "Teh", "price": {"amount": 100, "currency": "rupiah"}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	// rule diambil dari tag model: price min=1, key-nya nama column
+	rec = doRequest(t, srv, http.MethodPost, "/products", map[string]any{
+		"name": "Teh", "price": map[string]any{"amount": 0, "currency": "IDR"}, "stock": -1,
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, map[string]string{
+		"price": "must be at least 1",
+		"stock": "must be at least 0",
+	}, decodeBody[errorBody](t, rec).Error.Fields)
+
+	rec = doRequest(t, srv, http.MethodPost, "/users", map[string]any{
+		"password": "rahasia123", "name": map[string]string{"first_name": "A", "last_name": strings.Repeat("x", 101)},
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, map[string]string{
+		"name.last_name": "must be at most 100 characters",
+	}, decodeBody[errorBody](t, rec).Error.Fields)
+
+	rec = doRequest(t, srv, http.MethodGet, "/users?password=rahasia", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid_query", decodeBody[errorBody](t, rec).Error.Code)
+
+	rec = doRequest(t, srv, http.MethodGet, "/addresses/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServerListPagination(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := doRequest(t, srv, http.MethodGet, "/users?sort=id&limit=5&total=true", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	page := decodeBody[Page[userJSON]](t, rec)
+	assert.Equal(t, 5, len(page.Items))
+	assert.Equal(t, int64(14), *page.Total)
+	assert.NotEmpty(t, page.NextCursor)
+
+	rec = doRequest(t, srv, http.MethodGet, "/users?sort=id&limit=5&cursor="+page.NextCursor, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	next := decodeBody[Page[userJSON]](t, rec)
+	assert.Equal(t, "14", next.Items[0].ID)
+
+	// sort dengan kolom association memakai join yang sama dengan filter
+	path := "/users?Wallet.balance:null=false&sort=-Wallet.balance&limit=2&total=true"
+	rec = doRequest(t, srv, http.MethodGet, path, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	page = decodeBody[Page[userJSON]](t, rec)
+	assert.Equal(t, int64(3), *page.Total)
+	assert.Equal(t, []string{"2", "1"}, []string{page.Items[0].ID, page.Items[1].ID})
+
+	rec = doRequest(t, srv, http.MethodGet, path+"&cursor="+page.NextCursor, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	next = decodeBody[Page[userJSON]](t, rec)
+	assert.Equal(t, 1, len(next.Items))
+	assert.Equal(t, "3", next.Items[0].ID)
+
+	rec = doRequest(t, srv, http.MethodGet, path+"&cursor="+next.PrevCursor, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, page.Items, decodeBody[Page[userJSON]](t, rec).Items)
+
+	// tanpa filter association tetap di-join untuk sort
+	rec = doRequest(t, srv, http.MethodGet, "/users?sort=Wallet.balance&limit=1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodGet, "/users?limit=banyak", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServerUserAssociations(t *testing.T) {
+	srv := newTestServer(t)
+	productPath := "/users/3/likes/" + strconv.FormatInt(productID, 10)
+
+	rec := doRequest(t, srv, http.MethodGet, "/users/1/addresses", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, len(decodeBody[[]addressJSON](t, rec)))
+
+	rec = doRequest(t, srv, http.MethodGet, "/users/tidak-ada/addresses", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	for i := 0; i < 2; i++ {
+		rec = doRequest(t, srv, http.MethodPut, productPath, nil)
+		assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(t, srv, http.MethodGet, "/users/3/likes", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	likes := decodeBody[[]productJSON](t, rec)
+	assert.Equal(t, 1, len(likes))
+	assert.Equal(t, productID, likes[0].ID)
+	assert.Contains(t, rec.Body.String(), `"id":"`+strconv.FormatInt(productID, 10)+`"`)
+
+	rec = doRequest(t, srv, http.MethodDelete, productPath, nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodGet, "/users/3/likes", nil)
+	assert.Equal(t, 0, len(decodeBody[[]productJSON](t, rec)))
+
+	rec = doRequest(t, srv, http.MethodPut, "/users/3/likes/12345", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServerProductLikes(t *testing.T) {
+	srv := newTestServer(t)
+	path := "/products/" + strconv.FormatInt(productID, 10)
+
+	rec := doRequest(t, srv, http.MethodGet, path+"/likes", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	body := decodeBody[struct {
+		Likes int        `json:"likes"`
+		Users []userJSON `json:"users"`
+	}](t, rec)
+	assert.Equal(t, 2, body.Likes)
+	assert.Equal(t, 2, len(body.Users))
+
+	rec = doRequest(t, srv, http.MethodGet, "/products/most-liked?days=1&limit=5", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	popular := decodeBody[[]productLikesJSON](t, rec)
+	assert.Equal(t, 1, len(popular))
+	assert.Equal(t, productID, popular[0].Product.ID)
+	assert.Equal(t, int64(2), popular[0].Likes)
+
+	rec = doRequest(t, srv, http.MethodGet, "/products/most-liked?days=0", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodGet, path+"/also-liked", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 0, len(decodeBody[[]productLikesJSON](t, rec)))
+
+	rec = doRequest(t, srv, http.MethodGet, "/products/12345/also-liked", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServerCatalog(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := doRequest(t, srv, http.MethodPost, "/categories", map[string]any{"name": "Minuman"})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	minuman := decodeBody[categoryJSON](t, rec)
+
+	rec = doRequest(t, srv, http.MethodPost, "/categories", map[string]any{"name": "Kopi", "parent_id": minuman.ID})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	kopi := decodeBody[categoryJSON](t, rec)
+
+	rec = doRequest(t, srv, http.MethodPost, "/categories", map[string]any{"name": "Kopi", "parent_id": minuman.ID})
+	assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodPost, "/categories", map[string]any{"name": "Teh", "parent_id": 999})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodPut, "/categories/"+strconv.FormatInt(minuman.ID, 10), map[string]any{
+		"name": "Minuman", "parent_id": kopi.ID,
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodGet, "/categories", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	tree := decodeBody[[]categoryJSON](t, rec)
+	assert.Equal(t, 1, len(tree))
+	assert.Equal(t, "Kopi", tree[0].Children[0].Name)
+
+	rec = doRequest(t, srv, http.MethodPost, "/products", map[string]any{
+		"name":        "Kopi Tubruk",
+		"price":       map[string]any{"amount": 12000, "currency": "IDR"},
+		"category_id": kopi.ID,
+		"stock":       4,
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	product := decodeBody[productJSON](t, rec)
+	assert.Equal(t, int64(4), product.Available)
+	path := "/products/" + strconv.FormatInt(product.ID, 10)
+
+	rec = doRequest(t, srv, http.MethodGet, "/categories/"+strconv.FormatInt(minuman.ID, 10)+"/products", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 1, len(decodeBody[[]productJSON](t, rec)))
+
+	rec = doRequest(t, srv, http.MethodGet, "/categories/"+strconv.FormatInt(minuman.ID, 10)+"/products?recursive=false", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 0, len(decodeBody[[]productJSON](t, rec)))
+
+	rec = doRequest(t, srv, http.MethodPost, path+"/restock", map[string]any{"quantity": 6})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, int64(10), decodeBody[productJSON](t, rec).Stock)
+
+	rec = doRequest(t, srv, http.MethodPost, path+"/restock", map[string]any{"quantity": 0})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodPut, path, map[string]any{
+		"name":  "Kopi Tubruk",
+		"price": map[string]any{"amount": 14000, "currency": "IDR"},
+	})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Nil(t, decodeBody[productJSON](t, rec).CategoryID)
+
+	rec = doRequest(t, srv, http.MethodGet, path+"/price", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, IDR(14000), decodeBody[productPriceJSON](t, rec).Price)
+
+	rec = doRequest(t, srv, http.MethodGet, path+"/price-history", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 2, len(decodeBody[[]productPriceJSON](t, rec)))
+
+	rec = doRequest(t, srv, http.MethodGet, path+"/price?at=2000-01-01T00:00:00Z", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodGet, path+"/price?at=kemarin", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+}
+
+func TestServerOrders(t *testing.T) {
+	srv := newTestServer(t)
+	product := strconv.FormatInt(productID, 10)
+
+	rec := doRequest(t, srv, http.MethodPost, "/products/"+product+"/restock", map[string]any{"quantity": 3})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodPost, "/orders", map[string]any{
+		"user_id":    "1",
+		"address_id": 1,
+		"items":      []map[string]any{{"product_id": product, "quantity": 2}},
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	order := decodeBody[orderJSON](t, rec)
+	assert.Equal(t, OrderPaid, order.Status)
+	assert.Equal(t, IDR(50000), order.Total)
+	assert.Equal(t, 1, len(order.Items))
+	path := "/orders/" + strconv.FormatInt(order.ID, 10)
+
+	rec = doRequest(t, srv, http.MethodPost, "/orders", map[string]any{
+		"user_id":    "1",
+		"address_id": 1,
+		"items":      []map[string]any{{"product_id": product, "quantity": 2}},
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodPost, "/orders", map[string]any{"user_id": "1", "items": []map[string]any{}})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	assert.Equal(t, "is required", decodeBody[errorBody](t, rec).Error.Fields["address_id"])
+
+	rec = doRequest(t, srv, http.MethodGet, "/orders?user_id=1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	page := decodeBody[Page[orderJSON]](t, rec)
+	assert.Equal(t, 1, len(page.Items))
+	assert.Equal(t, "Kopi Susu", page.Items[0].Items[0].ProductName)
+
+	rec = doRequest(t, srv, http.MethodPost, path+"/refund", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, OrderRefunded, decodeBody[orderJSON](t, rec).Status)
+
+	rec = doRequest(t, srv, http.MethodPost, path+"/cancel", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodGet, "/orders/999", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestServerGuestBookModeration(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := doRequest(t, srv, http.MethodPost, "/guest-books", map[string]string{"name": "Tamu", "email": "tamu@example.com", "message": "Halo"})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	entry := decodeBody[guestBookJSON](t, rec)
+	assert.Equal(t, GuestBookPending, entry.Status)
+	path := "/guest-books/" + strconv.FormatUint(uint64(entry.ID), 10)
+
+	rec = doRequest(t, srv, http.MethodPost, path+"/replies", map[string]string{"name": "Admin", "email": "admin@example.com", "message": "Hai"})
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodPost, path+"/moderation", map[string]string{"status": "approved", "note": "ok"})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, GuestBookApproved, decodeBody[guestBookJSON](t, rec).Status)
+
+	rec = doRequest(t, srv, http.MethodPost, path+"/replies", map[string]string{"name": "Admin", "email": "admin@example.com", "message": "Hai"})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	reply := decodeBody[guestBookJSON](t, rec)
+	assert.Equal(t, entry.ID, *reply.ParentID)
+
+	rec = doRequest(t, srv, http.MethodPost, "/guest-books", map[string]string{"name": "Promo", "email": "promo@example.com", "message": "casino online"})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Equal(t, GuestBookSpam, decodeBody[guestBookJSON](t, rec).Status)
+
+	rec = doRequest(t, srv, http.MethodPost, "/guest-books/moderation", map[string]any{"ids": []uint{reply.ID}, "status": "approved"})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, int64(1), decodeBody[map[string]int64](t, rec)["updated"])
+
+	rec = doRequest(t, srv, http.MethodGet, "/guest-books/public", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	page := decodeBody[Page[publicGuestBookJSON]](t, rec)
+	assert.Equal(t, 1, len(page.Items))
+	assert.Equal(t, "Hai", page.Items[0].Replies[0].Message)
+	assert.NotContains(t, rec.Body.String(), "tamu@example.com")
+
+	rec = doRequest(t, srv, http.MethodGet, "/guest-books/public/"+strconv.FormatUint(uint64(entry.ID), 10), nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, srv, http.MethodGet, "/guest-books?status=spam", nil)
+	assert.Equal(t, 1, len(decodeBody[Page[guestBookJSON]](t, rec).Items))
+
+	rec = doRequest(t, srv, http.MethodPost, "/guest-books/moderation", map[string]any{"status": "hidden"})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	fields := decodeBody[errorBody](t, rec).Error.Fields
+	assert.Equal(t, "is required", fields["ids"])
+	assert.NotEmpty(t, fields["status"])
+
+	rec = doRequest(t, srv, http.MethodPost, "/guest-books/999/moderation", map[string]string{"status": "rejected"})
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestServerResources(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := doRequest(t, srv, http.MethodPost, "/wallets", map[string]any{
+		"user_id": "4",
+		"balance": map[string]any{"amount": 5000, "currency": "IDR"},
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	wallet := decodeBody[walletJSON](t, rec)
+	assert.Equal(t, IDR(5000), wallet.Balance)
+
+	rec = doRequest(t, srv, http.MethodGet, "/wallets?balance>=1000000&sort=-balance", nil)
+	assert.Equal(t, 2, len(decodeBody[Page[walletJSON]](t, rec).Items))
+
+	rec = doRequest(t, srv, http.MethodPut, "/wallets/1", map[string]any{"user_id": "1"})
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodPost, "/products", map[string]any{
+		"name":  "Teh Manis",
+		"price": map[string]any{"amount": 8000, "currency": "IDR"},
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	product := decodeBody[productJSON](t, rec)
+
+	path := "/products/" + strconv.FormatInt(product.ID, 10)
+	rec = doRequest(t, srv, http.MethodPut, path, map[string]any{
+		"name":  "Teh Manis",
+		"price": map[string]any{"amount": 9000, "currency": "IDR"},
+	})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, IDR(9000), decodeBody[productJSON](t, rec).Price)
+
+	rec = doRequest(t, srv, http.MethodPost, "/todos", map[string]string{"user_id": "2", "task": "Belajar REST"})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	todo := decodeBody[todoJSON](t, rec)
+
+	rec = doRequest(t, srv, http.MethodPut, "/todos/"+strconv.Itoa(todo.ID), map[string]string{"user_id": "2", "task": "Belajar net/http"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Belajar net/http", decodeBody[todoJSON](t, rec).Task)
+
+	rec = doRequest(t, srv, http.MethodDelete, "/todos/"+strconv.Itoa(todo.ID), nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doRequest(t, srv, http.MethodGet, "/todos/"+strconv.Itoa(todo.ID), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodPost, "/guest-books", map[string]string{"name": "Tamu", "email": "tamu@example.com", "message": "Halo"})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	entry := decodeBody[guestBookJSON](t, rec)
+
+	path = "/guest-books/" + strconv.FormatUint(uint64(entry.ID), 10)
+	rec = doRequest(t, srv, http.MethodPut, path, map[string]string{"name": "Tamu", "email": "tamu@example.com", "message": "Halo lagi"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodGet, "/guest-books?email=tamu@example.com", nil)
+	assert.Equal(t, "Halo lagi", decodeBody[Page[guestBookJSON]](t, rec).Items[0].Message)
+
+	rec = doRequest(t, srv, http.MethodPost, "/addresses", map[string]string{"user_id": "2", "line1": "Jalan Merdeka", "city": "Bandung", "postal_code": "40111"})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	address := decodeBody[addressJSON](t, rec)
+	assert.True(t, address.IsDefault)
+	assert.Equal(t, "ID", address.Country)
+
+	rec = doRequest(t, srv, http.MethodPost, "/addresses", map[string]any{
+		"user_id": "2", "label": "work", "line1": "Jalan Gatot Subroto", "city": "Jakarta", "postal_code": "1234", "country": "id",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "is not a valid postal code for ID", decodeBody[errorBody](t, rec).Error.Fields["postal_code"])
+
+	rec = doRequest(t, srv, http.MethodPost, "/addresses", map[string]any{
+		"user_id": "2", "label": "work", "line1": "Jalan Gatot Subroto", "city": "Jakarta", "postal_code": "12930", "country": "id",
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	work := decodeBody[addressJSON](t, rec)
+	assert.False(t, work.IsDefault)
+
+	path = "/addresses/" + strconv.FormatInt(address.ID, 10)
+	rec = doRequest(t, srv, http.MethodPut, path, map[string]any{"user_id": "2", "line1": "Jalan Merdeka", "city": "Bandung", "is_default": false})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodPut, "/addresses/"+strconv.FormatInt(work.ID, 10)+"/default", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.True(t, decodeBody[addressJSON](t, rec).IsDefault)
+
+	rec = doRequest(t, srv, http.MethodGet, "/users/2/addresses", nil)
+	addresses := decodeBody[[]addressJSON](t, rec)
+	assert.Equal(t, work.ID, addresses[0].ID)
+	assert.False(t, addresses[1].IsDefault)
+
+	rec = doRequest(t, srv, http.MethodDelete, path, nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestServerTodoWorkflow(t *testing.T) {
+	srv := newTestServer(t)
+
+	due := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	rec := doRequest(t, srv, http.MethodPost, "/todos", map[string]any{
+		"user_id":  "3",
+		"task":     "Kirim laporan",
+		"priority": PriorityHigh,
+		"due_at":   due,
+		"tags":     []string{"Kerja", "laporan"},
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	todo := decodeBody[todoJSON](t, rec)
+	assert.Equal(t, TodoOpen, todo.Status)
+	assert.Equal(t, PriorityHigh, todo.Priority)
+	assert.True(t, todo.Overdue)
+	assert.ElementsMatch(t, []string{"kerja", "laporan"}, todo.Tags)
+
+	rec = doRequest(t, srv, http.MethodGet, "/todos?user_id=3&overdue=true&tag=KERJA", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	page := decodeBody[Page[todoJSON]](t, rec)
+	assert.Equal(t, 1, len(page.Items))
+	assert.Equal(t, todo.ID, page.Items[0].ID)
+
+	rec = doRequest(t, srv, http.MethodGet, "/todos?user_id=3&due_within=24h", nil)
+	assert.Equal(t, 0, len(decodeBody[Page[todoJSON]](t, rec).Items))
+
+	rec = doRequest(t, srv, http.MethodGet, "/todos?due_within=kemarin", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	path := "/todos/" + strconv.Itoa(todo.ID)
+	rec = doRequest(t, srv, http.MethodPut, path, map[string]any{"user_id": "3", "task": "Kirim laporan", "status": TodoDone, "due_at": due})
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	todo = decodeBody[todoJSON](t, rec)
+	assert.Equal(t, TodoDone, todo.Status)
+	assert.NotNil(t, todo.CompletedAt)
+	assert.False(t, todo.Overdue)
+	assert.Equal(t, 2, len(todo.Tags))
+
+	rec = doRequest(t, srv, http.MethodPut, path, map[string]any{"user_id": "3", "task": "Kirim laporan", "status": TodoInProgress})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodPut, path, map[string]any{"user_id": "3", "task": "Kirim laporan", "status": "arsip", "priority": 7})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	fields := decodeBody[errorBody](t, rec).Error.Fields
+	assert.Contains(t, fields, "status")
+	assert.Contains(t, fields, "priority")
+
+	rec = doRequest(t, srv, http.MethodPost, path+"/restore", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodDelete, path, nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = doRequest(t, srv, http.MethodPost, path+"/restore", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "Kirim laporan", decodeBody[todoJSON](t, rec).Task)
+}
+
+func TestServerSearch(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := doRequest(t, srv, http.MethodGet, "/search?q=kopi+sus&types=products,todos&total=true", nil)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	results := decodeBody[map[string]Page[SearchHit]](t, rec)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, int64(1), *results[SearchProducts].Total)
+	assert.Equal(t, productID, results[SearchProducts].Items[0].ID)
+	assert.Equal(t, "<mark>Kopi</mark> <mark>Susu</mark>", results[SearchProducts].Items[0].Highlight)
+
+	rec = doRequest(t, srv, http.MethodGet, "/search?q=kopi&types=users", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doRequest(t, srv, http.MethodGet, "/search", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServerRoutes(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := doRequest(t, srv, http.MethodGet, "/tidak-ada", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "not_found", decodeBody[errorBody](t, rec).Error.Code)
+
+	rec = doRequest(t, srv, http.MethodPatch, "/users/1", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Contains(t, rec.Header().Get("Allow"), http.MethodPut)
+	assert.Equal(t, "method_not_allowed", decodeBody[errorBody](t, rec).Error.Code)
+}
+
+func TestServerContextCancelled(t *testing.T) {
+	srv := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "request_cancelled", decodeBody[errorBody](t, rec).Error.Code)
+}
+
+func TestServerHTTP(t *testing.T) {
+	ts := httptest.NewServer(newTestServer(t))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/users/1")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	var user userJSON
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&user))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Yonathan", user.Name.FirstName)
+}
@@ -0,0 +1,357 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/plugin/soft_delete"
+)
+
+var ErrNotSoftDeletable = errors.New("belajargorm: model does not support soft delete")
+
+// DefaultRetention adalah lama data disimpan di trash sebelum dihapus permanen oleh Purger.
+const DefaultRetention = 30 * 24 * time.Hour
+
+/**
+*	softDeleteColumn menyamakan dua cara soft delete yang dipakai model:
+*	gorm.DeletedAt (timestamp, NULL berarti aktif) dan soft_delete.DeletedAt
+*	(angka unix, 0 berarti aktif) dengan satuan detik, milli atau nano.
+ */
+type softDeleteColumn struct {
+	name string
+	unit string
+}
+
+var (
+	gormDeletedAtType   = reflect.TypeOf(gorm.DeletedAt{})
+	pluginDeletedAtType = reflect.TypeOf(soft_delete.DeletedAt(0))
+)
+
+func softDeleteColumnOf(s *schema.Schema) (softDeleteColumn, bool) {
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		switch field.FieldType {
+		case gormDeletedAtType:
+			return softDeleteColumn{name: field.DBName}, true
+		case pluginDeletedAtType:
+			setting := strings.ToLower(field.TagSettings["SOFTDELETE"])
+			switch {
+			case strings.Contains(setting, "flag"):
+				// mode flag hanya 0/1, tidak ada waktu hapus untuk retention
+				return softDeleteColumn{}, false
+			case strings.Contains(setting, "nano"):
+				return softDeleteColumn{name: field.DBName, unit: "nano"}, true
+			case strings.Contains(setting, "milli"):
+				return softDeleteColumn{name: field.DBName, unit: "milli"}, true
+			default:
+				return softDeleteColumn{name: field.DBName, unit: "second"}, true
+			}
+		}
+	}
+	return softDeleteColumn{}, false
+}
+
+func (c softDeleteColumn) column() clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: c.name}
+}
+
+func (c softDeleteColumn) deleted() clause.Expression {
+	if c.unit == "" {
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []any{c.column()}}
+	}
+	return clause.Neq{Column: c.column(), Value: 0}
+}
+
+func (c softDeleteColumn) value(t time.Time) any {
+	switch c.unit {
+	case "nano":
+		return t.UnixNano()
+	case "milli":
+		return t.UnixMilli()
+	case "second":
+		return t.Unix()
+	default:
+		return t
+	}
+}
+
+func (c softDeleteColumn) deletedBefore(t time.Time) clause.Expression {
+	return clause.And(c.deleted(), clause.Lt{Column: c.column(), Value: c.value(t)})
+}
+
+func (c softDeleteColumn) restored() any {
+	if c.unit == "" {
+		return nil
+	}
+	return 0
+}
+
+// PurgeResult berisi jumlah baris yang dihapus permanen dan yang dilewati
+// karena masih direferensikan data lain.
+type PurgeResult struct {
+	Purged  int64
+	Skipped int64
+}
+
+/**
+*	Trash berisi data model T yang sudah di-soft delete: bisa dilihat,
+*	dikembalikan (Restore) atau dihapus permanen. Model tanpa kolom soft
+*	delete mengembalikan ErrNotSoftDeletable.
+ */
+type Trash[T any] struct {
+	db        *gorm.DB
+	model     string
+	column    softDeleteColumn
+	many2many []string
+	err       error
+}
+
+func NewTrash[T any](db *gorm.DB) *Trash[T] {
+	t := &Trash[T]{db: db, model: reflect.TypeOf((*T)(nil)).Elem().Name()}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		t.err = err
+		return t
+	}
+	column, ok := softDeleteColumnOf(stmt.Schema)
+	if !ok {
+		t.err = fmt.Errorf("%w: %s", ErrNotSoftDeletable, t.model)
+		return t
+	}
+	t.column = column
+	for _, rel := range stmt.Schema.Relationships.Many2Many {
+		t.many2many = append(t.many2many, rel.Name)
+	}
+	return t
+}
+
+func (r *Repository[T]) Trash() *Trash[T] {
+	return NewTrash[T](r.db)
+}
+
+// Restore mengembalikan data yang sudah di-soft delete.
+func (r *Repository[T]) Restore(ctx context.Context, id any) error {
+	return r.Trash().Restore(ctx, id)
+}
+
+func (t *Trash[T]) query(ctx context.Context, opts []QueryOption) *gorm.DB {
+	db := t.db.WithContext(ctx).Unscoped().Model(new(T)).Where(t.column.deleted())
+	for _, opt := range opts {
+		db = opt(db)
+	}
+	return db
+}
+
+func (t *Trash[T]) notFound(id any) error {
+	return &NotFoundError{Model: "deleted " + t.model, Key: id}
+}
+
+func (t *Trash[T]) List(ctx context.Context, opts ...QueryOption) ([]T, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	var items []T
+	err := t.query(ctx, opts).Find(&items).Error
+	return items, err
+}
+
+func (t *Trash[T]) Page(ctx context.Context, req PageRequest, opts ...QueryOption) (*Page[T], error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	return Paginate[T](ctx, t.query(ctx, opts), req)
+}
+
+func (t *Trash[T]) Count(ctx context.Context, opts ...QueryOption) (int64, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	var count int64
+	err := t.query(ctx, opts).Count(&count).Error
+	return count, err
+}
+
+func (t *Trash[T]) Get(ctx context.Context, id any) (*T, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	var item T
+	err := t.query(ctx, nil).Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).Take(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, t.notFound(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Restore mengosongkan kolom deleted_at. Data yang tidak ada di trash
+// menghasilkan NotFoundError.
+func (t *Trash[T]) Restore(ctx context.Context, id any) error {
+	if t.err != nil {
+		return t.err
+	}
+	// hook model tidak dijalankan karena model kosong, updated_at tetap diisi gorm
+	res := t.query(ctx, nil).Session(&gorm.Session{SkipHooks: true}).
+		Where(clause.Eq{Column: clause.PrimaryColumn, Value: id}).
+		Update(t.column.name, t.column.restored())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return t.notFound(id)
+	}
+	return nil
+}
+
+// Purge menghapus permanen satu data yang sudah ada di trash.
+func (t *Trash[T]) Purge(ctx context.Context, id any) error {
+	item, err := t.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return t.purge(ctx, item)
+}
+
+func (t *Trash[T]) purge(ctx context.Context, item *T) error {
+	// savepoint per baris supaya satu baris yang gagal tidak membatalkan transaksi luar
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tx = tx.Unscoped()
+		if len(t.many2many) > 0 {
+			// baris join table many2many ikut dihapus, association lain dibiarkan
+			tx = tx.Select(t.many2many)
+		}
+		return tx.Delete(item).Error
+	})
+}
+
+/**
+*	PurgeOlderThan menghapus permanen data yang sudah lebih lama dari age di
+*	trash. Baris yang masih direferensikan foreign key (misalnya user yang
+*	masih punya wallet) dilewati dan dihitung di PurgeResult.Skipped.
+ */
+func (t *Trash[T]) PurgeOlderThan(ctx context.Context, age time.Duration) (PurgeResult, error) {
+	var result PurgeResult
+	if t.err != nil {
+		return result, t.err
+	}
+
+	var batch []T
+	cutoff := time.Now().Add(-age)
+	err := t.db.WithContext(ctx).Unscoped().Model(new(T)).
+		Where(t.column.deletedBefore(cutoff)).
+		FindInBatches(&batch, 100, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				err := t.purge(ctx, &batch[i])
+				switch {
+				case errors.Is(err, gorm.ErrForeignKeyViolated):
+					result.Skipped++
+				case err != nil:
+					return err
+				default:
+					result.Purged++
+				}
+			}
+			return nil
+		}).Error
+	return result, err
+}
+
+type purgeTarget struct {
+	name  string
+	purge func(ctx context.Context, age time.Duration) (PurgeResult, error)
+}
+
+func trashTarget[T any](name string, trash *Trash[T]) purgeTarget {
+	return purgeTarget{name: name, purge: trash.PurgeOlderThan}
+}
+
+/**
+*	Purger menjalankan PurgeOlderThan untuk semua model yang punya soft delete
+*	setiap Interval. Jalankan dengan go purger.Run(ctx), berhenti saat ctx
+*	dibatalkan.
+ */
+type Purger struct {
+	Retention time.Duration
+	Interval  time.Duration
+	Logger    *log.Logger
+
+	targets []purgeTarget
+}
+
+func NewPurger(store *Store, retention time.Duration) *Purger {
+	return &Purger{
+		Retention: retention,
+		Interval:  time.Hour,
+		targets: []purgeTarget{
+			trashTarget("users", store.Users.Trash()),
+			trashTarget("wallets", store.Wallets.Trash()),
+			trashTarget("addresses", store.Addresses.Trash()),
+			trashTarget("products", store.Products.Trash()),
+			trashTarget("todos", store.Todos.Trash()),
+			trashTarget("guest_books", store.GuestBooks.Trash()),
+		},
+	}
+}
+
+func (p *Purger) logf(format string, args ...any) {
+	logger := p.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf(format, args...)
+}
+
+// PurgeOnce menjalankan satu putaran purge. Error satu table tidak
+// menghentikan table lain, semua error digabung.
+func (p *Purger) PurgeOnce(ctx context.Context) (map[string]PurgeResult, error) {
+	results := make(map[string]PurgeResult, len(p.targets))
+	var errs []error
+	for _, target := range p.targets {
+		result, err := target.purge(ctx, p.Retention)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("purge %s: %w", target.name, err))
+			continue
+		}
+		results[target.name] = result
+	}
+	return results, errors.Join(errs...)
+}
+
+func (p *Purger) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		results, err := p.PurgeOnce(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			p.logf("purger: %v", err)
+		}
+		for _, name := range sortedKeys(results) {
+			if r := results[name]; r.Purged > 0 || r.Skipped > 0 {
+				p.logf("purger: %s purged=%d skipped=%d", name, r.Purged, r.Skipped)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package belajargorm
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashUserAndProduct(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	_, err := store.Users.Delete(ctx, "11")
+	assert.Nil(t, err)
+	_, err = store.Users.Get(ctx, "11")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	user, err := store.Users.Trash().Get(ctx, "11")
+	assert.Nil(t, err)
+	assert.True(t, user.DeletedAt.Valid)
+
+	page, err := store.Users.Trash().Page(ctx, PageRequest{WithTotal: true})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), *page.Total)
+	assert.Equal(t, "11", page.Items[0].ID)
+
+	assert.Nil(t, store.Users.Restore(ctx, "11"))
+	_, err = store.Users.Get(ctx, "11")
+	assert.Nil(t, err)
+	assert.ErrorIs(t, store.Users.Restore(ctx, "11"), ErrNotFound)
+
+	assert.Nil(t, store.Products.Delete(ctx, productID))
+	liked, err := store.Products.ListLikedByUser(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(liked))
+
+	assert.Nil(t, store.Products.Restore(ctx, productID))
+	liked, err = store.Products.ListLikedByUser(ctx, "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(liked))
+}
+
+func TestTrashPurgeOlderThan(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	old := Todo{UserID: "1", Task: "Lama"}
+	recent := Todo{UserID: "1", Task: "Baru"}
+	assert.Nil(t, store.Todos.Create(ctx, &old))
+	assert.Nil(t, store.Todos.Create(ctx, &recent))
+	assert.Nil(t, store.Todos.SetTags(ctx, old.ID, []string{"arsip"}))
+	assert.Nil(t, store.Todos.Delete(ctx, old.ID))
+	assert.Nil(t, store.Todos.Delete(ctx, recent.ID))
+
+	deletedAt := time.Now().Add(-40 * 24 * time.Hour)
+	err := db.Unscoped().Model(&Todo{}).Where("id = ?", old.ID).UpdateColumn("deleted_at", deletedAt.UnixNano()).Error
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.Addresses.Delete(ctx, int64(2)))
+	err = db.Unscoped().Model(&Address{}).Where("id = ?", 2).UpdateColumn("deleted_at", deletedAt).Error
+	assert.Nil(t, err)
+
+	result, err := store.Todos.Trash().PurgeOlderThan(ctx, DefaultRetention)
+	assert.Nil(t, err)
+	assert.Equal(t, PurgeResult{Purged: 1}, result)
+
+	var count int64
+	db.Unscoped().Model(&Todo{}).Where("id = ?", old.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.Model(&todoTag{}).Where("todo_id = ?", old.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	trashed, err := store.Todos.Trash().List(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(trashed))
+	assert.Equal(t, recent.ID, trashed[0].ID)
+
+	result, err = store.Addresses.Trash().PurgeOlderThan(ctx, DefaultRetention)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), result.Purged)
+	assert.ErrorIs(t, store.Addresses.Restore(ctx, int64(2)), ErrNotFound)
+
+	assert.Nil(t, store.Todos.Trash().Purge(ctx, recent.ID))
+	assert.ErrorIs(t, store.Todos.Trash().Purge(ctx, recent.ID), ErrNotFound)
+}
+
+func TestTrashNotSoftDeletable(t *testing.T) {
+	db := beginTest(t)
+
+	_, err := NewTrash[Tag](db).List(context.Background())
+	assert.ErrorIs(t, err, ErrNotSoftDeletable)
+}
+
+func TestPurger(t *testing.T) {
+	db := beginTest(t)
+	store := NewStore(db)
+
+	entry := GuestBook{Name: "Tamu", Email: "tamu@example.com", Message: "Halo"}
+	assert.Nil(t, db.Create(&entry).Error)
+	assert.Nil(t, db.Delete(&entry).Error)
+	err := db.Unscoped().Model(&GuestBook{}).Where("id = ?", entry.ID).UpdateColumn("deleted_at", time.Now().Add(-time.Hour)).Error
+	assert.Nil(t, err)
+
+	purger := NewPurger(store, time.Minute)
+	results, err := purger.PurgeOnce(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), results["guest_books"].Purged)
+	assert.Equal(t, 6, len(results))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	purger.Logger = log.New(io.Discard, "", 0)
+	assert.ErrorIs(t, purger.Run(ctx), context.Canceled)
+}
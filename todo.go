@@ -1,21 +1,226 @@
 package belajargorm
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/plugin/soft_delete"
 )
 
+const (
+	TodoOpen       = "open"
+	TodoInProgress = "in_progress"
+	TodoDone       = "done"
+)
+
+const (
+	PriorityLow = iota + 1
+	PriorityNormal
+	PriorityHigh
+	PriorityUrgent
+)
+
+var (
+	ErrInvalidTodoStatus = errors.New("belajargorm: invalid todo status")
+	ErrInvalidTransition = errors.New("belajargorm: invalid todo status transition")
+	ErrInvalidPriority   = errors.New("belajargorm: invalid todo priority")
+)
+
+// todoTransitions berisi status tujuan yang boleh dari tiap status. Todo yang
+// sudah done bisa dibuka lagi (reopen) ke open.
+var todoTransitions = map[string][]string{
+	TodoOpen:       {TodoInProgress, TodoDone},
+	TodoInProgress: {TodoOpen, TodoDone},
+	TodoDone:       {TodoOpen},
+}
+
 type Todo struct {
-	ID        int                   `gorm:"primary_key;column:id;autoIncrement"`
-	UserID    string                `gorm:"column:user_id"`
-	Task      string                `gorm:"column:task"`
-	CreatedAt int64                 `gorm:"column:created_at;autoCreateTime:nano"`
-	UpdatedAt int64                 `gorm:"column:updated_at;autoUpdateTime:nano"`
-	DeletedAt soft_delete.DeletedAt `gorm:"column:deleted_at;softDelete:nano"`
-}
-
-type TodoGorm struct {
-	UserID string `gorm:"column:user_id"`
-	Task   string `gorm:"column:task"`
-	gorm.Model
+	ID          int                   `gorm:"primary_key;column:id;autoIncrement"`
+	UserID      string                `gorm:"column:user_id" validate:"required,max=64"`
+	Task        string                `gorm:"column:task" validate:"required,max=500"`
+	Status      string                `gorm:"column:status" validate:"oneof=open in_progress done"`
+	Priority    int                   `gorm:"column:priority" validate:"min=1,max=4"`
+	DueAt       *time.Time            `gorm:"column:due_at"`
+	CompletedAt *time.Time            `gorm:"column:completed_at"`
+	CreatedAt   int64                 `gorm:"column:created_at;autoCreateTime:nano"`
+	UpdatedAt   int64                 `gorm:"column:updated_at;autoUpdateTime:nano"`
+	DeletedAt   soft_delete.DeletedAt `gorm:"column:deleted_at;softDelete:nano"`
+	Tags        []Tag                 `gorm:"many2many:todo_tags;joinForeignKey:todo_id;joinReferences:tag_id"`
+}
+
+type Tag struct {
+	ID        int64     `gorm:"primary_key;column:id;autoIncrement"`
+	Name      string    `gorm:"column:name" validate:"required,max=50"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (t *Tag) BeforeSave(db *gorm.DB) error {
+	return validateModel(db, t)
+}
+
+func (t *Tag) TableName() string {
+	return "tags"
+}
+
+// NormalizeTag membuat nama tag jadi huruf kecil tanpa spasi di ujung supaya
+// "Kerja" dan "kerja " dianggap tag yang sama.
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// todoTag adalah baris join table todo_tags.
+type todoTag struct {
+	TodoID int   `gorm:"primary_key;column:todo_id"`
+	TagID  int64 `gorm:"primary_key;column:tag_id"`
+}
+
+func (t *todoTag) TableName() string {
+	return "todo_tags"
+}
+
+func ensureTags(tx *gorm.DB, names []string) ([]Tag, error) {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, name := range names {
+		name = NormalizeTag(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]Tag, len(normalized))
+	for i, name := range normalized {
+		tags[i] = Tag{Name: name}
+	}
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// id tag yang sudah ada tidak dikembalikan oleh insert yang konflik, jadi baca ulang
+	tags = nil
+	err = tx.Where("name IN ?", normalized).Order("name").Find(&tags).Error
+	return tags, err
+}
+
+func validTodoStatus(status string) bool {
+	_, ok := todoTransitions[status]
+	return ok
+}
+
+// TransitionTo memindahkan status todo. CompletedAt diisi saat masuk done dan
+// dikosongkan lagi saat todo dibuka ulang.
+func (t *Todo) TransitionTo(status string, now time.Time) error {
+	if !validTodoStatus(status) {
+		return fmt.Errorf("%w: %q", ErrInvalidTodoStatus, status)
+	}
+
+	current := t.Status
+	if current == "" {
+		current = TodoOpen
+	}
+	if current == status {
+		return nil
+	}
+
+	allowed := false
+	for _, next := range todoTransitions[current] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, current, status)
+	}
+
+	t.Status = status
+	if status == TodoDone {
+		t.CompletedAt = &now
+	} else {
+		t.CompletedAt = nil
+	}
+	return nil
+}
+
+func (t *Todo) IsOverdue(now time.Time) bool {
+	return t.Status != TodoDone && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// BeforeSave mengisi default status dan priority, memastikan completed_at
+// sesuai dengan status, lalu memvalidasi field lain.
+func (t *Todo) BeforeSave(db *gorm.DB) error {
+	if t.Status == "" {
+		t.Status = TodoOpen
+	}
+	if !validTodoStatus(t.Status) {
+		return fmt.Errorf("%w: %q", ErrInvalidTodoStatus, t.Status)
+	}
+
+	if t.Priority == 0 {
+		t.Priority = PriorityNormal
+	}
+	if t.Priority < PriorityLow || t.Priority > PriorityUrgent {
+		return fmt.Errorf("%w: %d", ErrInvalidPriority, t.Priority)
+	}
+
+	switch {
+	case t.Status == TodoDone && t.CompletedAt == nil:
+		now := time.Now()
+		t.CompletedAt = &now
+	case t.Status != TodoDone:
+		t.CompletedAt = nil
+	}
+
+	// SQLite membandingkan waktu sebagai text, jadi simpan selalu dalam UTC
+	if t.DueAt != nil {
+		due := t.DueAt.UTC()
+		t.DueAt = &due
+	}
+	if t.CompletedAt != nil {
+		completed := t.CompletedAt.UTC()
+		t.CompletedAt = &completed
+	}
+	return validateModel(db, t)
+}
+
+// Overdue memfilter todo yang belum selesai dan due date-nya sudah lewat.
+func Overdue(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("todos.status <> ? AND todos.due_at < ?", TodoDone, now.UTC())
+	}
+}
+
+// WithTag memfilter todo yang punya tag tertentu.
+func WithTag(tag string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("todos.id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+			Table("todo_tags").
+			Select("todo_tags.todo_id").
+			Joins("JOIN tags ON tags.id = todo_tags.tag_id").
+			Where("tags.name = ?", NormalizeTag(tag)))
+	}
+}
+
+// DueWithin memfilter todo yang belum selesai dan jatuh tempo dalam rentang d dari now.
+func DueWithin(now time.Time, d time.Duration) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("todos.status <> ? AND todos.due_at >= ? AND todos.due_at <= ?", TodoDone, now.UTC(), now.Add(d).UTC())
+	}
+}
+
+func (t *Todo) FilterFields() FilterFields {
+	return FilterFields{
+		Filter: []string{"id", "user_id", "task", "status", "priority", "due_at", "completed_at", "created_at"},
+		Sort:   []string{"id", "priority", "due_at", "completed_at", "created_at"},
+	}
 }
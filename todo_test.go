@@ -0,0 +1,181 @@
+package belajargorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestTodoDefaults(t *testing.T) {
+	db := beginTest(t)
+
+	todo := Todo{UserID: "1", Task: "Belajar workflow"}
+	assert.Nil(t, db.Create(&todo).Error)
+	assert.Equal(t, TodoOpen, todo.Status)
+	assert.Equal(t, PriorityNormal, todo.Priority)
+	assert.Nil(t, todo.CompletedAt)
+
+	err := db.Create(&Todo{UserID: "1", Task: "Salah", Priority: 9}).Error
+	assert.ErrorIs(t, err, ErrInvalidPriority)
+
+	err = db.Create(&Todo{UserID: "1", Task: "Salah", Status: "archived"}).Error
+	assert.ErrorIs(t, err, ErrInvalidTodoStatus)
+
+	done := Todo{UserID: "1", Task: "Sudah selesai", Status: TodoDone}
+	assert.Nil(t, db.Create(&done).Error)
+	assert.NotNil(t, done.CompletedAt)
+}
+
+func TestTodoTransition(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	todo := Todo{Status: TodoOpen}
+
+	assert.Nil(t, todo.TransitionTo(TodoInProgress, now))
+	assert.Nil(t, todo.CompletedAt)
+
+	assert.Nil(t, todo.TransitionTo(TodoDone, now))
+	assert.Equal(t, now, *todo.CompletedAt)
+
+	err := todo.TransitionTo(TodoInProgress, now)
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	assert.Nil(t, todo.TransitionTo(TodoOpen, now))
+	assert.Nil(t, todo.CompletedAt)
+
+	assert.ErrorIs(t, todo.TransitionTo("archived", now), ErrInvalidTodoStatus)
+}
+
+func TestTodoSetStatus(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	todo := Todo{UserID: "1", Task: "Review PR"}
+	assert.Nil(t, store.Todos.Create(ctx, &todo))
+
+	updated, err := store.Todos.SetStatus(ctx, todo.ID, TodoDone)
+	assert.Nil(t, err)
+	assert.Equal(t, TodoDone, updated.Status)
+	assert.NotNil(t, updated.CompletedAt)
+
+	_, err = store.Todos.SetStatus(ctx, todo.ID, TodoInProgress)
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	reopened, err := store.Todos.SetStatus(ctx, todo.ID, TodoOpen)
+	assert.Nil(t, err)
+
+	loaded, err := store.Todos.Get(ctx, todo.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, TodoOpen, loaded.Status)
+	assert.Nil(t, loaded.CompletedAt)
+	assert.Equal(t, reopened.Status, loaded.Status)
+
+	_, err = store.Todos.SetStatus(ctx, 999, TodoDone)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestTodoDueDates(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		due := now.Add(d)
+		return &due
+	}
+
+	todos := []Todo{
+		{UserID: "1", Task: "Telat", DueAt: at(-48 * time.Hour)},
+		{UserID: "1", Task: "Telat tapi selesai", DueAt: at(-24 * time.Hour), Status: TodoDone},
+		{UserID: "1", Task: "Besok", DueAt: at(20 * time.Hour)},
+		{UserID: "1", Task: "Minggu depan", DueAt: at(7 * 24 * time.Hour)},
+		{UserID: "1", Task: "Tanpa due date"},
+		{UserID: "2", Task: "Telat user lain", DueAt: at(-time.Hour)},
+	}
+	for i := range todos {
+		assert.Nil(t, store.Todos.Create(ctx, &todos[i]))
+	}
+	assert.True(t, todos[0].IsOverdue(now))
+	assert.False(t, todos[1].IsOverdue(now))
+	assert.False(t, todos[4].IsOverdue(now))
+
+	overdue, err := store.Todos.ListOverdue(ctx, "1", now)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(overdue))
+	assert.Equal(t, "Telat", overdue[0].Task)
+
+	soon, err := store.Todos.ListDueSoon(ctx, "1", now, 24*time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(soon))
+	assert.Equal(t, "Besok", soon[0].Task)
+}
+
+func TestTodoTags(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	first := Todo{UserID: "1", Task: "Tulis laporan"}
+	second := Todo{UserID: "1", Task: "Belanja"}
+	assert.Nil(t, store.Todos.Create(ctx, &first))
+	assert.Nil(t, store.Todos.Create(ctx, &second))
+
+	assert.Nil(t, store.Todos.SetTags(ctx, first.ID, []string{"Kerja", " kerja ", "penting", ""}))
+	assert.Nil(t, store.Todos.SetTags(ctx, second.ID, []string{"rumah", "PENTING"}))
+
+	var tagCount int64
+	db.Model(&Tag{}).Count(&tagCount)
+	assert.Equal(t, int64(3), tagCount)
+
+	loaded, err := store.Todos.Get(ctx, first.ID, Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(loaded.Tags))
+	assert.Equal(t, "kerja", loaded.Tags[0].Name)
+	assert.Equal(t, "penting", loaded.Tags[1].Name)
+
+	important, err := store.Todos.ListByTag(ctx, "1", "Penting")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(important))
+
+	assert.Nil(t, store.Todos.SetTags(ctx, first.ID, []string{"kerja"}))
+	important, err = store.Todos.ListByTag(ctx, "1", "penting")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(important))
+	assert.Equal(t, second.ID, important[0].ID)
+
+	assert.Nil(t, store.Todos.SetTags(ctx, first.ID, nil))
+	work, err := store.Todos.ListByTag(ctx, "1", "kerja")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(work))
+
+	assert.ErrorIs(t, store.Todos.SetTags(ctx, 999, []string{"kerja"}), ErrNotFound)
+}
+
+func TestTodoRestore(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	todo := Todo{UserID: "1", Task: "Hapus lalu kembalikan"}
+	assert.Nil(t, store.Todos.Create(ctx, &todo))
+	assert.Nil(t, store.Todos.Delete(ctx, todo.ID))
+
+	_, err := store.Todos.Get(ctx, todo.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Nil(t, store.Todos.Restore(ctx, todo.ID))
+
+	restored, err := store.Todos.Get(ctx, todo.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "Hapus lalu kembalikan", restored.Task)
+	assert.Zero(t, restored.DeletedAt)
+
+	// todo yang tidak dihapus tidak bisa di-restore
+	assert.ErrorIs(t, store.Todos.Restore(ctx, todo.ID), ErrNotFound)
+}
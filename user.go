@@ -1,45 +1,123 @@
 package belajargorm
 
 import (
-	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type User struct {
-	ID           string    `gorm:"primary_key;column:id"`
-	Password     string    `gorm:"column:password"`
-	Name         Name      `gorm:"embedded"`
-	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime;<-:create"`
-	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
-	Information  string    `gorm:"-"`
-	Wallet       Wallet    `gorm:"foreignKey:user_id;references:id"`
-	Addresses    []Address `gorm:"foreignKey:user_id;references:id"`
-	LikeProducts []Product `gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:user_id;joinReferences:product_id"`
+	ID           string         `gorm:"primary_key;column:id" validate:"max=64"`
+	Password     string         `gorm:"column:password"`
+	Name         Name           `gorm:"embedded"`
+	SearchName   string         `gorm:"column:search_name"`
+	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime;<-:create"`
+	UpdatedAt    time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at"`
+	Version      Version        `gorm:"column:version"`
+	Information  string         `gorm:"-"`
+	Wallet       Wallet         `gorm:"foreignKey:user_id;references:id"`
+	Addresses    []Address      `gorm:"foreignKey:user_id;references:id"`
+	LikeProducts []Product      `gorm:"many2many:user_like_product;foreignKey:id;joinForeignKey:user_id;joinReferences:product_id"`
+
+	// storedPassword adalah hash yang terakhir dibaca dari atau ditulis ke
+	// database. Password yang sama dengan nilai ini tidak di-hash ulang.
+	storedPassword string
 }
 
 type Name struct {
-	FirstName  string `gorm:"column:first_name"`
-	MiddleName string `gorm:"column:middle_name"`
-	LastName   string `gorm:"column:last_name"`
+	FirstName  string `gorm:"column:first_name" validate:"required,max=100"`
+	MiddleName string `gorm:"column:middle_name" validate:"max=100"`
+	LastName   string `gorm:"column:last_name" validate:"max=100"`
+}
+
+// FullName menggabungkan nama depan, tengah dan belakang, bagian yang kosong dilewati.
+func (n Name) FullName() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{n.FirstName, n.MiddleName, n.LastName} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
 }
 
 func (u *User) TableName() string {
 	return "users"
 }
 
+func (u *User) FilterFields() FilterFields {
+	return FilterFields{
+		Filter: []string{"id", "first_name", "middle_name", "last_name", "created_at", "updated_at", "Wallet.balance", "Wallet.currency"},
+		Sort:   []string{"id", "first_name", "middle_name", "last_name", "created_at", "updated_at", "Wallet.balance"},
+	}
+}
+
 type UserLog struct {
 	ID        int    `gorm:"primary_key;column:id;autoIncrement"`
 	UserID    string `gorm:"column:user_id"`
 	Action    string `gorm:"column:action"`
+	Actor     string `gorm:"column:actor"`
+	Entity    string `gorm:"column:entity"`
+	RecordID  string `gorm:"column:record_id"`
+	Changes   string `gorm:"column:changes"`
 	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:mili"`
 	UpdatedAt int64  `gorm:"column:updated_at;autoUpdateTime:mili"`
 }
 
 func (u *User) BeforeCreate(db *gorm.DB) error {
 	if u.ID == "" {
-		u.ID = fmt.Sprintf("user-%d", time.Now().UTC().UnixMilli())
+		u.ID = UserIDGenerator.NewID()
+	}
+	u.SearchName = normalizeName(u.Name.FullName())
+	return nil
+}
+
+func (u *User) AfterFind(db *gorm.DB) error {
+	u.storedPassword = u.Password
+	return nil
+}
+
+// BeforeSave memvalidasi lalu meng-hash password sebelum disimpan, baik lewat
+// Create, Save, Updates(struct) maupun Update/Updates dengan map. Password dari
+// caller selalu dianggap plain text; yang tidak di-hash ulang hanya hash yang
+// sama dengan nilai yang dibaca dari database.
+func (u *User) BeforeSave(db *gorm.DB) error {
+	if err := validateModel(db, u); err != nil {
+		return err
+	}
+
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		return hashPasswordMap(dest)
+	case *User:
+		if dest != u {
+			return dest.hashPassword()
+		}
+	case User:
+		plain := dest.Password
+		if err := dest.hashPassword(); err != nil {
+			return err
+		}
+		if dest.Password != plain {
+			db.Statement.SetColumn("Password", dest.Password)
+		}
+		return nil
+	}
+
+	return u.hashPassword()
+}
+
+func (u *User) hashPassword() error {
+	if u.Password == "" || u.Password == u.storedPassword {
+		return nil
+	}
+	hashed, err := HashPassword(u.Password)
+	if err != nil {
+		return err
 	}
+	u.Password = hashed
+	u.storedPassword = hashed
 	return nil
 }
@@ -0,0 +1,192 @@
+package belajargorm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultSearchLimit dipakai SearchUsersByName kalau limit <= 0.
+const DefaultSearchLimit = 20
+
+var nameColumns = []string{"first_name", "middle_name", "last_name"}
+
+// normalizeName membuat text pencarian: lowercase, tanpa aksen dan spasi
+// berlebih, misalnya "  José  Núñez" menjadi "jose nunez".
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// searchNameKey menyimpan id user yang search_name-nya dihitung ulang di AfterUpdate.
+type searchNameKey struct{}
+
+// updatesName mengecek apakah statement update menulis salah satu kolom nama.
+func updatesName(stmt *gorm.Statement) bool {
+	if dest, ok := stmt.Dest.(map[string]any); ok {
+		for key := range dest {
+			if field := stmt.Schema.LookUpField(key); field != nil && contains(nameColumns, field.DBName) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if contains(stmt.Selects, "*") {
+		return true
+	}
+	if len(stmt.Selects) > 0 {
+		for _, column := range stmt.Selects {
+			if field := stmt.Schema.LookUpField(column); field != nil && contains(nameColumns, field.DBName) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Updates(struct) hanya menulis field yang tidak kosong
+	dest := reflect.Indirect(reflect.ValueOf(stmt.Dest))
+	if dest.Kind() != reflect.Struct || dest.Type() != stmt.Schema.ModelType {
+		return false
+	}
+	for _, column := range nameColumns {
+		if _, zero := stmt.Schema.FieldsByDBName[column].ValueOf(stmt.Context, dest); !zero {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+*	BeforeUpdate menjaga search_name tetap sesuai nama. Save menulis semua
+*	kolom sehingga search_name bisa langsung dihitung dari struct. Update
+*	lain (map, Select, Updates dengan sebagian field) tidak membawa nama
+*	lengkap, jadi id user yang terkena dicatat lalu search_name dihitung ulang
+*	dari database di AfterUpdate.
+ */
+func (u *User) BeforeUpdate(db *gorm.DB) error {
+	stmt := db.Statement
+	if !updatesName(stmt) {
+		return nil
+	}
+	if dest, ok := stmt.Dest.(*User); ok && dest == u && contains(stmt.Selects, "*") {
+		u.SearchName = normalizeName(u.Name.FullName())
+		return nil
+	}
+
+	ids := []string{u.ID}
+	if u.ID == "" {
+		query := db.Session(&gorm.Session{NewDB: true}).Model(&User{})
+		if where, ok := stmt.Clauses["WHERE"]; ok {
+			query = query.Clauses(where.Expression)
+		} else if !stmt.AllowGlobalUpdate {
+			// gorm akan menolak update tanpa WHERE
+			return nil
+		}
+		ids = nil
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+	}
+	stmt.Settings.Store(searchNameKey{}, ids)
+	return nil
+}
+
+func (u *User) AfterUpdate(db *gorm.DB) error {
+	ids, ok := db.Statement.Settings.LoadAndDelete(searchNameKey{})
+	if !ok || len(ids.([]string)) == 0 {
+		return nil
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true})
+	var users []User
+	err := tx.Unscoped().Select("id", "first_name", "middle_name", "last_name", "search_name").
+		Where("id IN ?", ids).Find(&users).Error
+	if err != nil {
+		return err
+	}
+	return updateSearchNames(tx, users)
+}
+
+// updateSearchNames menulis search_name lewat Table() supaya tidak memanggil
+// hook lagi dan tidak menaikkan version.
+func updateSearchNames(db *gorm.DB, users []User) error {
+	for _, user := range users {
+		name := normalizeName(user.Name.FullName())
+		if name == user.SearchName {
+			continue
+		}
+		if err := db.Table("users").Where("id = ?", user.ID).UpdateColumn("search_name", name).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildSearchNames menghitung ulang search_name semua user, termasuk yang
+// sudah dihapus. Dipakai setelah migration atau setelah nama diubah tanpa gorm.
+func (r *UserRepository) RebuildSearchNames(ctx context.Context) error {
+	db := r.db.WithContext(ctx)
+	var users []User
+	return db.Unscoped().Select("id", "first_name", "middle_name", "last_name", "search_name").
+		FindInBatches(&users, 500, func(tx *gorm.DB, batch int) error {
+			return updateSearchNames(db, users)
+		}).Error
+}
+
+/**
+*	SearchUsersByName mencari user berdasarkan nama depan, tengah dan
+*	belakang tanpa membedakan huruf besar dan aksen. Setiap kata di query
+*	harus menjadi awalan salah satu kata nama, misalnya "bud san" cocok
+*	dengan "Budi Santoso". Di Postgres nama yang mirip (typo) ikut cocok
+*	lewat pg_trgm dan hasilnya diurutkan menurut similarity. Di SQLite
+*	urutannya: nama persis sama, nama diawali query, lalu nama terpendek.
+ */
+func (r *UserRepository) SearchUsersByName(ctx context.Context, query string, limit int) ([]User, error) {
+	query = normalizeName(query)
+	if query == "" {
+		return []User{}, nil
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	words := strings.Fields(query)
+	conditions := make([]string, len(words))
+	args := make([]any, 0, 2*len(words)+1)
+	for i, word := range words {
+		conditions[i] = `(search_name LIKE ? ESCAPE '\' OR search_name LIKE ? ESCAPE '\')`
+		args = append(args, likePrefix(word), "% "+likePrefix(word))
+	}
+	match := strings.Join(conditions, " AND ")
+
+	var order clause.Expr
+	if r.db.Dialector.Name() == DriverPostgres {
+		match = "(" + match + ") OR ? <% search_name"
+		args = append(args, query)
+		order = clause.Expr{
+			SQL:  "word_similarity(?, search_name) DESC, similarity(?, search_name) DESC, id",
+			Vars: []any{query, query},
+		}
+	} else {
+		order = clause.Expr{
+			SQL:  `CASE WHEN search_name = ? THEN 0 WHEN search_name LIKE ? ESCAPE '\' THEN 1 ELSE 2 END, length(search_name), id`,
+			Vars: []any{query, likePrefix(query)},
+		}
+	}
+
+	return r.List(ctx, Where(match, args...), func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(clause.OrderBy{Expression: order})
+	}, Limit(limit))
+}
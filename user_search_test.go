@@ -0,0 +1,98 @@
+package belajargorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func searchName(t *testing.T, store *Store, id string) string {
+	t.Helper()
+	var name string
+	assert.Nil(t, store.db.Model(&User{}).Unscoped().Where("id = ?", id).Pluck("search_name", &name).Error)
+	return name
+}
+
+func TestUserFullName(t *testing.T) {
+	assert.Equal(t, "Budi Santoso", Name{FirstName: "Budi", LastName: "Santoso"}.FullName())
+	assert.Equal(t, "Budi Eko Santoso", Name{FirstName: "Budi", MiddleName: "Eko", LastName: "Santoso"}.FullName())
+	assert.Equal(t, "Budi", Name{FirstName: " Budi ", MiddleName: " "}.FullName())
+	assert.Equal(t, "jose nunez", normalizeName("  José   NÚÑEZ "))
+}
+
+func TestUserSearchName(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	user := User{ID: "cari", Password: "rahasia", Name: Name{FirstName: "Zoë", LastName: "Ardiansyah"}}
+	assert.Nil(t, store.Users.Create(ctx, &user))
+	assert.Equal(t, "zoe ardiansyah", searchName(t, store, "cari"))
+
+	// Save menghitung langsung dari struct
+	user.Name.MiddleName = "Élise"
+	assert.Nil(t, store.Users.Update(ctx, &user))
+	assert.Equal(t, "zoe elise ardiansyah", searchName(t, store, "cari"))
+
+	// update sebagian dihitung ulang dari database
+	assert.Nil(t, store.Users.UpdateFields(ctx, "cari", map[string]any{"last_name": "Putri"}))
+	assert.Equal(t, "zoe elise putri", searchName(t, store, "cari"))
+	assert.Nil(t, db.Model(&User{}).Where("first_name = ?", "Zoë").Update("middle_name", "").Error)
+	assert.Equal(t, "zoe putri", searchName(t, store, "cari"))
+	assert.Nil(t, db.Where("id = ?", "cari").Updates(&User{Name: Name{FirstName: "Zara"}}).Error)
+	assert.Equal(t, "zara putri", searchName(t, store, "cari"))
+
+	// update tanpa kolom nama tidak menyentuh search_name
+	assert.Nil(t, db.Exec("UPDATE users SET search_name = '' WHERE id = ?", "cari").Error)
+	assert.Nil(t, store.Users.UpdateFields(ctx, "cari", map[string]any{"password": "rahasia lagi"}))
+	assert.Equal(t, "", searchName(t, store, "cari"))
+
+	assert.Nil(t, store.Users.RebuildSearchNames(ctx))
+	assert.Equal(t, "zara putri", searchName(t, store, "cari"))
+}
+
+func TestSearchUsersByName(t *testing.T) {
+	db := beginTest(t)
+	ctx := context.Background()
+	store := NewStore(db)
+
+	for _, user := range []User{
+		{ID: "s1", Name: Name{FirstName: "Budi", LastName: "Santoso"}},
+		{ID: "s2", Name: Name{FirstName: "Budiman", MiddleName: "Sánchez", LastName: "Halim"}},
+		{ID: "s3", Name: Name{FirstName: "Ani", LastName: "Budi"}},
+		{ID: "s4", Name: Name{FirstName: "Budi"}},
+	} {
+		assert.Nil(t, store.Users.Create(ctx, &user))
+	}
+
+	ids := func(users []User) []string {
+		result := make([]string, len(users))
+		for i, user := range users {
+			result[i] = user.ID
+		}
+		return result
+	}
+
+	users, err := store.Users.SearchUsersByName(ctx, "BUDI", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"s4", "s1", "s2", "s3"}, ids(users))
+
+	// setiap kata harus menjadi awalan salah satu bagian nama
+	users, err = store.Users.SearchUsersByName(ctx, "bud sanc", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"s2"}, ids(users))
+
+	users, err = store.Users.SearchUsersByName(ctx, "budi", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(users))
+
+	// wildcard LIKE dicari apa adanya
+	users, err = store.Users.SearchUsersByName(ctx, "%", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(users))
+
+	users, err = store.Users.SearchUsersByName(ctx, "  ", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(users))
+}
@@ -0,0 +1,337 @@
+package belajargorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrValidation = errors.New("belajargorm: validation failed")
+
+// ValidationError berisi pesan error per field, errors.Is(err, ErrValidation)
+// bernilai true.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Add(field, message string) {
+	if e.Fields == nil {
+		e.Fields = map[string]string{}
+	}
+	if _, ok := e.Fields[field]; !ok {
+		e.Fields[field] = message
+	}
+}
+
+// Err mengembalikan nil kalau tidak ada field yang salah.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	fields := sortedKeys(e.Fields)
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field + " " + e.Fields[field]
+	}
+	return ErrValidation.Error() + ": " + strings.Join(parts, ", ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+/**
+*	fieldRule adalah satu rule dari tag `validate`, misalnya
+*	`validate:"required,max=100"`. Rule yang tersedia: required, min=N,
+*	max=N (panjang text atau nilai angka, Money memakai Amount), email,
+*	oneof=a b c dan regex=pola. regex harus rule terakhir karena pola boleh
+*	berisi koma. Selain required, rule dilewati kalau text kosong atau nil.
+ */
+type fieldRule struct {
+	name    string
+	arg     string
+	limit   float64
+	pattern *regexp.Regexp
+}
+
+func parseRules(tag string) ([]fieldRule, error) {
+	var rules []fieldRule
+	for tag != "" {
+		part := tag
+		if strings.HasPrefix(part, "regex=") {
+			tag = ""
+		} else if i := strings.IndexByte(part, ','); i >= 0 {
+			part, tag = part[:i], part[i+1:]
+		} else {
+			tag = ""
+		}
+
+		rule := fieldRule{name: part}
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			rule.name, rule.arg = part[:i], part[i+1:]
+		}
+
+		var err error
+		switch rule.name {
+		case "required", "email":
+		case "min", "max":
+			rule.limit, err = strconv.ParseFloat(rule.arg, 64)
+		case "oneof":
+			if rule.arg == "" {
+				err = errors.New("oneof without values")
+			}
+		case "regex":
+			rule.pattern, err = regexp.Compile(rule.arg)
+		default:
+			err = errors.New("unknown rule")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("belajargorm: invalid validate rule %q: %w", part, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+var (
+	validationSchemas sync.Map
+	// validationRules berisi rule per DBName untuk setiap *schema.Schema
+	validationRules sync.Map
+)
+
+func rulesForSchema(s *schema.Schema) (map[string][]fieldRule, error) {
+	if rules, ok := validationRules.Load(s); ok {
+		return rules.(map[string][]fieldRule), nil
+	}
+
+	rules := map[string][]fieldRule{}
+	for _, field := range s.Fields {
+		tag := field.Tag.Get("validate")
+		if tag == "" || field.DBName == "" {
+			continue
+		}
+		parsed, err := parseRules(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", s.Name, field.Name, err)
+		}
+		rules[field.DBName] = parsed
+	}
+	validationRules.Store(s, rules)
+	return rules, nil
+}
+
+/**
+*	validateModel dipanggil dari BeforeSave setiap model dan memeriksa rule
+*	tag `validate` sesuai field yang benar-benar akan ditulis:
+*	- Create dan Save: semua field.
+*	- Updates/Update dengan map: hanya key di map.
+*	- Updates dengan Select: hanya column yang dipilih.
+*	- Updates dengan struct: hanya field yang tidak kosong, sama seperti gorm.
+*	Error dikembalikan sebagai *ValidationError dengan nama column sebagai field.
+ */
+func validateModel(db *gorm.DB, model any) error {
+	stmt := db.Statement
+	value := reflect.Indirect(reflect.ValueOf(model))
+
+	s := stmt.Schema
+	if s == nil || s.ModelType != value.Type() {
+		var err error
+		if s, err = schema.Parse(model, &validationSchemas, db.NamingStrategy); err != nil {
+			return err
+		}
+	}
+	rules, err := rulesForSchema(s)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	// association yang di-upsert gorm dengan ON CONFLICT DO NOTHING biasanya
+	// hanya berisi primary key record yang sudah ada, misalnya Append(&User{ID: "4"})
+	if c, ok := stmt.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && onConflict.DoNothing && hasPrimaryKey(stmt, s, value) {
+			return nil
+		}
+	}
+
+	ve := &ValidationError{}
+	if dest, ok := stmt.Dest.(map[string]any); ok {
+		for key, v := range dest {
+			if _, ok := v.(clause.Expression); ok {
+				continue
+			}
+			if field := s.LookUpField(key); field != nil {
+				validateValue(ve, field.DBName, rules[field.DBName], v)
+			}
+		}
+		return ve.Err()
+	}
+
+	// Updates(struct) dengan struct lain sebagai Dest, misalnya db.Model(&user).Updates(User{...})
+	if dest := reflect.Indirect(reflect.ValueOf(stmt.Dest)); dest.IsValid() && dest.Type() == value.Type() {
+		value = dest
+	}
+
+	// BuildClauses sudah diisi callback gorm, "UPDATE" berarti Save atau Updates
+	updating := len(stmt.BuildClauses) > 0 && stmt.BuildClauses[0] == "UPDATE"
+	var selected map[string]bool
+	if updating && !contains(stmt.Selects, "*") && len(stmt.Selects) > 0 {
+		selected = map[string]bool{}
+		for _, column := range stmt.Selects {
+			if field := s.LookUpField(column); field != nil {
+				selected[field.DBName] = true
+			}
+		}
+	}
+
+	for _, field := range s.Fields {
+		fieldRules, ok := rules[field.DBName]
+		if !ok {
+			continue
+		}
+		v, zero := field.ValueOf(stmt.Context, value)
+		switch {
+		case selected != nil && !selected[field.DBName]:
+			continue
+		case updating && len(stmt.Selects) == 0 && zero:
+			continue
+		}
+		validateValue(ve, field.DBName, fieldRules, v)
+	}
+	return ve.Err()
+}
+
+// validateStruct memeriksa field model dengan rule tag `validate` tanpa
+// database, misalnya di handler sebelum record disimpan. Kalau columns diisi
+// hanya column itu yang diperiksa. Error ditambahkan ke ve dengan nama column
+// sebagai field supaya bisa digabung dengan cek lain.
+func validateStruct(ve *ValidationError, model any, columns ...string) error {
+	s, err := schema.Parse(model, &validationSchemas, schema.NamingStrategy{})
+	if err != nil {
+		return err
+	}
+	rules, err := rulesForSchema(s)
+	if err != nil {
+		return err
+	}
+	value := reflect.Indirect(reflect.ValueOf(model))
+	for _, field := range s.Fields {
+		if len(columns) > 0 && !contains(columns, field.DBName) {
+			continue
+		}
+		if fieldRules, ok := rules[field.DBName]; ok {
+			v, _ := field.ValueOf(context.Background(), value)
+			validateValue(ve, field.DBName, fieldRules, v)
+		}
+	}
+	return nil
+}
+
+func hasPrimaryKey(stmt *gorm.Statement, s *schema.Schema, value reflect.Value) bool {
+	for _, field := range s.PrimaryFields {
+		if _, zero := field.ValueOf(stmt.Context, value); zero {
+			return false
+		}
+	}
+	return len(s.PrimaryFields) > 0
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func validateValue(ve *ValidationError, field string, rules []fieldRule, value any) {
+	switch m := value.(type) {
+	case Money:
+		value = m.Amount
+	case *Money:
+		if m != nil {
+			value = m.Amount
+		}
+	}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v = reflect.Value{}
+			break
+		}
+		v = v.Elem()
+	}
+
+	text := v.IsValid() && v.Kind() == reflect.String
+	empty := !v.IsValid() || v.IsZero() || (text && strings.TrimSpace(v.String()) == "")
+	for _, rule := range rules {
+		if rule.name == "required" {
+			if empty {
+				ve.Add(field, "is required")
+				return
+			}
+			continue
+		}
+		if !v.IsValid() || (text && v.String() == "") {
+			continue
+		}
+
+		switch rule.name {
+		case "min", "max":
+			n, unit, ok := measure(v)
+			if !ok {
+				continue
+			}
+			if rule.name == "min" && n < rule.limit {
+				ve.Add(field, "must be at least "+rule.arg+unit)
+			}
+			if rule.name == "max" && n > rule.limit {
+				ve.Add(field, "must be at most "+rule.arg+unit)
+			}
+		case "email":
+			if addr, err := mail.ParseAddress(v.String()); err != nil || addr.Address != v.String() {
+				ve.Add(field, "must be a valid email address")
+			}
+		case "oneof":
+			options := strings.Fields(rule.arg)
+			if !contains(options, fmt.Sprint(v.Interface())) {
+				ve.Add(field, "must be one of "+strings.Join(options, ", "))
+			}
+		case "regex":
+			if !rule.pattern.MatchString(v.String()) {
+				ve.Add(field, "has an invalid format")
+			}
+		}
+	}
+}
+
+// measure mengembalikan panjang text atau nilai angka untuk rule min dan max.
+func measure(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	}
+	return 0, "", false
+}
@@ -0,0 +1,97 @@
+package belajargorm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validationFields(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	return ve.Fields
+}
+
+func TestModelValidationOnCreate(t *testing.T) {
+	db := beginTest(t)
+
+	err := db.Create(&User{ID: "kosong"}).Error
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, "is required", validationFields(t, err)["first_name"])
+
+	err = db.Create(&GuestBook{Name: "Tamu", Email: "bukan email", Message: "Halo"}).Error
+	assert.Equal(t, "must be a valid email address", validationFields(t, err)["email"])
+
+	err = db.Create(&GuestBook{Name: strings.Repeat("a", 101), Email: "tamu@example.com", Message: " "}).Error
+	fields := validationFields(t, err)
+	assert.Equal(t, "must be at most 100 characters", fields["name"])
+	assert.Equal(t, "is required", fields["message"])
+
+	err = db.Create(&Wallet{UserID: "4", Balance: IDR(-1)}).Error
+	assert.Equal(t, "must be at least 0", validationFields(t, err)["balance"])
+
+	err = db.Create(&Product{Name: "Gratis", Price: IDR(0)}).Error
+	assert.Equal(t, "must be at least 1", validationFields(t, err)["price"])
+
+	err = db.Create(&Order{UserID: "1", Status: "shipped", Total: IDR(0)}).Error
+	assert.Equal(t, "must be one of pending, paid, cancelled, refunded", validationFields(t, err)["status"])
+
+	// tidak ada yang tersimpan
+	var count int64
+	assert.Nil(t, db.Model(&User{}).Where("id = ?", "kosong").Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestModelValidationOnUpdate(t *testing.T) {
+	db := beginTest(t)
+
+	// map hanya memvalidasi key yang dikirim, seperti TestSelectedColumn
+	err := db.Model(&User{}).Where("id = ?", "3").Updates(map[string]any{"first_name": "Merah"}).Error
+	assert.Nil(t, err)
+	err = db.Model(&User{}).Where("id = ?", "3").Update("last_name", strings.Repeat("a", 101)).Error
+	assert.Equal(t, map[string]string{"last_name": "must be at most 100 characters"}, validationFields(t, err))
+	err = db.Model(&User{}).Where("id = ?", "3").Updates(map[string]any{"first_name": ""}).Error
+	assert.Equal(t, "is required", validationFields(t, err)["first_name"])
+
+	err = db.Model(&Product{}).Where("id = ?", productID).Update("price", IDR(0)).Error
+	assert.Equal(t, "must be at least 1", validationFields(t, err)["price"])
+	err = db.Model(&Todo{}).Where("id = ?", 1).Update("status", "archived").Error
+	assert.ErrorIs(t, err, ErrValidation)
+
+	// Updates dengan struct hanya memvalidasi field yang tidak kosong
+	err = db.Where("id = ?", "5").Updates(&User{Name: Name{LastName: "Bellen"}}).Error
+	assert.Nil(t, err)
+	err = db.Where("id = ?", "5").Updates(&User{Name: Name{LastName: strings.Repeat("a", 101)}}).Error
+	assert.ErrorIs(t, err, ErrValidation)
+
+	// Select dan Save memvalidasi column yang ditulis walaupun kosong
+	var user User
+	assert.Nil(t, db.Take(&user, "id = ?", "5").Error)
+	user.Name.FirstName = ""
+	err = db.Model(&user).Select("first_name").Updates(&user).Error
+	assert.Equal(t, "is required", validationFields(t, err)["first_name"])
+	assert.Nil(t, db.Model(&user).Select("last_name").Updates(&user).Error)
+	err = db.Save(&user).Error
+	assert.Equal(t, "is required", validationFields(t, err)["first_name"])
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := parseRules("required,max=10,regex=^[a-z]{1,3}$")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(rules))
+	assert.Equal(t, "^[a-z]{1,3}$", rules[2].pattern.String())
+
+	ve := &ValidationError{}
+	validateValue(ve, "code", rules, "abcd")
+	assert.Equal(t, "has an invalid format", ve.Fields["code"])
+
+	_, err = parseRules("required,unique")
+	assert.NotNil(t, err)
+	_, err = parseRules("max=ten")
+	assert.NotNil(t, err)
+}
@@ -2,9 +2,61 @@ package belajargorm
 
 import "gorm.io/gorm"
 
+// Balance adalah proyeksi dari wallet_transactions. Hanya boleh diisi saat create
+// (menjadi saldo awal), perubahan berikutnya harus lewat Ledger. Currency wallet
+// juga tidak bisa diganti setelah dibuat.
 type Wallet struct {
 	gorm.Model
-	UserID  string `gorm:"user_id"`
-	Balance int64  `gorm:"balance"`
-	User    *User  `gorm:"foreignKey:user_id;references:id"`
+	UserID   string  `gorm:"user_id" validate:"required,max=64"`
+	Balance  Money   `gorm:"balance;<-:create" validate:"min=0"`
+	Currency string  `gorm:"column:currency;<-:create"`
+	Version  Version `gorm:"column:version"`
+	User     *User   `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (w *Wallet) BeforeSave(db *gorm.DB) error {
+	if err := syncCurrency(&w.Balance, &w.Currency); err != nil {
+		return err
+	}
+	return validateModel(db, w)
+}
+
+func (w *Wallet) AfterFind(db *gorm.DB) error {
+	w.Balance.Currency = w.Currency
+	return nil
+}
+
+// BalanceAtLeast memfilter wallet dengan saldo minimal m di currency yang sama.
+func BalanceAtLeast(m Money) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("currency = ? AND balance >= ?", m.Currency, m.Amount)
+	}
+}
+
+// AfterCreate mencatat saldo awal ke ledger supaya Balance selalu sama dengan total ledger.
+func (w *Wallet) AfterCreate(db *gorm.DB) error {
+	if w.Balance.IsZero() {
+		return nil
+	}
+
+	entry := WalletTransaction{
+		WalletID:     w.ID,
+		EntryType:    EntryCredit,
+		Amount:       w.Balance.Amount,
+		BalanceAfter: w.Balance.Amount,
+		Description:  "opening balance",
+	}
+	if w.Balance.Amount < 0 {
+		entry.EntryType = EntryDebit
+		entry.Amount = -w.Balance.Amount
+	}
+
+	return db.Create(&entry).Error
+}
+
+func (w *Wallet) FilterFields() FilterFields {
+	return FilterFields{
+		Filter: []string{"id", "user_id", "balance", "currency", "created_at", "User.first_name"},
+		Sort:   []string{"id", "balance", "created_at"},
+	}
 }